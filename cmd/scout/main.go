@@ -1,15 +1,31 @@
 // main.go
 package main
 
-import "github.com/DeleMike/scout/internal/shell"
+import (
+	"os"
+
+	"github.com/DeleMike/scout/internal/shell"
+)
 
 // main is the application entry point.
 // It initializes and starts an interactive shell session
 // that accepts commands for directory analysis.
+//
+// When arguments are given (e.g. "scout ." or "scout ask <question>"),
+// the command runs once in headless mode and the program exits, with
+// status 1 if the command failed.
 func main() {
 	// Create a new shell instance with default configuration.
 	s := shell.New()
 
+	// Headless mode: run a single command and exit
+	if len(os.Args) > 1 {
+		if err := s.Run(os.Args[1:]); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Start the REPL (Read-Eval-Print Loop)
 	s.Start()
 }
@@ -0,0 +1,47 @@
+// Package cache locates the on-disk directories where Scout keeps per-root data
+// such as embedding indexes, so repeated runs on the same folder are fast.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns (and creates) the cache directory for a scanned root.
+//
+// Caches live under $SCOUT_CACHE_DIR when set, otherwise under the user's
+// cache directory (e.g. ~/.cache/scout), in a folder named after a hash of
+// the root's absolute path. Nothing is ever written inside the scanned folder.
+//
+// Parameters:
+//   - root: Directory that was scanned
+//
+// Returns:
+//   - string: Absolute path of the cache directory for root
+//   - error: Any error resolving or creating the directory
+func Dir(root string) (string, error) {
+	base := os.Getenv("SCOUT_CACHE_DIR")
+	if base == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(userCache, "scout")
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(absRoot))
+	dir := filepath.Join(base, hex.EncodeToString(sum[:8]))
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
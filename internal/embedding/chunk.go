@@ -0,0 +1,33 @@
+package embedding
+
+import "strings"
+
+// Chunk splits text into windows of size words, each overlapping the
+// previous one by overlap words.
+//
+// Example: Chunk("a b c d e", 3, 1) → ["a b c", "c d e"]
+//
+// Returns: Chunks in order (empty for blank text)
+func Chunk(text string, size, overlap int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var chunks []string
+	step := size - overlap
+	for start := 0; start < len(words); start += step {
+		end := min(start+size, len(words))
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+	}
+	return chunks
+}
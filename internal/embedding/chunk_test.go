@@ -0,0 +1,73 @@
+package embedding
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		size, overlap int
+		want          []string
+	}{
+		{"doc example", "a b c d e", 3, 1, []string{"a b c", "c d e"}},
+		{"no overlap", "a b c d e", 2, 0, []string{"a b", "c d", "e"}},
+		{"fits in one", "a b", 5, 2, []string{"a b"}},
+		{"exact fit", "a b c", 3, 1, []string{"a b c"}},
+		{"whitespace collapsed", "  a\n\tb   c\n", 2, 0, []string{"a b", "c"}},
+		{"overlap too large is dropped", "a b c d", 2, 2, []string{"a b", "c d"}},
+		{"negative overlap is dropped", "a b c d", 2, -1, []string{"a b", "c d"}},
+		{"blank", " \n\t", 3, 1, nil},
+		{"empty", "", 3, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Chunk(tt.text, tt.size, tt.overlap); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chunk(%q, %d, %d) = %q, want %q", tt.text, tt.size, tt.overlap, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkCoversText(t *testing.T) {
+	words := make([]string, 1000)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", i)
+	}
+	text := strings.Join(words, " ")
+
+	// Zero size falls back to DefaultChunkSize
+	chunks := Chunk(text, 0, DefaultChunkOverlap)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want several", len(chunks))
+	}
+
+	// Every word appears, the last chunk ends the text and consecutive
+	// chunks share exactly the overlap
+	seen := make(map[string]bool)
+	for i, chunk := range chunks {
+		fields := strings.Fields(chunk)
+		if len(fields) > DefaultChunkSize {
+			t.Errorf("chunk %d has %d words, want at most %d", i, len(fields), DefaultChunkSize)
+		}
+		for _, w := range fields {
+			seen[w] = true
+		}
+		if i > 0 {
+			prev := strings.Fields(chunks[i-1])
+			if got, want := fields[:DefaultChunkOverlap], prev[len(prev)-DefaultChunkOverlap:]; !reflect.DeepEqual(got, want) {
+				t.Errorf("chunk %d starts with %v, want overlap %v", i, got[:3], want[:3])
+			}
+		}
+	}
+	if len(seen) != len(words) {
+		t.Errorf("chunks cover %d words, want %d", len(seen), len(words))
+	}
+	if last := strings.Fields(chunks[len(chunks)-1]); last[len(last)-1] != words[len(words)-1] {
+		t.Errorf("last chunk ends with %q, want %q", last[len(last)-1], words[len(words)-1])
+	}
+}
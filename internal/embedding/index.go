@@ -0,0 +1,259 @@
+// Package embedding keeps a per-directory vector index of extracted file
+// content, used for semantic retrieval (e.g. by the "ask" command).
+package embedding
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/DeleMike/scout/internal/cache"
+)
+
+// indexFileName is the name of the index file inside the root's cache directory
+const indexFileName = "embeddings.gob"
+
+// Chunking defaults, in words. Overlap keeps sentences that straddle a
+// boundary retrievable from either side.
+const (
+	DefaultChunkSize    = 200
+	DefaultChunkOverlap = 40
+)
+
+// Embedder turns text into a unit-length vector.
+// summarize.Embedder is the production implementation.
+type Embedder interface {
+	Embed(text string) ([]float32, error)
+	Model() string
+}
+
+// Document is one file's extracted text, ready to be chunked and embedded
+type Document struct {
+	Path    string    // Path relative to the scanned root
+	ModTime time.Time // Used to detect changed files
+	Size    int64     // Used to detect changed files
+	Text    string    // Extracted text to index
+}
+
+// Entry is a single embedded chunk of a file
+type Entry struct {
+	Path   string    // Path relative to the scanned root
+	Chunk  int       // Chunk number within the file
+	Text   string    // Chunk text
+	Vector []float32 // Unit-length embedding
+}
+
+// fileStamp records the state of a file when it was last embedded
+type fileStamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Index is the on-disk vector index for one scanned root
+type Index struct {
+	Root    string               // Absolute path of the scanned directory
+	Model   string               // Embedding model the vectors came from
+	Files   map[string]fileStamp // Indexed files and their state
+	Entries []Entry              // All embedded chunks
+}
+
+// Result is a chunk matched by a similarity search
+type Result struct {
+	Path  string  // File the chunk came from
+	Chunk int     // Chunk number within the file
+	Text  string  // Chunk text
+	Score float64 // Cosine similarity to the query (higher is better)
+}
+
+// UpdateStats summarizes what an incremental update did
+type UpdateStats struct {
+	Added     int // Files embedded for the first time
+	Updated   int // Files re-embedded because they changed
+	Removed   int // Files dropped because they no longer exist
+	Unchanged int // Files whose vectors were reused
+}
+
+// Load reads the index for root from the cache, or returns an empty index
+// if none has been built yet.
+//
+// Parameters:
+//   - root: Scanned directory
+//
+// Returns:
+//   - *Index: Existing or empty index
+//   - error: Any error locating or decoding the cache
+func Load(root string) (*Index, error) {
+	idx := &Index{Root: root, Files: make(map[string]fileStamp)}
+
+	dir, err := cache.Dir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, indexFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := gob.NewDecoder(f).Decode(idx); err != nil {
+		// A corrupt or outdated index is simply rebuilt
+		return &Index{Root: root, Files: make(map[string]fileStamp)}, nil
+	}
+	if idx.Files == nil {
+		idx.Files = make(map[string]fileStamp)
+	}
+
+	return idx, nil
+}
+
+// Save writes the index to the root's cache directory
+func (idx *Index) Save() error {
+	dir, err := cache.Dir(idx.Root)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, indexFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(idx); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, indexFileName))
+}
+
+// Update brings the index in line with docs, embedding only files that are
+// new or whose size/modification time changed, and dropping files that are
+// gone. Switching embedding models rebuilds the whole index.
+//
+// Parameters:
+//   - docs: Current extracted content of every file under the root
+//   - embedder: Model used to embed new chunks
+//
+// Returns:
+//   - UpdateStats: Counts of added, updated, removed and reused files
+//   - error: The first embedding error encountered
+func (idx *Index) Update(docs []Document, embedder Embedder) (UpdateStats, error) {
+	var stats UpdateStats
+
+	if idx.Model != embedder.Model() {
+		idx.Model = embedder.Model()
+		idx.Files = make(map[string]fileStamp)
+		idx.Entries = nil
+	}
+
+	current := make(map[string]bool, len(docs))
+	changed := make(map[string]bool)
+	for _, doc := range docs {
+		current[doc.Path] = true
+		stamp, ok := idx.Files[doc.Path]
+		switch {
+		case !ok:
+			changed[doc.Path] = true
+			stats.Added++
+		case !stamp.ModTime.Equal(doc.ModTime) || stamp.Size != doc.Size:
+			changed[doc.Path] = true
+			stats.Updated++
+		default:
+			stats.Unchanged++
+		}
+	}
+
+	// Keep entries of unchanged files that still exist
+	kept := idx.Entries[:0]
+	for _, entry := range idx.Entries {
+		if current[entry.Path] && !changed[entry.Path] {
+			kept = append(kept, entry)
+		}
+	}
+	idx.Entries = kept
+
+	for path := range idx.Files {
+		if !current[path] {
+			delete(idx.Files, path)
+			stats.Removed++
+		}
+	}
+
+	for _, doc := range docs {
+		if !changed[doc.Path] {
+			continue
+		}
+
+		for i, chunk := range Chunk(doc.Text, DefaultChunkSize, DefaultChunkOverlap) {
+			vector, err := embedder.Embed(chunk)
+			if err != nil {
+				return stats, fmt.Errorf("embedding %s: %w", doc.Path, err)
+			}
+			idx.Entries = append(idx.Entries, Entry{Path: doc.Path, Chunk: i, Text: chunk, Vector: vector})
+		}
+		idx.Files[doc.Path] = fileStamp{ModTime: doc.ModTime, Size: doc.Size}
+	}
+
+	return stats, nil
+}
+
+// Search embeds query and returns the k most similar chunks.
+//
+// Parameters:
+//   - embedder: Same model the index was built with
+//   - query: Free-text query
+//   - k: Number of results to return
+//
+// Returns:
+//   - []Result: Best matches, most similar first
+//   - error: Any error embedding the query
+func (idx *Index) Search(embedder Embedder, query string, k int) ([]Result, error) {
+	vector, err := embedder.Embed(query)
+	if err != nil {
+		return nil, err
+	}
+	return idx.SearchVector(vector, k), nil
+}
+
+// SearchVector returns the k chunks most similar to a unit-length vector
+func (idx *Index) SearchVector(vector []float32, k int) []Result {
+	results := make([]Result, 0, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		results = append(results, Result{
+			Path:  entry.Path,
+			Chunk: entry.Chunk,
+			Text:  entry.Text,
+			Score: dot(vector, entry.Vector),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// dot computes the dot product (cosine similarity for unit vectors)
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
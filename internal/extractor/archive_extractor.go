@@ -0,0 +1,602 @@
+package extractor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DeleMike/scout/internal/helpers"
+)
+
+// ArchiveExtractor lists the entries of zip, tar, tar.gz and 7z archives
+// without unpacking them to disk, and can run Scout's extractors on small
+// inner files through an fs.FS view of the archive
+type ArchiveExtractor struct {
+	Limit        int  // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+	ExtractInner bool // Also extract small inner files such as READMEs and manifests
+}
+
+const (
+	maxInnerFileSize   = 256 * 1024 // inner files larger than this are only listed
+	maxInnerFiles      = 5
+	maxArchiveListing  = 20 // paths kept for markers, nested archives and top-level entries
+	maxLargestEntries  = 5
+	maxExtensionCounts = 10
+)
+
+// archiveExtensions are inner entries reported as nested archives
+var archiveExtensions = map[string]bool{
+	".zip": true, ".tar": true, ".gz": true, ".tgz": true, ".7z": true, ".rar": true,
+	".jar": true, ".war": true, ".bz2": true, ".xz": true,
+}
+
+// archiveEntry is one file or directory listed in an archive
+type archiveEntry struct {
+	name string
+	size int64
+	dir  bool
+}
+
+// archiveListing is what a format-specific reader found
+type archiveListing struct {
+	format    string
+	entries   []archiveEntry
+	encrypted bool
+	fsys      fs.FS // view of the archive for inner extraction (nil if unavailable)
+	partial   bool  // fsys is missing files that did not fit in memory
+	note      string
+}
+
+// Extract lists an archive's contents
+func (e ArchiveExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := openRandomAccess(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var keepUpTo int64
+	if e.ExtractInner {
+		keepUpTo = maxInnerFileSize
+	}
+
+	header := make([]byte, 8)
+	n, _ := file.ReadAt(header, 0)
+	header = header[:n]
+
+	var listing *archiveListing
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		listing, err = listZip(file)
+	case bytes.HasPrefix(header, []byte("\x1F\x8B")):
+		listing, err = listGzip(file, name, keepUpTo)
+	case bytes.HasPrefix(header, []byte("7z\xBC\xAF\x27\x1C")):
+		listing = list7z(file)
+	default:
+		listing, err = listTar(file.section(), "tar", keepUpTo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %v", err)
+	}
+	details := listing.details()
+	if e.ExtractInner && listing.fsys != nil {
+		if inner := extractInnerFiles(listing); len(inner) > 0 {
+			details["inner_files"] = inner
+		}
+	}
+
+	return &ExtractedContent{
+		Category: "archive",
+		Preview:  truncatePreview(listing.preview(), previewLimit(e.Limit)),
+		Lines:    len(listing.entries),
+		Details:  details,
+	}, nil
+}
+
+// OpenArchive opens a zip file or a (gzip-compressed) tarball as a
+// read-only file system, so an archive can be scanned like a directory
+// without unpacking it. Tarballs have no index to seek with, so their
+// files are read into memory.
+//
+// Parameters:
+//   - filePath: Path to the archive
+//
+// Returns:
+//   - fs.FS: The archive's contents
+//   - io.Closer: Releases the archive once scanning is done
+//   - error: If the file is not a supported archive or is too large
+func OpenArchive(filePath string) (fs.FS, io.Closer, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	header := make([]byte, 512)
+	n, _ := file.ReadAt(header, 0)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		r, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to read archive: %v", err)
+		}
+		return r, file, nil
+	case bytes.HasPrefix(header, []byte("7z\xBC\xAF\x27\x1C")):
+		file.Close()
+		return nil, nil, fmt.Errorf("7z archives can only be listed, not scanned; unpack it first")
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	format := "tar"
+	if bytes.HasPrefix(header, []byte("\x1F\x8B")) {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %v", err)
+		}
+		defer gz.Close()
+		br := bufio.NewReaderSize(gz, 1024)
+		if block, _ := br.Peek(512); !isTarHeader(block) {
+			return nil, nil, fmt.Errorf("%s is a single compressed file, not an archive", filepath.Base(filePath))
+		}
+		r, format = br, "tar.gz"
+	} else if !isTarHeader(header) {
+		return nil, nil, fmt.Errorf("%s is not a zip or tar archive", filepath.Base(filePath))
+	}
+
+	listing, err := listTar(r, format, maxInMemoryFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read archive: %v", err)
+	}
+	if listing.partial {
+		return nil, nil, fmt.Errorf("%s is too large to scan in memory (over %s unpacked); unpack it first",
+			filepath.Base(filePath), helpers.FormatBytes(maxInMemoryFile))
+	}
+	return listing.fsys, io.NopCloser(nil), nil
+}
+
+// listZip reads the zip central directory
+func listZip(file *randomAccessFile) (*archiveListing, error) {
+	r, err := zip.NewReader(file, file.size)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &archiveListing{format: "zip", fsys: r}
+	for _, f := range r.File {
+		listing.entries = append(listing.entries, archiveEntry{
+			name: f.Name,
+			size: int64(f.UncompressedSize64),
+			dir:  f.FileInfo().IsDir(),
+		})
+		// Bit 0 of the general purpose flags marks an encrypted entry
+		if f.Flags&0x1 != 0 {
+			listing.encrypted = true
+		}
+	}
+	return listing, nil
+}
+
+// listGzip lists a gzip-compressed tarball, or a single gzip-compressed
+// file whose name and size come from the gzip header and trailer
+func listGzip(file *randomAccessFile, name string, keepUpTo int64) (*archiveListing, error) {
+	gz, err := gzip.NewReader(file.section())
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	br := bufio.NewReaderSize(gz, 1024)
+	block, _ := br.Peek(512)
+	if isTarHeader(block) {
+		return listTar(br, "tar.gz", keepUpTo)
+	}
+
+	inner := gz.Name
+	if inner == "" {
+		inner = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	// The trailer holds the uncompressed size modulo 4 GiB
+	var size int64
+	if file.size >= 4 {
+		trailer := make([]byte, 4)
+		if _, err := file.ReadAt(trailer, file.size-4); err == nil {
+			size = int64(binary.LittleEndian.Uint32(trailer))
+		}
+	}
+	return &archiveListing{format: "gzip", entries: []archiveEntry{{name: inner, size: size}}}, nil
+}
+
+// isTarHeader reports whether a 512-byte block is a tar header, by its
+// ustar magic or, for old v7 archives, its header checksum
+func isTarHeader(block []byte) bool {
+	if len(block) < 512 {
+		return false
+	}
+	if string(block[257:262]) == "ustar" {
+		return true
+	}
+	stored, err := strconv.ParseInt(strings.Trim(string(block[148:156]), " \x00"), 8, 64)
+	if err != nil {
+		return false
+	}
+	var sum int64
+	for i, b := range block {
+		if i >= 148 && i < 156 {
+			b = ' '
+		}
+		sum += int64(b)
+	}
+	return sum == stored
+}
+
+// listTar reads tar headers, skipping over file bodies. Files of up to
+// keepUpTo bytes are kept in memory (0 keeps none) so extractors can read
+// them, within an overall budget of maxInMemoryFile.
+func listTar(r io.Reader, format string, keepUpTo int64) (*archiveListing, error) {
+	tr := tar.NewReader(r)
+	listing := &archiveListing{format: format}
+	kept := newMemFS()
+	var keptSize int64
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// A truncated archive still has a useful listing so far
+			if len(listing.entries) == 0 {
+				return nil, err
+			}
+			listing.note = "archive is truncated or corrupt"
+			break
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+h.Name), "/")
+		entry := archiveEntry{name: name, size: h.Size, dir: h.Typeflag == tar.TypeDir}
+		listing.entries = append(listing.entries, entry)
+
+		if keepUpTo == 0 || !fs.ValidPath(name) {
+			continue
+		}
+		switch {
+		case entry.dir:
+			kept.addDir(name, h.ModTime)
+		case h.Typeflag != tar.TypeReg || h.Size > keepUpTo:
+		case keptSize+h.Size > maxInMemoryFile:
+			listing.partial = true
+		default:
+			data, err := io.ReadAll(tr)
+			if err == nil {
+				kept.addFile(name, data, h.ModTime)
+				keptSize += h.Size
+			}
+		}
+	}
+
+	if keepUpTo > 0 {
+		listing.fsys = kept
+	}
+	return listing, nil
+}
+
+// list7z lists a 7z archive with the 7z command line tool (or bsdtar),
+// since its headers are usually LZMA-compressed. Both tools need a file on
+// disk, so an archive read from another file system is copied to one.
+func list7z(file *randomAccessFile) *archiveListing {
+	listing := &archiveListing{format: "7z"}
+
+	var filePath string
+	if f, ok := file.ReaderAt.(*os.File); ok {
+		filePath = f.Name()
+	} else {
+		tmp, err := os.CreateTemp("", "scout-*.7z")
+		if err != nil {
+			listing.note = "could not copy the archive to list it"
+			return listing
+		}
+		defer os.Remove(tmp.Name())
+		_, err = io.Copy(tmp, file.section())
+		tmp.Close()
+		if err != nil {
+			listing.note = "could not copy the archive to list it"
+			return listing
+		}
+		filePath = tmp.Name()
+	}
+
+	for _, tool := range []string{"7z", "7zz", "7za"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			continue
+		}
+		out, err := exec.Command(tool, "l", "-slt", "-p", filePath).Output()
+		if err != nil {
+			if bytes.Contains(out, []byte("Wrong password")) || bytes.Contains(out, []byte("Can not open encrypted")) {
+				listing.encrypted = true
+			}
+			continue
+		}
+		listing.entries = parse7zSLT(out)
+		return listing
+	}
+	if listing.encrypted {
+		listing.note = "file names are encrypted"
+		return listing
+	}
+
+	if _, err := exec.LookPath("bsdtar"); err == nil {
+		if out, err := exec.Command("bsdtar", "-tvf", filePath).Output(); err == nil {
+			listing.entries = parseTarVerbose(out)
+			return listing
+		}
+	}
+
+	listing.note = "listing 7z archives needs the 7z or bsdtar command"
+	return listing
+}
+
+// parse7zSLT parses the "Key = value" blocks printed by "7z l -slt"
+func parse7zSLT(out []byte) []archiveEntry {
+	var entries []archiveEntry
+	var current *archiveEntry
+	// Entries follow the "----------" separator after the archive's own properties
+	listed := false
+	for line := range strings.SplitSeq(string(out), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "----------") {
+			listed = true
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		if !listed || !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			entries = append(entries, archiveEntry{name: filepath.ToSlash(value)})
+			current = &entries[len(entries)-1]
+		case "Size":
+			if current != nil {
+				current.size, _ = strconv.ParseInt(value, 10, 64)
+			}
+		case "Folder":
+			if current != nil && value == "+" {
+				current.dir = true
+			}
+		case "Attributes":
+			if current != nil && strings.HasPrefix(value, "D") {
+				current.dir = true
+			}
+		}
+	}
+	return entries
+}
+
+// parseTarVerbose parses "ls -l" style lines printed by "bsdtar -tv"
+func parseTarVerbose(out []byte) []archiveEntry {
+	var entries []archiveEntry
+	for line := range strings.SplitSeq(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 9 {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[4], 10, 64)
+		entries = append(entries, archiveEntry{
+			name: strings.Join(fields[8:], " "),
+			size: size,
+			dir:  strings.HasPrefix(fields[0], "d"),
+		})
+	}
+	return entries
+}
+
+// details summarises the listing: counts, sizes, extensions, and any
+// project markers or archives nested inside
+func (l *archiveListing) details() map[string]any {
+	var files, dirs int
+	var total int64
+	extensions := make(map[string]int)
+	topLevel := make(map[string]bool)
+	var topOrder, markers, nested []string
+
+	for _, entry := range l.entries {
+		name := strings.TrimSuffix(entry.name, "/")
+		if name == "" {
+			continue
+		}
+
+		first, rest, _ := strings.Cut(name, "/")
+		if rest != "" || entry.dir {
+			first += "/"
+		}
+		if !topLevel[first] {
+			topLevel[first] = true
+			topOrder = append(topOrder, first)
+		}
+
+		if entry.dir {
+			dirs++
+			continue
+		}
+		files++
+		total += entry.size
+
+		base := path.Base(name)
+		ext := strings.ToLower(path.Ext(base))
+		if ext == "" {
+			ext = "(none)"
+		}
+		extensions[ext]++
+		if helpers.IsProjectMarkerFile(base) && len(markers) < maxArchiveListing {
+			markers = append(markers, name)
+		}
+		if archiveExtensions[strings.ToLower(path.Ext(base))] && len(nested) < maxArchiveListing {
+			nested = append(nested, name)
+		}
+	}
+
+	details := map[string]any{
+		"format":      l.format,
+		"file_count":  files,
+		"dir_count":   dirs,
+		"total_size":  total,
+		"extensions":  topCounts(extensions, maxExtensionCounts),
+		"top_level":   topOrder[:min(len(topOrder), maxArchiveListing)],
+		"largest":     l.largest(),
+		"single_root": len(topOrder) == 1 && strings.HasSuffix(topOrder[0], "/"),
+	}
+	if len(markers) > 0 {
+		details["project_markers"] = markers
+	}
+	if len(nested) > 0 {
+		details["nested_archives"] = nested
+	}
+	if l.encrypted {
+		details["encrypted"] = true
+	}
+	if l.note != "" {
+		details["note"] = l.note
+	}
+	return details
+}
+
+// topCounts keeps the n most common keys (extensions, element names)
+// with their counts
+func topCounts(counts map[string]int, n int) map[string]int {
+	if len(counts) <= n {
+		return counts
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	top := make(map[string]int, n)
+	for _, key := range keys[:n] {
+		top[key] = counts[key]
+	}
+	return top
+}
+
+// largest returns the biggest files as "path (size)"
+func (l *archiveListing) largest() []string {
+	var files []archiveEntry
+	for _, entry := range l.entries {
+		if !entry.dir {
+			files = append(files, entry)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].size > files[j].size })
+
+	var largest []string
+	for _, f := range files[:min(len(files), maxLargestEntries)] {
+		largest = append(largest, fmt.Sprintf("%s (%s)", f.name, helpers.FormatBytes(f.size)))
+	}
+	return largest
+}
+
+// preview starts with a one-line summary followed by the entry listing, e.g.
+// "ZIP archive: 42 files, 1.2 MB unpacked"
+func (l *archiveListing) preview() string {
+	var b strings.Builder
+	var files int
+	var total int64
+	for _, entry := range l.entries {
+		if !entry.dir {
+			files++
+			total += entry.size
+		}
+	}
+
+	fmt.Fprintf(&b, "%s archive: %s, %s unpacked", strings.ToUpper(l.format), plural(files, "file"), helpers.FormatBytes(total))
+	if l.note != "" {
+		fmt.Fprintf(&b, " (%s)", l.note)
+	}
+	b.WriteString("\n")
+	for _, entry := range l.entries {
+		if entry.dir {
+			continue
+		}
+		fmt.Fprintf(&b, "%s (%s)\n", entry.name, helpers.FormatBytes(entry.size))
+	}
+	return b.String()
+}
+
+// extractInnerFiles runs the text-capable extractors on the most telling
+// small files inside the archive: project markers and READMEs first
+func extractInnerFiles(l *archiveListing) []map[string]any {
+	var candidates []archiveEntry
+	for _, entry := range l.entries {
+		if !entry.dir && entry.size <= maxInnerFileSize && fs.ValidPath(entry.name) {
+			candidates = append(candidates, entry)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return innerFilePriority(candidates[i].name) > innerFilePriority(candidates[j].name)
+	})
+
+	var inner []map[string]any
+	for _, entry := range candidates {
+		if len(inner) >= maxInnerFiles || innerFilePriority(entry.name) <= 0 {
+			break
+		}
+		extractor := DetectExtractor(entry.name, 0)
+		if _, binary := extractor.(BinaryExtractor); binary {
+			continue
+		}
+		content, err := extractor.Extract(l.fsys, entry.name)
+		if err != nil || content.Category == "binary" {
+			continue
+		}
+		inner = append(inner, map[string]any{
+			"name":     entry.name,
+			"category": content.Category,
+			"preview":  truncatePreview(content.Preview, 200),
+		})
+	}
+	return inner
+}
+
+// innerFilePriority ranks inner files by how much they say about the
+// archive; shallow files beat deeply nested ones (<= 0: not worth extracting)
+func innerFilePriority(name string) int {
+	base := strings.ToLower(path.Base(name))
+	score := 0
+	switch {
+	case helpers.IsProjectMarkerFile(base):
+		score = 30
+	case strings.HasPrefix(base, "readme"):
+		score = 20
+	case IsTextFile(path.Ext(base)):
+		score = 5
+	default:
+		return 0
+	}
+	return score - strings.Count(name, "/")
+}
@@ -0,0 +1,201 @@
+package extractor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// archiveFiles is the tree written into each test archive
+var archiveFiles = map[string]string{
+	"README.md":      "# Demo\n",
+	"src/main.go":    "package main\n",
+	"src/util/a.txt": "alpha",
+}
+
+// zipBytes builds a zip archive of archiveFiles
+func zipBytes(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range archiveFiles {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// tarGzBytes builds a gzip-compressed tarball of archiveFiles, listing the
+// src directory explicitly as tar does
+func tarGzBytes(t *testing.T) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	w.WriteHeader(&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0o755, ModTime: modTime})
+	for name, data := range archiveFiles {
+		w.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data)), ModTime: modTime})
+		w.Write([]byte(data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	return buf.Bytes()
+}
+
+// writeArchive writes data to a file named name in dir
+func writeArchive(t *testing.T, dir, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestArchiveExtract(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		dirs   int
+	}{
+		{"demo.zip", zipBytes(t), "zip", 0},
+		{"demo.tar.gz", tarGzBytes(t), "tar.gz", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{tt.name: {Data: tt.data}}
+			content, err := ArchiveExtractor{ExtractInner: true}.Extract(fsys, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := content.Details
+			if d["format"] != tt.format || d["file_count"] != 3 || d["dir_count"] != tt.dirs || d["total_size"] != int64(25) {
+				t.Errorf("details = %v, want %s with 3 files, %d dirs and 25 bytes", d, tt.format, tt.dirs)
+			}
+			if got := d["top_level"].([]string); !slices.Contains(got, "README.md") || !slices.Contains(got, "src/") || len(got) != 2 {
+				t.Errorf("top_level = %v, want README.md and src/", got)
+			}
+			if d["single_root"] != false {
+				t.Errorf("single_root = %v, want false", d["single_root"])
+			}
+			if !reflect.DeepEqual(d["extensions"], map[string]int{".md": 1, ".go": 1, ".txt": 1}) {
+				t.Errorf("extensions = %v", d["extensions"])
+			}
+			if inner, _ := d["inner_files"].([]map[string]any); len(inner) == 0 {
+				t.Errorf("inner_files = %v, want the README and sources extracted", d["inner_files"])
+			}
+			if !strings.HasPrefix(content.Preview, strings.ToUpper(tt.format)+" archive: 3 files, ") {
+				t.Errorf("preview = %q", content.Preview)
+			}
+		})
+	}
+}
+
+func TestParse7zSLT(t *testing.T) {
+	out := []byte("Path = demo.7z\nType = 7z\n\n----------\nPath = src\nSize = 0\nAttributes = D....\n\n" +
+		"Path = src\\main.go\r\nSize = 14\r\nAttributes = A....\r\n")
+	want := []archiveEntry{{name: "src", dir: true}, {name: "src/main.go", size: 14}}
+	if runtime.GOOS != "windows" {
+		want[1].name = "src\\main.go" // only Windows paths use backslashes
+	}
+	if got := parse7zSLT(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parse7zSLT = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTarVerbose(t *testing.T) {
+	out := []byte("drwxr-xr-x  0 me  staff     0 Mar  1 12:00 src/\n" +
+		"-rw-r--r--  0 me  staff  1024 Mar  1 12:00 src/my notes.txt\n" +
+		"garbage\n")
+	want := []archiveEntry{{name: "src/", dir: true}, {name: "src/my notes.txt", size: 1024}}
+	if got := parseTarVerbose(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTarVerbose = %+v, want %+v", got, want)
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{
+		writeArchive(t, dir, "demo.zip", zipBytes(t)),
+		writeArchive(t, dir, "demo.tar.gz", tarGzBytes(t)),
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			fsys, closer, err := OpenArchive(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closer.Close()
+
+			for name, want := range archiveFiles {
+				data, err := fs.ReadFile(fsys, name)
+				if err != nil || string(data) != want {
+					t.Errorf("ReadFile(%s) = %q, %v, want %q", name, data, err, want)
+				}
+			}
+			if err := fstest.TestFS(fsys, "README.md", "src/main.go", "src/util/a.txt"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestOpenArchiveRejects(t *testing.T) {
+	dir := t.TempDir()
+	single := filepath.Join(dir, "notes.txt.gz")
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("just some notes"))
+	gz.Close()
+	os.WriteFile(single, buf.Bytes(), 0o644)
+
+	plain := filepath.Join(dir, "notes.txt")
+	os.WriteFile(plain, []byte("just some notes"), 0o644)
+
+	for _, path := range []string{single, plain, filepath.Join(dir, "missing.zip")} {
+		if _, _, err := OpenArchive(path); err == nil {
+			t.Errorf("OpenArchive(%s): want an error", filepath.Base(path))
+		}
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newMemFS()
+	m.addFile("a/b/c.txt", []byte("deep"), modTime)
+	m.addDir("a", modTime.Add(time.Hour))
+	m.addFile("top.txt", []byte("old"), modTime)
+	m.addFile("top.txt", []byte("new"), modTime) // a later entry replaces an earlier one
+	m.addDir("empty", modTime)
+
+	if err := fstest.TestFS(m, "a/b/c.txt", "top.txt", "empty"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := fs.ReadFile(m, "top.txt"); string(data) != "new" {
+		t.Errorf("top.txt = %q, want new", data)
+	}
+	if info, err := fs.Stat(m, "a"); err != nil || !info.IsDir() || !info.ModTime().Equal(modTime.Add(time.Hour)) {
+		t.Errorf("Stat(a) = %v, %v, want a directory keeping its listed time", info, err)
+	}
+	if _, err := m.Open("missing"); err == nil {
+		t.Error("Open(missing): want an error")
+	}
+}
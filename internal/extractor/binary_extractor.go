@@ -1,13 +1,13 @@
 package extractor
 
-import "os"
+import "io/fs"
 
 // BinaryExtractor extracts contents from a word document
 type BinaryExtractor struct{}
 
 // Extract tried to extract a binary content
-func (b BinaryExtractor) Extract(path string) (*ExtractedContent, error) {
-	info, err := os.Stat(path)
+func (b BinaryExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	info, err := fs.Stat(fsys, name)
 	if err != nil {
 		return nil, err
 	}
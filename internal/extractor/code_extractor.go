@@ -1,33 +1,119 @@
 package extractor
 
 import (
-	"os"
+	"bufio"
+	"io"
+	"io/fs"
 	"strings"
 )
 
 // CodeExtractor extracts contents from a code file
-type CodeExtractor struct{}
+type CodeExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
 
 // Extract extracts content from a code file
-func (c CodeExtractor) Extract(path string) (*ExtractedContent, error) {
-	data, err := os.ReadFile(path)
+func (c CodeExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return nil, err
 	}
+	return c.extract(name, data), nil
+}
+
+// ScriptExtractor handles files without an extension: scripts are
+// recognised by their shebang line ("#!/usr/bin/env python3") and outlined
+// as code, anything else is treated as binary
+type ScriptExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
 
-	content := string(data)
+// Extract outlines a script, or describes the file as binary
+func (s ScriptExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	head, _ := reader.Peek(256)
+	if shebangLanguage(head) == "" {
+		return BinaryExtractor{}.Extract(fsys, name)
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxConfigScan))
+	if err != nil {
+		return nil, err
+	}
+	return CodeExtractor{Limit: s.Limit}.extract(name, data), nil
+}
+
+// extract builds the preview and details from source code. Files in a
+// language with an outline parser (see goOutline and outlineSource) are
+// previewed by their outline, followed by the source when a larger Limit is
+// given; other files by their first 30 lines. Blank, comment and code lines
+// are counted for every language whose comment syntax is known. The source
+// is decoded to UTF-8 first (see decodeText).
+func (c CodeExtractor) extract(name string, data []byte) *ExtractedContent {
+	content, encodingName, lineEnding := decodeText(data)
+	data = []byte(content)
 	lines := strings.Split(content, "\n")
+	head := strings.Join(lines[:min(len(lines), 30)], "\n")
+
+	var details map[string]any
+	var outline string
+	ok := false
+	language := sourceLanguage(name, data)
+	switch language {
+	case "":
+	case "go":
+		details, outline, ok = goOutline(name, data)
+	default:
+		details, outline, ok = outlineSource(language, data)
+	}
 
-	preview := strings.Join(lines[:min(len(lines), 30)], "\n")
+	var preview string
+	switch {
+	case ok && c.Limit > 0:
+		preview = truncatePreview(outline+"\n"+content, c.Limit)
+	case ok && !hasDeclarations(details):
+		// Scripts without functions say more through their first lines
+		preview = truncatePreview(outline+head, DefaultPreviewLimit)
+	case ok:
+		preview = truncatePreview(outline, DefaultPreviewLimit)
+	case c.Limit > 0:
+		preview = truncatePreview(content, c.Limit)
+	default:
+		preview = head
+	}
+	if !ok {
+		details = map[string]any{"imports": extractImports(lines)}
+		if language != "" {
+			details["language"] = language
+		}
+	}
+	if counts, found := countLines(language, content); found {
+		details["line_counts"] = counts
+	}
+	details["encoding"] = encodingName
+	details["line_endings"] = lineEnding
 
 	return &ExtractedContent{
 		Category: "code",
 		Preview:  preview,
 		Lines:    len(lines),
-		Details: map[string]any{
-			"imports": extractImports(lines),
-		},
-	}, nil
+		Details:  details,
+	}
+}
+
+// hasDeclarations reports whether an outline found any types or functions
+func hasDeclarations(details map[string]any) bool {
+	for _, key := range []string{"types", "functions", "methods"} {
+		if _, ok := details[key]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func extractImports(lines []string) []string {
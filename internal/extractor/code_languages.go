@@ -0,0 +1,364 @@
+package extractor
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Line matchers of the outline parsers (see scanBraces and scanIndented).
+// Each recognises the declarations of one language on a single trimmed
+// line, records imports directly, and returns the declaration, if any.
+
+// braceSyntaxes lists the brace-delimited languages with an outline parser
+var braceSyntaxes = map[string]braceSyntax{
+	"javascript": {lineComments: []string{"//"}, quotes: "\"'`", match: matchJavaScript},
+	"typescript": {lineComments: []string{"//"}, quotes: "\"'`", match: matchJavaScript},
+	"java":       {lineComments: []string{"//"}, quotes: `"`, charLiterals: true, match: matchJava},
+	"rust":       {lineComments: []string{"///", "//!", "//"}, quotes: `"`, charLiterals: true, match: matchRust},
+	"c":          {lineComments: []string{"//"}, quotes: `"`, charLiterals: true, match: matchC},
+	"cpp":        {lineComments: []string{"//"}, quotes: `"`, charLiterals: true, match: matchC},
+	"dart":       {lineComments: []string{"///", "//"}, quotes: `"'`, match: matchDart},
+	"php":        {lineComments: []string{"//", "#"}, quotes: `"'`, match: matchPHP},
+	"shell":      {lineComments: []string{"#"}, quotes: `"'`, match: matchShell},
+}
+
+// indentSyntax strips literals and comments from Python and Ruby lines
+var indentSyntax = braceSyntax{lineComments: []string{"#"}, quotes: `"'`}
+
+var (
+	pyImportRe = regexp.MustCompile(`^import\s+(.+)$`)
+	pyFromRe   = regexp.MustCompile(`^from\s+(\S+)\s+import\b`)
+	pyClassRe  = regexp.MustCompile(`^class\s+([A-Za-z_]\w*)`)
+	pyDefRe    = regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z_]\w*)\s*\(`)
+	pyMainRe   = regexp.MustCompile(`^if\s+__name__\s*==\s*['"]__main__['"]`)
+)
+
+// matchPython recognises imports, classes, functions and the
+// `if __name__ == "__main__"` guard
+func matchPython(o *codeOutline, line string, scope *indentScope) *codeDecl {
+	if m := pyFromRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := pyImportRe.FindStringSubmatch(line); m != nil {
+		for _, module := range strings.Split(m[1], ",") {
+			if fields := strings.Fields(module); len(fields) > 0 {
+				o.addImport(fields[0])
+			}
+		}
+		return nil
+	}
+	if scope == nil && pyMainRe.MatchString(line) {
+		o.hasMain = true
+		return nil
+	}
+	if m := pyClassRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "type", name: m[1], typeKind: "class", declaration: declarationText(line), opens: "class"}
+	}
+	if m := pyDefRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "function", name: m[1], declaration: declarationText(line), opens: "function"}
+	}
+	return nil
+}
+
+var (
+	rbRequireRe = regexp.MustCompile(`^(?:require|require_relative|load)\s*\(?\s*['"]([^'"]+)['"]`)
+	rbTypeRe    = regexp.MustCompile(`^(class|module)\s+([A-Z][\w:]*)`)
+	rbDefRe     = regexp.MustCompile(`^def\s+(?:self\.)?([\w]+[?!=]?)`)
+)
+
+// matchRuby recognises requires, classes, modules and methods
+func matchRuby(o *codeOutline, line string, scope *indentScope) *codeDecl {
+	if m := rbRequireRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := rbTypeRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "type", name: m[2], typeKind: m[1], declaration: line, opens: "class"}
+	}
+	if m := rbDefRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "function", name: m[1], declaration: strings.TrimSuffix(line, "; end"), opens: "function"}
+	}
+	return nil
+}
+
+var (
+	jsImportRe    = regexp.MustCompile(`^import\s(?:.*\bfrom\s*)?['"]([^'"]+)['"]`)
+	jsRequireRe   = regexp.MustCompile(`\brequire\(\s*['"]([^'"]+)['"]\s*\)`)
+	jsTypeRe      = regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(class|interface|enum|type)\s+([A-Za-z_$][\w$]*)`)
+	jsNamespaceRe = regexp.MustCompile(`^(?:export\s+)?(?:declare\s+)?(namespace|module)\s+([A-Za-z_$][\w$.]*)\s*\{`)
+	jsFunctionRe  = regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?function\b\s*\*?\s*([A-Za-z_$][\w$]*)`)
+	jsArrowRe     = regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]*)?=\s*(?:async\s+)?(?:function\b|(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*(?::[^=]*)?=>)`)
+	jsMethodRe    = regexp.MustCompile(`^(?:(?:public|private|protected|static|readonly|async|override|abstract|get|set|declare)\s+)*\*?(#?[A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\s*\(`)
+	jsPropertyRe  = regexp.MustCompile(`^(?:(?:public|private|protected|static|readonly)\s+)*(#?[A-Za-z_$][\w$]*)\s*(?::[^=]*)?=\s*(?:async\s+)?(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*(?::[^=]*)?=>`)
+)
+
+// jsKeywords are words followed by "(" that do not start a method
+var jsKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"function": true, "super": true, "new": true, "typeof": true, "await": true,
+}
+
+// matchJavaScript recognises ES and CommonJS imports, classes, TypeScript
+// interfaces, enums, types and namespaces, functions, arrow functions
+// assigned to constants, and class methods
+func matchJavaScript(o *codeOutline, line string, scope *braceScope) *codeDecl {
+	if scope != nil && scope.kind == "class" {
+		m := jsMethodRe.FindStringSubmatch(line)
+		if m == nil {
+			m = jsPropertyRe.FindStringSubmatch(line)
+		}
+		if m != nil && !jsKeywords[m[1]] {
+			return &codeDecl{kind: "function", name: m[1], declaration: declarationText(line)}
+		}
+		return nil
+	}
+	if m := jsImportRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := jsRequireRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := jsNamespaceRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "type", name: m[2], typeKind: "namespace", declaration: declarationText(line), opens: "namespace"}
+	}
+	if m := jsTypeRe.FindStringSubmatch(line); m != nil {
+		d := &codeDecl{kind: "type", name: m[2], typeKind: m[1], declaration: declarationText(line)}
+		if m[1] == "class" {
+			d.opens = "class"
+		}
+		return d
+	}
+	if m := jsFunctionRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "function", name: m[1], declaration: declarationText(line)}
+	}
+	if m := jsArrowRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "function", name: m[1], declaration: declarationText(line)}
+	}
+	return nil
+}
+
+var (
+	javaPackageRe = regexp.MustCompile(`^package\s+([\w.]+)\s*;`)
+	javaImportRe  = regexp.MustCompile(`^import\s+(?:static\s+)?([\w.*]+)\s*;`)
+	javaTypeRe    = regexp.MustCompile(`^(?:(?:public|protected|private|abstract|static|final|sealed|non-sealed|strictfp)\s+)*(class|interface|enum|record|@interface)\s+([A-Za-z_$][\w$]*)`)
+	javaMethodRe  = regexp.MustCompile(`^(?:(?:public|protected|private|abstract|static|final|synchronized|native|default|strictfp)\s+)*(?:<[^>]+>\s+)?([\w$.]+(?:<[^=;(]*>)?(?:\[\])*\s+)?([A-Za-z_$][\w$]*)\s*\(`)
+)
+
+// matchJava recognises the package, imports, types and their methods and
+// constructors
+func matchJava(o *codeOutline, line string, scope *braceScope) *codeDecl {
+	if m := javaTypeRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "type", name: m[2], typeKind: strings.TrimPrefix(m[1], "@"), declaration: declarationText(line), opens: "class"}
+	}
+	if scope == nil {
+		if m := javaPackageRe.FindStringSubmatch(line); m != nil {
+			o.pkg = m[1]
+		} else if m := javaImportRe.FindStringSubmatch(line); m != nil {
+			o.addImport(m[1])
+		}
+		return nil
+	}
+	m := javaMethodRe.FindStringSubmatch(line)
+	if m == nil || m[1] == "" && m[2] != scope.owner || m[1] == "return " || m[1] == "new " {
+		return nil
+	}
+	if m[2] == "main" && strings.Contains(line, "static") && strings.Contains(line, "void") {
+		o.hasMain = true
+	}
+	return &codeDecl{kind: "function", name: m[2], declaration: declarationText(line)}
+}
+
+var (
+	rsUseRe   = regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?use\s+([\w:]+)`)
+	rsCrateRe = regexp.MustCompile(`^extern\s+crate\s+(\w+)`)
+	rsTypeRe  = regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?(struct|enum|trait|union|type)\s+(\w+)`)
+	rsImplRe  = regexp.MustCompile(`^(?:unsafe\s+)?impl\b(?:\s*<[^{]*?>)?\s+(?:.+?\s+for\s+)?([\w:]+)`)
+	rsFnRe    = regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:default\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(\w+)`)
+)
+
+// matchRust recognises use declarations, types, traits, impl blocks (whose
+// functions are listed as methods of the type) and functions
+func matchRust(o *codeOutline, line string, scope *braceScope) *codeDecl {
+	if m := rsFnRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "function", name: m[1], declaration: declarationText(line)}
+	}
+	if scope != nil && scope.kind == "class" {
+		return nil
+	}
+	if m := rsUseRe.FindStringSubmatch(line); m != nil {
+		o.addImport(strings.TrimSuffix(m[1], "::"))
+		return nil
+	}
+	if m := rsCrateRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := rsTypeRe.FindStringSubmatch(line); m != nil {
+		d := &codeDecl{kind: "type", name: m[2], typeKind: m[1], declaration: declarationText(line)}
+		if m[1] == "trait" {
+			d.opens = "class"
+		}
+		return d
+	}
+	if m := rsImplRe.FindStringSubmatch(line); m != nil {
+		name := m[1][strings.LastIndex(m[1], ":")+1:]
+		return &codeDecl{kind: "block", name: name, declaration: declarationText(line), opens: "class"}
+	}
+	return nil
+}
+
+var (
+	cIncludeRe   = regexp.MustCompile(`^#\s*include\s*[<"]([^>"]+)[>"]`)
+	cNamespaceRe = regexp.MustCompile(`^(?:inline\s+)?namespace\s*([\w:]*)\s*\{?|^extern\s+"C"\s*\{`)
+	cTypeRe      = regexp.MustCompile(`^(?:typedef\s+)?(?:template\s*<[^>]*>\s*)?(struct|class|union|enum(?:\s+class|\s+struct)?)\s+(?:\w+\s+)??([A-Za-z_]\w*)\s*(?:final\s*)?(?::[^:]|\{|$)`)
+	cFuncRe      = regexp.MustCompile(`^(?:template\s*<[^>]*>\s*)?(.*?[\w*&>\]])\s+[*&]*((?:[A-Za-z_]\w*::)*~?[A-Za-z_]\w*)\s*\(`)
+	cMemberRe    = regexp.MustCompile(`^(?:explicit\s+|virtual\s+)*(~?[A-Za-z_]\w*)\s*\(|^((?:[A-Za-z_]\w*::)+~?[A-Za-z_]\w*)\s*\(`)
+)
+
+// cKeywords start statements and expressions, never a return type
+var cKeywords = map[string]bool{
+	"return": true, "else": true, "if": true, "while": true, "for": true, "switch": true,
+	"case": true, "goto": true, "delete": true, "new": true, "throw": true, "sizeof": true,
+	"typedef": true, "using": true, "do": true,
+}
+
+// matchC recognises includes, namespaces, structs, classes, unions and
+// enums, function definitions and prototypes, and C++ members, including
+// those defined out of line (Parser::parse)
+func matchC(o *codeOutline, line string, scope *braceScope) *codeDecl {
+	if m := cIncludeRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if strings.HasPrefix(line, "#") {
+		return nil
+	}
+	if m := cNamespaceRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "block", name: m[1], declaration: declarationText(line), opens: "namespace"}
+	}
+	if m := cTypeRe.FindStringSubmatch(line); m != nil && !strings.Contains(line, "(") && (strings.Contains(line, "{") || !strings.HasSuffix(line, ";")) {
+		return &codeDecl{kind: "type", name: m[2], typeKind: m[1], declaration: declarationText(line), opens: "class"}
+	}
+
+	name := ""
+	if m := cFuncRe.FindStringSubmatch(line); m != nil && !cKeywords[strings.Fields(m[1])[0]] && !strings.ContainsAny(m[1], "=(") {
+		name = m[2]
+	} else if m := cMemberRe.FindStringSubmatch(line); m != nil {
+		switch {
+		case m[2] != "":
+			name = m[2]
+		case scope != nil && scope.kind == "class" && strings.TrimPrefix(m[1], "~") == scope.owner:
+			name = m[1]
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	// Parser::parse is a member of Parser, even outside its class body
+	if i := strings.LastIndex(name, "::"); i >= 0 && (scope == nil || scope.kind != "class") {
+		owner := name[:i]
+		owner = owner[strings.LastIndex(owner, ":")+1:]
+		return &codeDecl{kind: "function", name: name[i+2:], owner: owner, declaration: declarationText(line)}
+	}
+	return &codeDecl{kind: "function", name: name, declaration: declarationText(line)}
+}
+
+var (
+	dartImportRe = regexp.MustCompile(`^import\s+['"]([^'"]+)['"]`)
+	dartTypeRe   = regexp.MustCompile(`^(?:(?:abstract|base|final|sealed|interface)\s+)*(class|mixin|enum|extension(?:\s+type)?)\s+([A-Za-z_$][\w$]*)`)
+	dartAliasRe  = regexp.MustCompile(`^typedef\s+([A-Za-z_$][\w$]*)`)
+	dartFuncRe   = regexp.MustCompile(`^(?:(?:static|external|abstract|factory|const)\s+)*(?:[\w<>?,\[\] ]+?\s+)?(?:(?:get|set|operator)\s+)?([A-Za-z_$][\w$.]*)\s*(?:<[^>]*>)?\s*(?:\(|=>|\{)`)
+)
+
+// dartKeywords are words followed by "(" that do not start a declaration
+var dartKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"else": true, "assert": true, "await": true, "super": true,
+}
+
+// matchDart recognises imports, classes, mixins, enums, extensions,
+// typedefs, functions, getters and constructors
+func matchDart(o *codeOutline, line string, scope *braceScope) *codeDecl {
+	if m := dartImportRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := dartTypeRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "type", name: m[2], typeKind: m[1], declaration: declarationText(line), opens: "class"}
+	}
+	if m := dartAliasRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "type", name: m[1], typeKind: "typedef", declaration: declarationText(line)}
+	}
+	if m := dartFuncRe.FindStringSubmatch(line); m != nil && !dartKeywords[m[1]] && !strings.Contains(line, " = ") {
+		return &codeDecl{kind: "function", name: m[1], declaration: dartDeclaration(line)}
+	}
+	return nil
+}
+
+// dartDeclaration drops the expression body of "int get size => _items.length;"
+func dartDeclaration(line string) string {
+	if i := strings.Index(line, "=>"); i > 0 {
+		line = line[:i]
+	}
+	return declarationText(line)
+}
+
+var (
+	phpNamespaceRe = regexp.MustCompile(`^namespace\s+([\w\\]+)\s*(;|\{)`)
+	phpUseRe       = regexp.MustCompile(`^use\s+(?:function\s+|const\s+)?([\w\\]+)`)
+	phpRequireRe   = regexp.MustCompile(`^(?:require|require_once|include|include_once)\s*\(?\s*['"]([^'"]+)['"]`)
+	phpTypeRe      = regexp.MustCompile(`^(?:(?:abstract|final|readonly)\s+)*(class|interface|trait|enum)\s+(\w+)`)
+	phpFuncRe      = regexp.MustCompile(`^(?:(?:public|protected|private|static|abstract|final)\s+)*function\s+&?(\w+)\s*\(`)
+)
+
+// matchPHP recognises the namespace, use and require statements, classes,
+// interfaces, traits, enums, functions and methods
+func matchPHP(o *codeOutline, line string, scope *braceScope) *codeDecl {
+	if m := phpFuncRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "function", name: m[1], declaration: declarationText(line)}
+	}
+	if scope != nil && scope.kind == "class" {
+		return nil
+	}
+	if m := phpNamespaceRe.FindStringSubmatch(line); m != nil {
+		o.pkg = m[1]
+		if m[2] == "{" {
+			return &codeDecl{kind: "block", name: m[1], declaration: declarationText(line), opens: "namespace"}
+		}
+		return nil
+	}
+	if m := phpUseRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := phpRequireRe.FindStringSubmatch(line); m != nil {
+		o.addImport(m[1])
+		return nil
+	}
+	if m := phpTypeRe.FindStringSubmatch(line); m != nil {
+		return &codeDecl{kind: "type", name: m[2], typeKind: m[1], declaration: declarationText(line), opens: "class"}
+	}
+	return nil
+}
+
+var (
+	shFuncRe   = regexp.MustCompile(`^(?:function\s+([\w:.-]+)|([\w:.-]+)\s*\(\s*\))`)
+	shSourceRe = regexp.MustCompile(`^(?:source|\.)\s+["']?([^\s"';]+)`)
+)
+
+// matchShell recognises functions and sourced scripts
+func matchShell(o *codeOutline, line string, scope *braceScope) *codeDecl {
+	if m := shSourceRe.FindStringSubmatch(line); m != nil {
+		o.addImport(path.Clean(m[1]))
+		return nil
+	}
+	if m := shFuncRe.FindStringSubmatch(line); m != nil {
+		name := m[1] + m[2]
+		return &codeDecl{kind: "function", name: name, declaration: name + "()"}
+	}
+	return nil
+}
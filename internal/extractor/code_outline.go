@@ -0,0 +1,591 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const maxDeclaration = 160 // longest declaration line kept in an outline
+
+// codeLanguages maps source file extensions to their language, which picks
+// the outline parser and the comment syntax used to count lines
+var codeLanguages = map[string]string{
+	".go": "go",
+	".py": "python", ".pyw": "python",
+	".js": "javascript", ".jsx": "javascript", ".mjs": "javascript", ".cjs": "javascript",
+	".ts": "typescript", ".tsx": "typescript", ".mts": "typescript", ".cts": "typescript",
+	".java": "java",
+	".rs":   "rust",
+	".c":    "c", ".h": "c",
+	".cpp": "cpp", ".cc": "cpp", ".cxx": "cpp", ".hpp": "cpp", ".hh": "cpp", ".hxx": "cpp",
+	".dart": "dart",
+	".rb":   "ruby",
+	".php":  "php",
+	".sh":   "shell", ".bash": "shell",
+	".kt": "kotlin", ".kts": "kotlin",
+	".cs":    "csharp",
+	".swift": "swift",
+}
+
+// shebangInterpreters maps the interpreter named on a "#!" line to the
+// language of the script
+var shebangInterpreters = map[string]string{
+	"python": "python",
+	"node":   "javascript", "nodejs": "javascript", "deno": "javascript", "bun": "javascript",
+	"ts-node": "typescript", "tsx": "typescript",
+	"ruby":        "ruby",
+	"php":         "php",
+	"dart":        "dart",
+	"rust-script": "rust",
+	"sh":          "shell", "bash": "shell", "zsh": "shell", "ksh": "shell", "dash": "shell",
+}
+
+// sourceLanguage names the language of a source file by its extension,
+// falling back to the interpreter on its shebang line
+func sourceLanguage(name string, data []byte) string {
+	if language, ok := codeLanguages[strings.ToLower(path.Ext(name))]; ok {
+		return language
+	}
+	return shebangLanguage(data)
+}
+
+// shebangLanguage reads the interpreter from a "#!/usr/bin/env python3"
+// line; versions ("python3.12", "php8") and env flags are ignored
+func shebangLanguage(data []byte) string {
+	line, ok := bytes.CutPrefix(data, []byte("#!"))
+	if !ok {
+		return ""
+	}
+	line, _, _ = bytes.Cut(line, []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = path.Base(field)
+				break
+			}
+		}
+	}
+	return shebangInterpreters[strings.TrimRight(interpreter, "0123456789.")]
+}
+
+// codeDecl is a declaration recognised by a language's line matcher
+type codeDecl struct {
+	kind        string // "type", "function" or "block" (outlined but not listed, e.g. a Rust impl)
+	name        string
+	typeKind    string // class, interface, struct, trait...
+	owner       string // class of a member defined outside its body (C++ Parser::parse)
+	declaration string // the declaration as written, without its body
+	opens       string // scope the body opens: "class" (functions in it are methods), "namespace" or ""
+}
+
+// codeOutline collects the declarations of a source file in the order they
+// appear, along with the lists reported in the details
+type codeOutline struct {
+	language  string
+	pkg       string // Java package or PHP namespace
+	doc       string // module docstring or leading comment
+	imports   []string
+	types     []string
+	functions []string
+	methods   []string
+	docs      map[string]string
+	hasMain   bool
+	seen      map[string]bool
+	body      strings.Builder
+}
+
+// outlineSource outlines a file in one of the languages of codeLanguages or
+// shebangInterpreters. ok is false when no parser exists for the language.
+func outlineSource(language string, data []byte) (details map[string]any, outline string, ok bool) {
+	o := &codeOutline{
+		language: language,
+		docs:     make(map[string]string),
+		seen:     make(map[string]bool),
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	switch language {
+	case "python":
+		scanIndented(o, text, matchPython)
+	case "ruby":
+		scanIndented(o, text, matchRuby)
+	default:
+		syntax, found := braceSyntaxes[language]
+		if !found {
+			return nil, "", false
+		}
+		scanBraces(o, syntax, text)
+	}
+	return o.details(), o.String(), true
+}
+
+// addImport records an imported module once
+func (o *codeOutline) addImport(module string) {
+	module = strings.TrimSpace(module)
+	if module == "" || o.seen["import "+module] {
+		return
+	}
+	o.seen["import "+module] = true
+	o.imports = append(o.imports, module)
+}
+
+// add records a declaration; functions declared inside a class are listed
+// as its methods. Repeated declarations (overloads, a C prototype and its
+// definition) are kept once.
+func (o *codeOutline) add(d codeDecl, owner string, indent int, doc string) {
+	key := d.name
+	if owner != "" {
+		key = owner + "." + d.name
+	}
+	if o.seen[d.kind+" "+key] {
+		return
+	}
+	o.seen[d.kind+" "+key] = true
+
+	switch {
+	case d.kind == "type":
+		o.types = append(o.types, key+" "+d.typeKind)
+	case d.kind == "function" && owner != "":
+		o.methods = append(o.methods, owner+"."+callSignature(d.declaration, d.name))
+	case d.kind == "function":
+		o.functions = append(o.functions, callSignature(d.declaration, d.name))
+		if d.name == "main" {
+			o.hasMain = true
+		}
+	}
+	if doc != "" && d.kind != "block" {
+		o.docs[key] = doc
+	}
+
+	prefix := strings.Repeat("\t", indent)
+	fmt.Fprintf(&o.body, "%s%s\n", prefix, truncatePreview(collapseSpace(d.declaration), maxDeclaration))
+	if doc != "" {
+		fmt.Fprintf(&o.body, "%s\t// %s\n", prefix, doc)
+	}
+}
+
+// String renders the outline: package, file doc and imports, then the
+// declarations, methods indented under their class
+func (o *codeOutline) String() string {
+	var b strings.Builder
+	if o.pkg != "" && o.language == "php" {
+		fmt.Fprintf(&b, "namespace %s\n", o.pkg)
+	} else if o.pkg != "" {
+		fmt.Fprintf(&b, "package %s\n", o.pkg)
+	}
+	if o.doc != "" {
+		fmt.Fprintf(&b, "// %s\n", o.doc)
+	}
+	if len(o.imports) > 0 {
+		fmt.Fprintf(&b, "import %s\n", strings.Join(o.imports, ", "))
+	}
+	b.WriteString(o.body.String())
+	return b.String()
+}
+
+// details reports the outline in the same keys as goOutline
+func (o *codeOutline) details() map[string]any {
+	details := map[string]any{"language": o.language}
+	if o.pkg != "" {
+		details["package"] = o.pkg
+	}
+	if o.doc != "" {
+		details["doc"] = o.doc
+	}
+	if len(o.imports) > 0 {
+		details["imports"] = o.imports
+	}
+	for key, list := range map[string][]string{"types": o.types, "functions": o.functions, "methods": o.methods} {
+		if len(list) > 0 {
+			details[key] = list[:min(len(list), maxOutlineSymbols)]
+		}
+	}
+	if len(o.docs) > 0 {
+		details["docs"] = o.docs
+	}
+	if o.hasMain {
+		details["has_main"] = true
+	}
+	return details
+}
+
+// setFileDoc takes a comment block that precedes all declarations as the
+// file's description, unless it is a license header
+func (o *codeOutline) setFileDoc(comment []string) {
+	if o.doc != "" || len(comment) == 0 || len(o.types)+len(o.functions)+len(o.methods) > 0 {
+		return
+	}
+	text := strings.Join(comment, " ")
+	lower := strings.ToLower(text)
+	if strings.Contains(lower, "copyright") || strings.Contains(lower, "license") || strings.Contains(lower, "spdx-") {
+		return
+	}
+	o.doc = commentSentence(comment)
+}
+
+// commentSentence returns the first sentence of a comment block, stopping
+// at Javadoc-style tags ("@param")
+func commentSentence(comment []string) string {
+	var text []string
+	for _, line := range comment {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "*!/#"))
+		if strings.HasPrefix(line, "@") {
+			break
+		}
+		text = append(text, line)
+	}
+	return firstSentence(strings.Join(text, " "))
+}
+
+// firstSentence returns the first sentence of text, bounded in length
+func firstSentence(text string) string {
+	text = collapseSpace(text)
+	if i := strings.Index(text, ". "); i >= 0 {
+		text = text[:i+1]
+	}
+	return truncatePreview(text, maxOutlineDoc)
+}
+
+// callSignature shortens a declaration to the name and its parameter list,
+// e.g. "export async function load(path: string): Promise<void>" becomes
+// "load(path: string)"
+func callSignature(declaration, name string) string {
+	start := strings.Index(declaration, name)
+	if start < 0 {
+		return name + "()"
+	}
+	open := strings.Index(declaration[start:], "(")
+	if open < 0 {
+		return name
+	}
+	open += start
+	depth := 0
+	for i := open; i < len(declaration); i++ {
+		switch declaration[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return name + collapseSpace(declaration[open:i+1])
+			}
+		}
+	}
+	return name + "(...)"
+}
+
+// declarationText trims a declaration line down to the declaration itself,
+// dropping the body, a Python colon or a trailing semicolon. Parameter
+// lists continued on the next lines are closed with "...)".
+func declarationText(line string) string {
+	parens := 0
+body:
+	for i, c := range line {
+		switch c {
+		case '(':
+			parens++
+		case ')':
+			parens--
+		case '{':
+			if parens <= 0 && i > 0 {
+				line = line[:i]
+				break body
+			}
+		}
+	}
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(line, ";")
+	line = strings.TrimSuffix(line, ":")
+	line = strings.TrimSpace(line)
+	if open := strings.Count(line, "(") - strings.Count(line, ")"); open > 0 {
+		line += "..." + strings.Repeat(")", open)
+	}
+	return line
+}
+
+// braceScope is a class, impl block or namespace whose body the brace
+// scanner is in
+type braceScope struct {
+	kind  string
+	owner string
+	depth int // brace depth of the body
+}
+
+// braceSyntax describes a C-family language for scanBraces
+type braceSyntax struct {
+	lineComments []string // longest first: "///" before "//"
+	quotes       string   // characters that delimit strings
+	charLiterals bool     // single quotes delimit one character ('a', '\n'), not strings
+	match        func(o *codeOutline, line string, scope *braceScope) *codeDecl
+}
+
+// scanBraces outlines a language whose blocks are delimited by braces.
+// Declarations are only looked for at the top level and directly inside
+// classes and namespaces, so nothing inside function bodies is listed.
+func scanBraces(o *codeOutline, syntax braceSyntax, text string) {
+	var scopes []braceScope
+	var comment []string // comment lines above the next declaration
+	var opening *braceScope
+	depth, inBlock := 0, false
+
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(raw)
+		if inBlock {
+			end := strings.Index(line, "*/")
+			if end < 0 {
+				comment = append(comment, line)
+				continue
+			}
+			comment = append(comment, line[:end])
+			inBlock = false
+			if line = strings.TrimSpace(line[end+2:]); line == "" {
+				continue
+			}
+		}
+		if rest, ok := strings.CutPrefix(line, "/*"); ok {
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				inBlock = true
+				comment = append(comment, rest)
+				continue
+			}
+			comment = append(comment, rest[:end])
+			if line = strings.TrimSpace(rest[end+2:]); line == "" {
+				continue
+			}
+		}
+		// Decorators and attributes sit between a doc comment and its declaration
+		decoration := strings.HasPrefix(line, "@") && !strings.HasPrefix(line, "@interface") || strings.HasPrefix(line, "#[")
+		if text, ok := lineComment(line, syntax.lineComments); ok && !decoration {
+			comment = append(comment, text)
+			continue
+		}
+		if line == "" {
+			o.setFileDoc(comment)
+			comment = nil
+			continue
+		}
+
+		code := strings.TrimSpace(stripLiterals(line, syntax))
+		bodyDepth := 0
+		var scope *braceScope
+		if len(scopes) > 0 {
+			scope = &scopes[len(scopes)-1]
+			bodyDepth = scope.depth
+		}
+		if depth == bodyDepth && opening == nil && !decoration {
+			if d := syntax.match(o, line, scope); d != nil {
+				owner, indent := "", 0
+				for _, s := range scopes {
+					if s.kind == "class" {
+						indent++
+					}
+				}
+				if scope != nil && scope.kind == "class" {
+					owner = scope.owner
+				}
+				if d.owner != "" {
+					owner = d.owner
+				}
+				o.add(*d, owner, indent, commentSentence(comment))
+				if d.opens != "" && (strings.Contains(code, "{") || !strings.HasSuffix(code, ";")) {
+					opening = &braceScope{kind: d.opens, owner: d.name}
+				}
+			} else {
+				o.setFileDoc(comment)
+			}
+		}
+		if !decoration {
+			comment = nil
+		}
+
+		if opening != nil && strings.Contains(code, "{") {
+			opening.depth = depth + 1
+			scopes = append(scopes, *opening)
+			opening = nil
+		} else if opening != nil && strings.HasSuffix(code, ";") {
+			opening = nil
+		}
+		depth += strings.Count(code, "{") - strings.Count(code, "}")
+		depth = max(depth, 0)
+		for len(scopes) > 0 && depth < scopes[len(scopes)-1].depth {
+			scopes = scopes[:len(scopes)-1]
+		}
+	}
+}
+
+// lineComment returns the text of a whole-line comment
+func lineComment(line string, markers []string) (string, bool) {
+	if strings.HasPrefix(line, "#!") {
+		return "", true
+	}
+	for _, marker := range markers {
+		if text, ok := strings.CutPrefix(line, marker); ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// stripLiterals blanks out string and character literals and drops a
+// trailing comment, so braces inside them are not counted
+func stripLiterals(line string, syntax braceSyntax) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		for _, marker := range syntax.lineComments {
+			if strings.HasPrefix(line[i:], marker) && (marker != "#" || i == 0 || line[i-1] == ' ') {
+				return b.String()
+			}
+		}
+		if c == '\'' && syntax.charLiterals {
+			// 'a' or '\n'; anything else is a Rust lifetime
+			if end := strings.IndexByte(line[min(i+2, len(line)):], '\''); end >= 0 && end <= 2 {
+				i += 2 + end
+				continue
+			}
+		} else if strings.IndexByte(syntax.quotes, c) >= 0 {
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = j
+			b.WriteString(`""`)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// indentScope is a class or function whose body the indentation scanner is in
+type indentScope struct {
+	kind   string
+	owner  string
+	indent int
+}
+
+// scanIndented outlines Python and Ruby, whose nesting follows the
+// indentation. Python docstrings are preferred over "#" comments as docs.
+func scanIndented(o *codeOutline, text string, match func(o *codeOutline, line string, scope *indentScope) *codeDecl) {
+	lines := strings.Split(text, "\n")
+	var scopes []indentScope
+	var comment []string
+	inString := ""
+	brackets := 0 // brackets left open by a statement spanning lines
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if inString != "" {
+			if strings.Contains(line, inString) {
+				inString = ""
+			}
+			continue
+		}
+		if brackets > 0 {
+			brackets = max(brackets+bracketBalance(line), 0)
+			continue
+		}
+		if line == "" {
+			o.setFileDoc(comment)
+			comment = nil
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "#"); ok {
+			if !strings.HasPrefix(rest, "!") && !strings.Contains(rest, "-*-") {
+				comment = append(comment, rest)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+		for len(scopes) > 0 && indent <= scopes[len(scopes)-1].indent {
+			scopes = scopes[:len(scopes)-1]
+		}
+		var scope *indentScope
+		if len(scopes) > 0 {
+			scope = &scopes[len(scopes)-1]
+		}
+
+		if o.language == "python" && o.doc == "" && len(scopes) == 0 && len(o.types)+len(o.functions) == 0 && isDocstring(line) {
+			o.doc = docstring(lines, i)
+		}
+		if scope == nil || scope.kind == "class" {
+			if d := match(o, line, scope); d != nil {
+				owner := ""
+				if scope != nil {
+					owner = scope.owner
+				}
+				doc := commentSentence(comment)
+				if o.language == "python" && i+1 < len(lines) && isDocstring(strings.TrimSpace(lines[i+1])) {
+					doc = docstring(lines, i+1)
+				}
+				o.add(*d, owner, len(scopes), doc)
+				if d.opens != "" {
+					scopes = append(scopes, indentScope{kind: d.opens, owner: d.name, indent: indent})
+				}
+			} else {
+				o.setFileDoc(comment)
+			}
+		}
+		comment = nil
+
+		for _, quote := range []string{`"""`, `'''`} {
+			if strings.Count(line, quote)%2 == 1 {
+				inString = quote
+				break
+			}
+		}
+		if inString == "" {
+			brackets = max(bracketBalance(line), 0)
+		}
+	}
+}
+
+// bracketBalance counts the brackets a Python or Ruby line opens minus
+// those it closes
+func bracketBalance(line string) int {
+	code := stripLiterals(line, indentSyntax)
+	return strings.Count(code, "(") + strings.Count(code, "[") + strings.Count(code, "{") -
+		strings.Count(code, ")") - strings.Count(code, "]") - strings.Count(code, "}")
+}
+
+// isDocstring reports whether a line starts a triple-quoted string
+func isDocstring(line string) bool {
+	line = strings.TrimLeft(line, "rRuU")
+	return strings.HasPrefix(line, `"""`) || strings.HasPrefix(line, `'''`)
+}
+
+// docstring returns the first sentence of the triple-quoted string that
+// starts at lines[start]
+func docstring(lines []string, start int) string {
+	line := strings.TrimLeft(strings.TrimSpace(lines[start]), "rRuU")
+	quote := line[:3]
+	rest := line[3:]
+	var text []string
+	for i := start + 1; ; i++ {
+		if end := strings.Index(rest, quote); end >= 0 {
+			text = append(text, rest[:end])
+			break
+		}
+		text = append(text, rest)
+		if i >= len(lines) {
+			break
+		}
+		rest = lines[i]
+	}
+	return firstSentence(strings.Join(text, " "))
+}
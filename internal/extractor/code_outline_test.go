@@ -0,0 +1,448 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutlineSource(t *testing.T) {
+	tests := []struct {
+		language string
+		src      string
+		outline  string
+	}{
+		{
+			language: "python",
+			src: `#!/usr/bin/env python3
+"""Tools for parsing logs. More text."""
+import os, sys as system
+from collections import defaultdict
+
+CONFIG = {
+    "a": 1,
+}
+
+class Parser(Base):
+    """Parses a log line."""
+
+    def __init__(self, path):
+        self.path = path
+
+    @property
+    def name(self):
+        def inner():
+            pass
+        return "x"
+
+    async def fetch(self,
+                    url):
+        pass
+
+# Entry point
+def main():
+    text = """
+def fake():
+"""
+    return 0
+`,
+			outline: `// Tools for parsing logs.
+import os, sys, collections
+class Parser(Base)
+	// Parses a log line.
+	def __init__(self, path)
+	def name(self)
+	async def fetch(self,...)
+def main()
+	// Entry point
+`,
+		},
+		{
+			language: "ruby",
+			src: `# Billing helpers
+require "json"
+require_relative "lib/tax"
+
+module Billing
+  # An invoice
+  class Invoice < Base
+    def total(items)
+      items.sum
+    end
+
+    def self.build
+    end
+  end
+
+  def helper; end
+end
+`,
+			outline: `// Billing helpers
+import json, lib/tax
+module Billing
+	class Invoice < Base
+		// An invoice
+		def total(items)
+		def self.build
+	def helper
+`,
+		},
+		{
+			language: "javascript",
+			src: `/**
+ * Route handlers for the API.
+ */
+import express from "express";
+const fs = require("fs");
+
+// Handles a user request
+export async function getUser(req, res) {
+  if (x) { return "}"; }
+}
+
+export default class UserStore extends Store {
+  constructor(db) { this.db = db; }
+  // Finds one user
+  async find(id) {
+    return this.db.get(id);
+  }
+  static create() {}
+}
+
+export const handler = async (event) => {
+  return 1;
+};
+`,
+			outline: `// Route handlers for the API.
+import express, fs
+export async function getUser(req, res)
+	// Handles a user request
+export default class UserStore extends Store
+	constructor(db)
+	async find(id)
+		// Finds one user
+	static create()
+export const handler = async (event) =>
+`,
+		},
+		{
+			language: "typescript",
+			src: `import { Injectable } from "@angular/core";
+export interface User { id: string }
+export type ID = string | number;
+enum Color { Red }
+export abstract class Repo<T> implements Base {
+  private items: T[] = [];
+  public async save(item: T): Promise<void> {}
+}
+function main(): void {}
+`,
+			outline: `import @angular/core
+export interface User
+export type ID = string | number
+enum Color
+export abstract class Repo<T> implements Base
+	public async save(item: T): Promise<void>
+function main(): void
+`,
+		},
+		{
+			language: "java",
+			src: `package com.acme.shop;
+
+import java.util.List;
+import static java.lang.Math.max;
+
+/** A shopping cart. */
+@Entity
+public class Cart implements Serializable {
+    private List<Item> items;
+
+    /** Adds an item. */
+    @Override
+    public void add(Item item) {
+        if (item == null) { throw new IllegalArgumentException("{"); }
+    }
+
+    public static void main(String[] args) {}
+
+    enum State { OPEN, CLOSED }
+}
+
+interface Priced { int price(); }
+record Point(int x, int y) {}
+`,
+			outline: `package com.acme.shop
+import java.util.List, java.lang.Math.max
+public class Cart implements Serializable
+	// A shopping cart.
+	public void add(Item item)
+		// Adds an item.
+	public static void main(String[] args)
+	enum State
+interface Priced
+record Point(int x, int y)
+`,
+		},
+		{
+			language: "rust",
+			src: `//! Geometry helpers.
+use std::fmt;
+use crate::util::{a, b};
+
+/// A point in space.
+#[derive(Debug)]
+pub struct Point<'a> { x: f64, name: &'a str }
+
+pub trait Shape { fn area(&self) -> f64; }
+
+impl fmt::Display for Point<'_> {
+    /// Formats the point.
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result { write!(f, "{}", self.x) }
+}
+
+pub(crate) async fn load(path: &str) -> Result<(), Error> {
+    let c = '{';
+    Ok(())
+}
+
+fn main() {}
+`,
+			outline: `// Geometry helpers.
+import std::fmt, crate::util
+pub struct Point<'a>
+	// A point in space.
+pub trait Shape
+impl fmt::Display for Point<'_>
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result
+		// Formats the point.
+pub(crate) async fn load(path: &str) -> Result<(), Error>
+fn main()
+`,
+		},
+		{
+			language: "c",
+			src: `/* Copyright 2024 Acme. MIT license. */
+#include <stdio.h>
+#include "util.h"
+
+/* Counts words. */
+int count_words(const char *s);
+
+typedef struct node {
+    int value;
+} node_t;
+
+int count_words(const char *s) {
+    return 0;
+}
+
+int main(int argc, char **argv) { return 0; }
+`,
+			outline: `import stdio.h, util.h
+int count_words(const char *s)
+	// Counts words.
+typedef struct node
+int main(int argc, char **argv)
+`,
+		},
+		{
+			language: "cpp",
+			src: `#include <vector>
+namespace geo {
+// A shape
+class Shape : public Base {
+public:
+    virtual double area() const = 0;
+    Shape(int n);
+};
+double Shape::perimeter() const { return 0; }
+template <typename T>
+T clamp(T v) { return v; }
+}
+`,
+			outline: `import vector
+namespace geo
+class Shape : public Base
+	// A shape
+	virtual double area() const = 0
+	Shape(int n)
+double Shape::perimeter() const
+T clamp(T v)
+`,
+		},
+		{
+			language: "dart",
+			src: `import 'package:flutter/material.dart';
+
+/// The app root.
+class MyApp extends StatelessWidget {
+  @override
+  Widget build(BuildContext context) {
+    return Text('}');
+  }
+}
+
+void main() => runApp(MyApp());
+mixin Logger {}
+`,
+			outline: `import package:flutter/material.dart
+class MyApp extends StatelessWidget
+	// The app root.
+	Widget build(BuildContext context)
+void main()
+mixin Logger
+`,
+		},
+		{
+			language: "php",
+			src: `<?php
+namespace App\Http;
+
+use Illuminate\Http\Request;
+
+/** Handles users. */
+final class UserController extends Controller
+{
+    public function index(Request $request) { return "}"; }
+    private static function helper() {}
+}
+
+function global_helper($x) {}
+trait Loggable {}
+`,
+			outline: `namespace App\Http
+import Illuminate\Http\Request
+final class UserController extends Controller
+	// Handles users.
+	public function index(Request $request)
+	private static function helper()
+function global_helper($x)
+trait Loggable
+`,
+		},
+		{
+			language: "shell",
+			src: `#!/bin/bash
+# Deploys the app
+deploy() {
+  echo "}"
+}
+function cleanup {
+  rm -rf tmp
+}
+`,
+			outline: `deploy()
+	// Deploys the app
+cleanup()
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		_, outline, ok := outlineSource(tt.language, []byte(tt.src))
+		if !ok {
+			t.Errorf("outlineSource(%q) failed", tt.language)
+			continue
+		}
+		if outline != tt.outline {
+			t.Errorf("outlineSource(%q) outline =\n%s\nwant\n%s", tt.language, outline, tt.outline)
+		}
+	}
+
+	if _, _, ok := outlineSource("kotlin", []byte("fun main() {}\n")); ok {
+		t.Error("outlineSource(kotlin) succeeded for an unsupported language")
+	}
+}
+
+func TestOutlineSourceDetails(t *testing.T) {
+	src := `/* Copyright 2024 Acme. Licensed under the MIT license. */
+
+// Package-level helpers for carts.
+#include "cart.h"
+
+// A cart line.
+struct line { int qty; };
+
+// Sums the cart.
+int total(struct line *lines, int n) {
+    return 0;
+}
+
+double Cart::tax(double rate) const { return 0; }
+`
+	details, _, ok := outlineSource("cpp", []byte(src))
+	if !ok {
+		t.Fatal("outlineSource failed")
+	}
+	want := map[string]any{
+		"language":  "cpp",
+		"doc":       "Package-level helpers for carts.",
+		"imports":   []string{"cart.h"},
+		"types":     []string{"line struct"},
+		"functions": []string{"total(struct line *lines, int n)"},
+		"methods":   []string{"Cart.tax(double rate)"},
+		"docs":      map[string]string{"line": "A cart line.", "total": "Sums the cart."},
+	}
+	if !reflect.DeepEqual(details, want) {
+		t.Errorf("details = %v\nwant %v", details, want)
+	}
+}
+
+func TestSourceLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"app.PY", "", "python"},
+		{"lib/util.rs", "", "rust"},
+		{"deploy", "#!/bin/bash\necho hi\n", "shell"},
+		{"run", "#!/usr/bin/env python3\n", "python"},
+		{"run", "#!/usr/bin/env -S python3.12 -u\n", "python"},
+		{"run", "#!/usr/bin/env LANG=C ruby\n", "ruby"},
+		{"notes", "plain text\n", ""},
+		{"run", "#!\n", ""},
+	}
+	for _, tt := range tests {
+		if got := sourceLanguage(tt.name, []byte(tt.data)); got != tt.want {
+			t.Errorf("sourceLanguage(%q, %q) = %q, want %q", tt.name, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestCallSignature(t *testing.T) {
+	tests := []struct {
+		decl, name, want string
+	}{
+		{"pub fn load(path: &str) -> Result<(), Error>", "load", "load(path: &str)"},
+		{"def fetch(self,\n          url)", "fetch", "fetch(self, url)"},
+		{"void run(Callback cb = f(1), int n)", "run", "run(Callback cb = f(1), int n)"},
+		{"def fetch(self,...)", "fetch", "fetch(self,...)"},
+		{"def open(self, (a", "open", "open(...)"},
+		{"class Parser", "Parser", "Parser"},
+		{"export const handler = async () =>", "missing", "missing()"},
+	}
+	for _, tt := range tests {
+		if got := callSignature(tt.decl, tt.name); got != tt.want {
+			t.Errorf("callSignature(%q, %q) = %q, want %q", tt.decl, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeclarationText(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"public void add(Item item) {", "public void add(Item item)"},
+		{"fn area(&self) -> f64;", "fn area(&self) -> f64"},
+		{"def name(self):", "def name(self)"},
+		{"async def fetch(self,", "async def fetch(self,...)"},
+		{"void each(std::function<void(int)> f = [](int) {}) {", "void each(std::function<void(int)> f = [](int) {})"},
+		{"{", "{"},
+	}
+	for _, tt := range tests {
+		if got := declarationText(tt.line); got != tt.want {
+			t.Errorf("declarationText(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package extractor
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value types inferred for spreadsheet and CSV cells
+const (
+	typeText     = "text"
+	typeNumber   = "number"
+	typeCurrency = "currency"
+	typePercent  = "percent"
+	typeDate     = "date"
+	typeBoolean  = "boolean"
+)
+
+// headerSearchRows bounds how far down a sheet the header row is looked for;
+// report titles and blank lines often sit above it
+const headerSearchRows = 5
+
+// currencySymbols are stripped from a value before it is parsed as a number
+const currencySymbols = "$€£¥₦₹"
+
+// dateLayouts are the date formats recognised in cell text, including the
+// formats excelize renders for Excel's built-in date styles
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"1/2/2006",
+	"1/2/06",
+	"01-02-06",
+	"02.01.2006",
+	"2-Jan-06",
+	"02-Jan-2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"1/2/06 15:04",
+	time.RFC3339,
+}
+
+// parseDate parses s using the recognised dateLayouts
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseNumber parses a plain number, allowing thousands separators and
+// accounting-style negatives such as "(1,200.50)"
+func parseNumber(s string) (float64, bool) {
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	if negative {
+		s = s[1 : len(s)-1]
+	}
+	s = strings.ReplaceAll(s, ",", "")
+	// ParseFloat also accepts "NaN", "Inf" and hex floats, which are words here
+	if s == "" || strings.ContainsAny(strings.ToLower(s), "abcdfghijklmnopqrstuvwxyz") {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		v = -v
+	}
+	return v, true
+}
+
+// valueType infers the type of a single cell value, returning "" for blanks
+func valueType(value string) string {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return ""
+	}
+
+	if _, ok := parseNumber(s); ok {
+		return typeNumber
+	}
+	if strings.HasSuffix(s, "%") {
+		if _, ok := parseNumber(strings.TrimSpace(strings.TrimSuffix(s, "%"))); ok {
+			return typePercent
+		}
+	}
+	if strings.ContainsAny(s, currencySymbols) {
+		stripped := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(currencySymbols, r) || r == ' ' {
+				return -1
+			}
+			return r
+		}, s)
+		if _, ok := parseNumber(stripped); ok {
+			return typeCurrency
+		}
+	}
+	if strings.EqualFold(s, "true") || strings.EqualFold(s, "false") {
+		return typeBoolean
+	}
+	if _, ok := parseDate(s); ok {
+		return typeDate
+	}
+	return typeText
+}
+
+// detectHeaderRow returns the index of the row holding column headers, or
+// -1 if none is found. A header row has at least two cells, all of them
+// distinct text, and is followed by at least one row.
+func detectHeaderRow(rows [][]string) int {
+	for i := 0; i < len(rows)-1 && i < headerSearchRows; i++ {
+		seen := make(map[string]bool)
+		filled := 0
+		header := true
+		for _, cell := range rows[i] {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			if valueType(cell) != typeText || seen[strings.ToLower(cell)] {
+				header = false
+				break
+			}
+			seen[strings.ToLower(cell)] = true
+			filled++
+		}
+		if header && filled >= 2 {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnTypes infers each column's type as the most common non-blank value
+// type among the given data rows
+func columnTypes(rows [][]string) []string {
+	var counts []map[string]int
+	for _, row := range rows {
+		for col, cell := range row {
+			for len(counts) <= col {
+				counts = append(counts, make(map[string]int))
+			}
+			if t := valueType(cell); t != "" {
+				counts[col][t]++
+			}
+		}
+	}
+
+	types := make([]string, len(counts))
+	for col, c := range counts {
+		types[col] = dominantType(c)
+	}
+	return types
+}
+
+// dominantType returns the most frequent type in counts ("" when empty).
+// Ties are broken towards the more specific type.
+func dominantType(counts map[string]int) string {
+	best, bestCount := "", 0
+	for _, t := range []string{typeDate, typeCurrency, typePercent, typeNumber, typeBoolean, typeText} {
+		if counts[t] > bestCount {
+			best, bestCount = t, counts[t]
+		}
+	}
+	return best
+}
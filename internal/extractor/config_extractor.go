@@ -0,0 +1,747 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigExtractor parses JSON, YAML and TOML files and reports their shape
+// (top-level keys, nesting depth, array sizes) and the schema family they
+// follow, such as a Kubernetes manifest, a GitHub Actions workflow or an
+// OpenAPI description
+type ConfigExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+const (
+	maxConfigScan      = 16 * 1024 * 1024 // larger files are previewed, not parsed
+	maxConfigKeys      = 20
+	maxConfigArrays    = 10 // array sizes reported, largest first
+	maxConfigListItems = 20 // jobs, services, resources or images kept
+)
+
+// configNameSchemas recognises well-known files by name alone
+var configNameSchemas = map[string]string{
+	"chart.yaml":              "helm-chart",
+	"kustomization.yaml":      "kustomize",
+	"kustomization.yml":       "kustomize",
+	".gitlab-ci.yml":          "gitlab-ci",
+	"devcontainer.json":       "devcontainer",
+	".devcontainer.json":      "devcontainer",
+	"jsconfig.json":           "tsconfig",
+	"renovate.json":           "renovate",
+	".eslintrc.json":          "eslint",
+	".prettierrc.json":        "prettier",
+	".babelrc.json":           "babel",
+	"babel.config.json":       "babel",
+	"mkdocs.yml":              "mkdocs",
+	".golangci.yml":           "golangci-lint",
+	".golangci.yaml":          "golangci-lint",
+	".pre-commit-config.yaml": "pre-commit",
+	"dependabot.yml":          "dependabot",
+	"codecov.yml":             "codecov",
+	"serverless.yml":          "serverless",
+	"netlify.toml":            "netlify",
+	"vercel.json":             "vercel",
+	"firebase.json":           "firebase",
+	"angular.json":            "angular",
+}
+
+// configSchemaNames label each schema family in previews
+var configSchemaNames = map[string]string{
+	"openapi":        "OpenAPI description",
+	"json-schema":    "JSON Schema",
+	"kubernetes":     "Kubernetes manifest",
+	"kustomize":      "Kustomization",
+	"helm-chart":     "Helm chart",
+	"helm-values":    "Helm values",
+	"helm-template":  "Helm template",
+	"github-actions": "GitHub Actions workflow",
+	"gitlab-ci":      "GitLab CI pipeline",
+	"docker-compose": "Docker Compose file",
+	"cloudformation": "CloudFormation template",
+	"ansible":        "Ansible playbook",
+	"serverless":     "Serverless Framework config",
+	"tsconfig":       "TypeScript config",
+	"devcontainer":   "Dev container config",
+	"eslint":         "ESLint config",
+	"prettier":       "Prettier config",
+	"babel":          "Babel config",
+	"renovate":       "Renovate config",
+	"dependabot":     "Dependabot config",
+	"pre-commit":     "pre-commit hooks",
+	"golangci-lint":  "golangci-lint config",
+	"mkdocs":         "MkDocs site config",
+	"codecov":        "Codecov config",
+	"netlify":        "Netlify config",
+	"vercel":         "Vercel config",
+	"firebase":       "Firebase config",
+	"angular":        "Angular workspace",
+}
+
+// Extract parses a config file and describes its structure
+func (e ConfigExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxConfigScan+1))
+	if err != nil {
+		return nil, err
+	}
+	limit := previewLimit(e.Limit)
+	format := configFormat(name)
+	base := strings.ToLower(path.Base(name))
+
+	details := map[string]any{"type": "config", "format": format}
+	var docs []any
+	switch {
+	case len(data) > maxConfigScan:
+		data = data[:maxConfigScan]
+		details["truncated"] = true
+	default:
+		docs, err = decodeConfig(format, data)
+	}
+
+	schema := configSchema(base, docs, data)
+	if err != nil && schema != "helm-template" {
+		// Broken files are worth pointing out rather than skipping
+		details["parse_error"] = err.Error()
+	}
+	if schema != "" {
+		details["schema"] = schema
+	}
+
+	if len(docs) > 0 {
+		if len(docs) > 1 {
+			details["documents"] = len(docs)
+		}
+		shape := configShape{arrays: make(map[string]int)}
+		keys := make(map[string]bool)
+		for _, doc := range docs {
+			shape.walk(doc, "", 0)
+			if m, ok := doc.(map[string]any); ok {
+				for key := range m {
+					keys[key] = true
+				}
+			}
+		}
+		if len(keys) > 0 {
+			details["top_level_keys"] = firstSorted(keys, maxConfigKeys)
+			details["key_count"] = len(keys)
+		}
+		details["depth"] = shape.depth
+		if len(shape.arrays) > 0 {
+			details["array_sizes"] = topCounts(shape.arrays, maxConfigArrays)
+		}
+		addSchemaDetails(details, schema, docs)
+	} else if schema == "helm-template" {
+		addTemplateKinds(details, data)
+	}
+
+	preview := configPreviewTitle(format, schema, details) + "\n\n" + string(data)
+	return &ExtractedContent{
+		Category: "config",
+		Preview:  truncatePreview(preview, limit),
+		Lines:    bytes.Count(data, []byte("\n")) + 1,
+		Details:  details,
+	}, nil
+}
+
+// configFormat returns "json", "yaml" or "toml" from a file's extension
+func configFormat(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	default:
+		return "json"
+	}
+}
+
+// decodeConfig parses a config file into generic values, one per YAML
+// document. Maps always come back as map[string]any and arrays as []any.
+func decodeConfig(format string, data []byte) ([]any, error) {
+	var docs []any
+	switch format {
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var doc any
+			err := decoder.Decode(&doc)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return docs, fmt.Errorf("invalid YAML: %v", err)
+			}
+			// Empty documents ("---" at the end of a file) hold nothing
+			if doc != nil {
+				docs = append(docs, normalizeConfig(doc))
+			}
+		}
+	case "toml":
+		var doc map[string]any
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid TOML: %v", err)
+		}
+		docs = append(docs, normalizeConfig(doc))
+	default:
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			// tsconfig.json, VS Code settings and devcontainer.json allow
+			// comments and trailing commas
+			if json.Unmarshal(stripJSONComments(data), &doc) != nil {
+				return nil, fmt.Errorf("invalid JSON: %v", err)
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// normalizeConfig converts the map and array types YAML and TOML decoders
+// produce into map[string]any and []any
+func normalizeConfig(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			v[key] = normalizeConfig(child)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, child := range v {
+			m[fmt.Sprint(key)] = normalizeConfig(child)
+		}
+		return m
+	case []any:
+		for i, child := range v {
+			v[i] = normalizeConfig(child)
+		}
+		return v
+	case []map[string]any:
+		list := make([]any, len(v))
+		for i, child := range v {
+			list[i] = normalizeConfig(child)
+		}
+		return list
+	}
+	return v
+}
+
+// stripJSONComments removes // and /* */ comments and trailing commas
+// outside of strings
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == '}' || c == ']':
+			trimmed := bytes.TrimRight(out, " \t\r\n")
+			if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+				out = trimmed[:len(trimmed)-1]
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// configShape measures how deeply a config nests and how long its arrays are
+type configShape struct {
+	depth  int
+	arrays map[string]int // dotted path → largest length seen
+}
+
+// walk visits v at the given path; depth counts the maps and arrays above it
+func (s *configShape) walk(v any, at string, depth int) {
+	switch v := v.(type) {
+	case map[string]any:
+		depth++
+		s.depth = max(s.depth, depth)
+		for key, child := range v {
+			if at != "" {
+				key = at + "." + key
+			}
+			s.walk(child, key, depth)
+		}
+	case []any:
+		depth++
+		s.depth = max(s.depth, depth)
+		if at == "" {
+			at = "(root)"
+		}
+		s.arrays[at] = max(s.arrays[at], len(v))
+		for _, child := range v {
+			s.walk(child, at+"[]", depth)
+		}
+	}
+}
+
+// configSchema names the schema family a config follows, first by file
+// name, then by the keys of its documents ("" when unknown)
+func configSchema(base string, docs []any, data []byte) string {
+	// Helm templates are YAML with Go template actions, which rarely parse
+	if bytes.Contains(data, []byte("{{")) &&
+		(bytes.Contains(data, []byte(".Values")) || bytes.Contains(data, []byte(".Release")) || bytes.Contains(data, []byte(".Chart"))) {
+		return "helm-template"
+	}
+
+	if schema, ok := configNameSchemas[base]; ok {
+		return schema
+	}
+	stem := strings.TrimSuffix(strings.TrimSuffix(base, path.Ext(base)), ".")
+	switch {
+	case strings.HasPrefix(stem, "tsconfig") && path.Ext(base) == ".json":
+		return "tsconfig"
+	case strings.HasPrefix(stem, "docker-compose"), stem == "compose", strings.HasPrefix(stem, "compose."):
+		return "docker-compose"
+	case stem == "values" || strings.HasPrefix(stem, "values-") || strings.HasPrefix(stem, "values."):
+		return "helm-values"
+	}
+
+	if len(docs) == 0 {
+		return ""
+	}
+	if plays, ok := docs[0].([]any); ok && len(plays) > 0 {
+		if play, ok := plays[0].(map[string]any); ok && play["hosts"] != nil {
+			return "ansible"
+		}
+		return ""
+	}
+	m, ok := docs[0].(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case m["openapi"] != nil || m["swagger"] != nil:
+		return "openapi"
+	case m["AWSTemplateFormatVersion"] != nil:
+		return "cloudformation"
+	case strings.Contains(configString(m, "$schema"), "json-schema.org") &&
+		(m["properties"] != nil || m["type"] != nil || m["$defs"] != nil || m["definitions"] != nil):
+		return "json-schema"
+	case isKubernetesManifest(docs):
+		if configString(m, "kind") == "Kustomization" {
+			return "kustomize"
+		}
+		return "kubernetes"
+	case configMap(m, "jobs") != nil && (m["on"] != nil || m["true"] != nil):
+		// YAML 1.1 readers turn the "on" key into true
+		return "github-actions"
+	case isComposeFile(m):
+		return "docker-compose"
+	case m["stages"] != nil && hasScriptJob(m):
+		return "gitlab-ci"
+	case configMap(m, "compilerOptions") != nil:
+		return "tsconfig"
+	}
+	return ""
+}
+
+// isKubernetesManifest reports whether every document is a Kubernetes
+// object (apiVersion plus kind)
+func isKubernetesManifest(docs []any) bool {
+	for _, doc := range docs {
+		m, ok := doc.(map[string]any)
+		if !ok || configString(m, "apiVersion") == "" || configString(m, "kind") == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isComposeFile reports whether a config declares containers under "services"
+func isComposeFile(m map[string]any) bool {
+	for _, service := range configMap(m, "services") {
+		if s, ok := service.(map[string]any); ok && (s["image"] != nil || s["build"] != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasScriptJob reports whether any top-level map has a "script", as
+// GitLab CI jobs do
+func hasScriptJob(m map[string]any) bool {
+	for _, value := range m {
+		if job, ok := value.(map[string]any); ok && job["script"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// addSchemaDetails records the fields that matter for a schema family
+func addSchemaDetails(details map[string]any, schema string, docs []any) {
+	m, _ := docs[0].(map[string]any)
+	set := func(key, value string) {
+		if value != "" {
+			details[key] = value
+		}
+	}
+	setList := func(key string, values []string) {
+		if len(values) > 0 {
+			details[key] = values[:min(len(values), maxConfigListItems)]
+		}
+	}
+
+	switch schema {
+	case "openapi":
+		info := configMap(m, "info")
+		set("title", configString(info, "title"))
+		set("version", configString(info, "version"))
+		set("spec_version", configString(m, "openapi")+configString(m, "swagger"))
+		details["paths"] = len(configMap(m, "paths"))
+
+	case "json-schema":
+		set("title", configString(m, "title"))
+		set("draft", configString(m, "$schema"))
+		if properties := configMap(m, "properties"); properties != nil {
+			details["properties"] = len(properties)
+		}
+
+	case "kustomize":
+		resources := configStrings(m["resources"])
+		if len(resources) > 0 {
+			details["resource_count"] = len(resources)
+			setList("resources", resources)
+		}
+
+	case "kubernetes", "helm-template":
+		kinds := make(map[string]int)
+		var resources []string
+		for _, doc := range docs {
+			obj, _ := doc.(map[string]any)
+			kind := configString(obj, "kind")
+			if kind == "" {
+				continue
+			}
+			kinds[kind]++
+			resource := kind
+			if name := configString(configMap(obj, "metadata"), "name"); name != "" {
+				resource += "/" + name
+			}
+			resources = append(resources, resource)
+		}
+		if len(kinds) > 0 {
+			details["kinds"] = topCounts(kinds, maxConfigArrays)
+			details["resource_count"] = len(resources)
+			setList("resources", resources)
+		}
+		images := make(map[string]bool)
+		for _, doc := range docs {
+			collectImages(doc, images)
+		}
+		setList("images", firstSorted(images, maxConfigListItems))
+
+	case "helm-chart":
+		set("title", configString(m, "name"))
+		set("version", configString(m, "version"))
+		set("app_version", configString(m, "appVersion"))
+		set("description", configString(m, "description"))
+		var dependencies []string
+		for _, dep := range configList(m, "dependencies") {
+			if name := configString(dep, "name"); name != "" {
+				dependencies = append(dependencies, name)
+			}
+		}
+		setList("dependencies", dependencies)
+
+	case "helm-values":
+		images := make(map[string]bool)
+		collectImages(m, images)
+		setList("images", firstSorted(images, maxConfigListItems))
+
+	case "github-actions":
+		set("title", configString(m, "name"))
+		on := m["on"]
+		if on == nil {
+			on = m["true"]
+		}
+		triggers := configStrings(on)
+		if events, ok := on.(map[string]any); ok {
+			triggers = firstSorted(keySet(events), maxConfigListItems)
+		}
+		setList("triggers", triggers)
+		jobs := configMap(m, "jobs")
+		setList("jobs", firstSorted(keySet(jobs), maxConfigListItems))
+		steps := 0
+		for _, job := range jobs {
+			if j, ok := job.(map[string]any); ok {
+				steps += len(configList(j, "steps"))
+			}
+		}
+		details["steps"] = steps
+
+	case "gitlab-ci":
+		setList("stages", configStrings(m["stages"]))
+		var jobs []string
+		for name, value := range m {
+			if job, ok := value.(map[string]any); ok && job["script"] != nil {
+				jobs = append(jobs, name)
+			}
+		}
+		sort.Strings(jobs)
+		setList("jobs", jobs)
+
+	case "docker-compose":
+		services := configMap(m, "services")
+		setList("services", firstSorted(keySet(services), maxConfigListItems))
+		images := make(map[string]bool)
+		for _, service := range services {
+			if image := configString(service, "image"); image != "" {
+				images[image] = true
+			}
+		}
+		setList("images", firstSorted(images, maxConfigListItems))
+
+	case "cloudformation":
+		set("title", configString(m, "Description"))
+		types := make(map[string]int)
+		resources := configMap(m, "Resources")
+		for _, resource := range resources {
+			if kind := configString(resource, "Type"); kind != "" {
+				types[kind]++
+			}
+		}
+		details["resource_count"] = len(resources)
+		if len(types) > 0 {
+			details["kinds"] = topCounts(types, maxConfigArrays)
+		}
+
+	case "ansible":
+		plays, _ := docs[0].([]any)
+		details["plays"] = len(plays)
+		hosts := make(map[string]bool)
+		roles := make(map[string]bool)
+		for _, play := range plays {
+			for _, host := range configStrings(configValue(play, "hosts")) {
+				hosts[host] = true
+			}
+			for _, role := range configStrings(configValue(play, "roles")) {
+				roles[role] = true
+			}
+		}
+		setList("hosts", firstSorted(hosts, maxConfigListItems))
+		setList("roles", firstSorted(roles, maxConfigListItems))
+
+	case "serverless":
+		set("title", configString(m, "service"))
+		set("provider", configString(configMap(m, "provider"), "name")+configString(m, "provider"))
+		setList("functions", firstSorted(keySet(configMap(m, "functions")), maxConfigListItems))
+
+	case "tsconfig":
+		options := configMap(m, "compilerOptions")
+		set("target", configString(options, "target"))
+		set("module", configString(options, "module"))
+		set("extends", configString(m, "extends"))
+		if strict, ok := options["strict"].(bool); ok {
+			details["strict"] = strict
+		}
+	}
+}
+
+// addTemplateKinds records the kinds a Helm template declares, read line
+// by line since the template itself is not valid YAML
+func addTemplateKinds(details map[string]any, data []byte) {
+	kinds := make(map[string]int)
+	count := 0
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if kind, ok := strings.CutPrefix(strings.TrimRight(line, "\r "), "kind: "); ok && !strings.Contains(kind, "{{") {
+			kinds[strings.Trim(kind, `"'`)]++
+			count++
+		}
+	}
+	if count > 0 {
+		details["kinds"] = topCounts(kinds, maxConfigArrays)
+		details["resource_count"] = count
+	}
+}
+
+// collectImages gathers the container images referenced anywhere in a
+// config: "image: nginx:1.25" in manifests and Compose files, or Helm's
+// "image: {repository: nginx, tag: 1.25}"
+func collectImages(v any, images map[string]bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if key != "image" {
+				collectImages(child, images)
+				continue
+			}
+			switch image := child.(type) {
+			case string:
+				if image != "" && !strings.Contains(image, "{{") {
+					images[image] = true
+				}
+			case map[string]any:
+				if repository := configString(image, "repository"); repository != "" {
+					if tag := configString(image, "tag"); tag != "" {
+						repository += ":" + tag
+					}
+					images[repository] = true
+				}
+			}
+		}
+	case []any:
+		for _, child := range v {
+			collectImages(child, images)
+		}
+	}
+}
+
+// configPreviewTitle is the first preview line, e.g.
+// "Kubernetes manifest: 3 resources (Deployment, Service, Ingress)"
+func configPreviewTitle(format, schema string, details map[string]any) string {
+	label := strings.ToUpper(format) + " config"
+	if name, ok := configSchemaNames[schema]; ok {
+		label = name
+	}
+	if _, ok := details["parse_error"]; ok {
+		return fmt.Sprintf("%s (does not parse: %s)", label, details["parse_error"])
+	}
+
+	title, _ := details["title"].(string)
+	if version, ok := details["version"].(string); ok && title != "" {
+		title += " " + version
+	}
+	switch schema {
+	case "kustomize":
+		if resources, ok := details["resources"].([]string); ok {
+			return fmt.Sprintf("%s: %s (%s)", label, plural(len(resources), "resource"), strings.Join(resources, ", "))
+		}
+	case "kubernetes", "helm-template", "cloudformation":
+		count, _ := details["resource_count"].(int)
+		kinds, _ := details["kinds"].(map[string]int)
+		if count > 0 {
+			return fmt.Sprintf("%s: %s (%s)", label, plural(count, "resource"), strings.Join(firstSorted(keySet(kinds), 5), ", "))
+		}
+	case "github-actions", "gitlab-ci":
+		if jobs, ok := details["jobs"].([]string); ok {
+			if title != "" {
+				label = fmt.Sprintf("%s %q", label, title)
+			}
+			return fmt.Sprintf("%s: %s (%s)", label, plural(len(jobs), "job"), strings.Join(jobs, ", "))
+		}
+	case "docker-compose":
+		if services, ok := details["services"].([]string); ok {
+			return fmt.Sprintf("%s: %s (%s)", label, plural(len(services), "service"), strings.Join(services, ", "))
+		}
+	case "openapi":
+		paths, _ := details["paths"].(int)
+		return fmt.Sprintf("%s %s: %s (%s)", label, details["spec_version"], title, plural(paths, "path"))
+	}
+
+	if title != "" {
+		return fmt.Sprintf("%s: %s", label, title)
+	}
+	if keys, ok := details["top_level_keys"].([]string); ok {
+		return fmt.Sprintf("%s: %s (%s)", label, plural(details["key_count"].(int), "top-level key"),
+			strings.Join(keys[:min(len(keys), 8)], ", "))
+	}
+	return label
+}
+
+// configValue returns m[key] when v is a map
+func configValue(v any, key string) any {
+	m, _ := v.(map[string]any)
+	return m[key]
+}
+
+// configMap returns m[key] when v is a map holding a map there
+func configMap(v any, key string) map[string]any {
+	child, _ := configValue(v, key).(map[string]any)
+	return child
+}
+
+// configList returns m[key] when v is a map holding an array there
+func configList(v any, key string) []any {
+	child, _ := configValue(v, key).([]any)
+	return child
+}
+
+// configString returns m[key] as text when it is a string or number
+func configString(v any, key string) string {
+	switch value := configValue(v, key).(type) {
+	case string:
+		return value
+	case int, int64, float64:
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// configStrings returns a string or an array of strings as a list
+func configStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// keySet returns the keys of a map as a set
+func keySet[V any](m map[string]V) map[string]bool {
+	keys := make(map[string]bool, len(m))
+	for key := range m {
+		keys[key] = true
+	}
+	return keys
+}
+
+// firstSorted returns up to n members of a set in alphabetical order
+func firstSorted(set map[string]bool, n int) []string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values[:min(len(values), n)]
+}
@@ -0,0 +1,132 @@
+package extractor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// extractConfig runs ConfigExtractor on a file held in memory
+func extractConfig(t *testing.T, name, data string) map[string]any {
+	t.Helper()
+	content, err := ConfigExtractor{}.Extract(fstest.MapFS{name: {Data: []byte(data)}}, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content.Details
+}
+
+func TestConfigSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"openapi.yaml", "openapi: 3.0.0\ninfo: {title: Pets}\n", "openapi"},
+		{"api.json", `{"swagger": "2.0"}`, "openapi"},
+		{"stack.yaml", "AWSTemplateFormatVersion: '2010-09-09'\n", "cloudformation"},
+		{"user.schema.json", `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "object"}`, "json-schema"},
+		{"other.json", `{"$schema": "https://example.com/schema"}`, ""},
+		{"deploy.yaml", "apiVersion: apps/v1\nkind: Deployment\n---\napiVersion: v1\nkind: Service\n", "kubernetes"},
+		{"mixed.yaml", "apiVersion: v1\nkind: Service\n---\nfoo: bar\n", ""},
+		{"ci.yml", "on: push\njobs:\n  build: {runs-on: ubuntu-latest}\n", "github-actions"},
+		{"app.yml", "services:\n  web:\n    image: nginx\n", "docker-compose"},
+		{"docker-compose.prod.yml", "version: '3'\n", "docker-compose"},
+		{"pipeline.yml", "stages: [test]\nunit:\n  script: make test\n", "gitlab-ci"},
+		{"site.yml", "- hosts: web\n  roles: [nginx]\n", "ansible"},
+		{"tsconfig.base.json", `{}`, "tsconfig"},
+		{"settings.json", `{"compilerOptions": {}}`, "tsconfig"},
+		{"values-prod.yaml", "replicaCount: 2\n", "helm-values"},
+		{"Chart.yaml", "name: web\n", "helm-chart"},
+		{"deployment.yaml", "kind: Deployment\nreplicas: {{ .Values.replicas }}\n", "helm-template"},
+		{"settings.yaml", "debug: true\n", ""},
+	}
+	for _, tt := range tests {
+		details := extractConfig(t, tt.name, tt.data)
+		if got, _ := details["schema"].(string); got != tt.want {
+			t.Errorf("%s: schema = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigShape(t *testing.T) {
+	details := extractConfig(t, "app.toml", "name = \"app\"\n[server]\nports = [80, 443]\n[[server.routes]]\npath = \"/\"\n")
+	if details["format"] != "toml" || details["depth"] != 4 || details["key_count"] != 2 {
+		t.Errorf("details = %v, want toml, depth 4 and 2 keys", details)
+	}
+	want := map[string]int{"server.ports": 2, "server.routes": 1}
+	if !reflect.DeepEqual(details["array_sizes"], want) {
+		t.Errorf("array_sizes = %v, want %v", details["array_sizes"], want)
+	}
+
+	details = extractConfig(t, "broken.json", `{"a": `)
+	if msg, _ := details["parse_error"].(string); !strings.HasPrefix(msg, "invalid JSON") {
+		t.Errorf("parse_error = %q, want invalid JSON", msg)
+	}
+}
+
+func TestConfigSchemaDetails(t *testing.T) {
+	var resources strings.Builder
+	resources.WriteString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n")
+	for i := range 50 {
+		fmt.Fprintf(&resources, "  - service-%02d.yaml\n", i)
+	}
+	details := extractConfig(t, "kustomization.yaml", resources.String())
+	if details["resource_count"] != 50 || len(details["resources"].([]string)) != maxConfigListItems {
+		t.Errorf("kustomize: resource_count = %v with %d listed, want 50 with %d",
+			details["resource_count"], len(details["resources"].([]string)), maxConfigListItems)
+	}
+
+	details = extractConfig(t, "k8s.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web}
+spec:
+  template:
+    spec:
+      containers:
+        - image: nginx:1.25
+---
+apiVersion: v1
+kind: Service
+metadata: {name: web}
+`)
+	if details["resource_count"] != 2 || !reflect.DeepEqual(details["resources"], []string{"Deployment/web", "Service/web"}) ||
+		!reflect.DeepEqual(details["images"], []string{"nginx:1.25"}) {
+		t.Errorf("kubernetes: details = %v", details)
+	}
+
+	details = extractConfig(t, "build.yml", "name: CI\non:\n  push: {}\n  pull_request: {}\njobs:\n  test:\n    steps: [{run: a}, {run: b}]\n  lint:\n    steps: [{run: c}]\n")
+	if !reflect.DeepEqual(details["triggers"], []string{"pull_request", "push"}) ||
+		!reflect.DeepEqual(details["jobs"], []string{"lint", "test"}) || details["steps"] != 3 {
+		t.Errorf("github-actions: details = %v", details)
+	}
+
+	details = extractConfig(t, "values.yaml", "image:\n  repository: redis\n  tag: \"7\"\nsidecar:\n  image: busybox\n")
+	if !reflect.DeepEqual(details["images"], []string{"busybox", "redis:7"}) {
+		t.Errorf("helm-values: images = %v", details["images"])
+	}
+
+	details = extractConfig(t, "svc.yaml", "{{- if .Values.enabled }}\nkind: Service\n---\nkind: \"Deployment\"\nkind: {{ .Values.kind }}\n{{- end }}\n")
+	if details["resource_count"] != 2 || details["parse_error"] != nil {
+		t.Errorf("helm-template: details = %v", details)
+	}
+}
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"{\"a\": 1, // note\n}", "{\"a\": 1}"},
+		{`{"url": "http://x/*y*/"}`, `{"url": "http://x/*y*/"}`},
+		{`{"a": [1, 2, ], /* gone */ "b": "\"//"}`, `{"a": [1, 2],  "b": "\"//"}`},
+		{`{"a": 1 /* unterminated`, `{"a": 1 `},
+	}
+	for _, tt := range tests {
+		if got := string(stripJSONComments([]byte(tt.in))); got != tt.want {
+			t.Errorf("stripJSONComments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
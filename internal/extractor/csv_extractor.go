@@ -0,0 +1,412 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/csv"
+	"errors"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CSVExtractor sniffs the dialect of a CSV/TSV file, streams it to count
+// rows and profiles each column without loading the file into memory
+type CSVExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+const (
+	csvSniffBytes   = 64 * 1024 // sample used to detect the dialect
+	csvSniffLines   = 20
+	maxProfiledCols = 100
+	distinctSketchK = 256 // smallest hashes kept per column for distinct estimates
+)
+
+// csvDelimiters are the candidate separators with the names reported in details
+var csvDelimiters = []struct {
+	char rune
+	name string
+}{
+	{',', "comma"},
+	{'\t', "tab"},
+	{';', "semicolon"},
+	{'|', "pipe"},
+}
+
+// nullValues are cell values treated as missing when profiling
+var nullValues = map[string]bool{"": true, "na": true, "n/a": true, "null": true, "none": true, "nan": true, "-": true}
+
+// Extract delimited text content
+func (e CSVExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return e.extract(file, name)
+}
+
+// extract sniffs, streams and profiles the delimited text in file
+func (e CSVExtractor) extract(file io.Reader, path string) (*ExtractedContent, error) {
+	br := bufio.NewReaderSize(file, csvSniffBytes)
+	sample, err := br.Peek(csvSniffBytes)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	// A UTF-8 byte order mark would otherwise stick to the first header
+	if bytes.HasPrefix(sample, []byte("\xEF\xBB\xBF")) {
+		br.Discard(3)
+		sample = sample[3:]
+	}
+
+	delimiter, delimiterName := sniffDelimiter(sample, strings.ToLower(filepath.Ext(path)) == ".tsv")
+	hasHeader := csvHasHeader(readCSVSample(sample, delimiter))
+
+	reader := newCSVReader(br, delimiter)
+	reader.ReuseRecord = true
+
+	budget := previewLimit(e.Limit)
+	var (
+		preview   strings.Builder
+		headers   []string
+		profiles  []*columnProfile
+		rows      int
+		maxCols   int
+		malformed int
+	)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				malformed++
+				continue
+			}
+			return nil, err
+		}
+		rows++
+		maxCols = max(maxCols, len(record))
+
+		// Scans show six rows; a larger budget shows rows until it is filled
+		if (e.Limit > 0 || rows <= 6) && preview.Len() < budget {
+			preview.WriteString(strings.Join(record, " | "))
+			preview.WriteString("\n")
+		}
+
+		if rows == 1 && hasHeader {
+			headers = append([]string(nil), record...)
+			continue
+		}
+		for i, cell := range record[:min(len(record), maxProfiledCols)] {
+			for len(profiles) <= i {
+				profiles = append(profiles, newColumnProfile())
+			}
+			profiles[i].add(cell)
+		}
+	}
+
+	dataRows := rows
+	if hasHeader {
+		dataRows--
+	}
+
+	var columns []map[string]any
+	for i, p := range profiles {
+		name := ""
+		if i < len(headers) {
+			name = strings.TrimSpace(headers[i])
+		}
+		columns = append(columns, p.summary(name, dataRows))
+	}
+
+	details := map[string]any{
+		"type":       strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."),
+		"delimiter":  delimiterName,
+		"quoted":     bytes.ContainsRune(sample, '"'),
+		"has_header": hasHeader,
+		"columns":    maxCols,
+		"rows":       dataRows,
+		"profile":    columns,
+	}
+	if hasHeader {
+		details["headers"] = headers
+	}
+	if malformed > 0 {
+		details["malformed_rows"] = malformed
+	}
+
+	return &ExtractedContent{
+		Category: "spreadsheet",
+		Preview:  truncatePreview(preview.String(), budget),
+		Lines:    rows,
+		Details:  details,
+	}, nil
+}
+
+// newCSVReader returns a lenient reader for the sniffed delimiter
+func newCSVReader(r io.Reader, delimiter rune) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader
+}
+
+// readCSVSample parses the first rows of the dialect sample
+func readCSVSample(sample []byte, delimiter rune) [][]string {
+	reader := newCSVReader(bytes.NewReader(sample), delimiter)
+	var rows [][]string
+	for len(rows) < csvSniffLines {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			rows = append(rows, record)
+		}
+	}
+	return rows
+}
+
+// sniffDelimiter picks the candidate separator that appears the same
+// (non-zero) number of times on most sample lines, ignoring quoted text
+func sniffDelimiter(sample []byte, tsv bool) (rune, string) {
+	lines := strings.Split(string(sample), "\n")
+	// The last line of the sample is usually cut short
+	if len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+	lines = lines[:min(len(lines), csvSniffLines)]
+
+	// A .tsv file is tab separated whenever its sample contains a tab
+	if tsv && bytes.ContainsRune(sample, '\t') {
+		return '\t', "tab"
+	}
+
+	best, bestScore := 0, 0
+	for i, d := range csvDelimiters {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			if n := countUnquoted(line, d.char); n > 0 {
+				counts[n]++
+			}
+		}
+		// Score by how many lines agree on the most common count
+		for _, agree := range counts {
+			if agree > bestScore {
+				best, bestScore = i, agree
+			}
+		}
+	}
+	return csvDelimiters[best].char, csvDelimiters[best].name
+}
+
+// countUnquoted counts occurrences of c outside double-quoted fields
+func countUnquoted(line string, c rune) int {
+	n := 0
+	quoted := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == c && !quoted:
+			n++
+		}
+	}
+	return n
+}
+
+// csvHasHeader decides whether the first row names the columns: it must be
+// distinct text, and either some column below it holds non-text values or
+// none of its values recur in the rows below
+func csvHasHeader(rows [][]string) bool {
+	if len(rows) < 2 || detectHeaderRow(rows[:2]) != 0 {
+		return false
+	}
+
+	for _, t := range columnTypes(rows[1:]) {
+		if t != "" && t != typeText {
+			return true
+		}
+	}
+	for col, title := range rows[0] {
+		for _, row := range rows[1:] {
+			if col < len(row) && strings.EqualFold(strings.TrimSpace(row[col]), strings.TrimSpace(title)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// columnProfile accumulates statistics for one column while streaming
+type columnProfile struct {
+	types    map[string]int
+	nulls    int
+	minNum   float64
+	maxNum   float64
+	hasNum   bool
+	minDate  time.Time
+	maxDate  time.Time
+	distinct distinctSketch
+}
+
+func newColumnProfile() *columnProfile {
+	return &columnProfile{
+		types:    make(map[string]int),
+		minNum:   math.Inf(1),
+		maxNum:   math.Inf(-1),
+		distinct: distinctSketch{seen: make(map[uint64]bool)},
+	}
+}
+
+// add records one cell value
+func (p *columnProfile) add(cell string) {
+	value := strings.TrimSpace(cell)
+	if nullValues[strings.ToLower(value)] {
+		p.nulls++
+		return
+	}
+
+	t := valueType(value)
+	p.types[t]++
+	p.distinct.add(value)
+
+	switch t {
+	case typeNumber, typeCurrency, typePercent:
+		if v, ok := parseNumber(numericPart(value)); ok {
+			p.minNum = math.Min(p.minNum, v)
+			p.maxNum = math.Max(p.maxNum, v)
+			p.hasNum = true
+		}
+	case typeDate:
+		if d, ok := parseDate(value); ok {
+			if p.minDate.IsZero() || d.Before(p.minDate) {
+				p.minDate = d
+			}
+			if d.After(p.maxDate) {
+				p.maxDate = d
+			}
+		}
+	}
+}
+
+// summary reports the profile for a column of rows data rows
+func (p *columnProfile) summary(name string, rows int) map[string]any {
+	s := map[string]any{
+		"type":     dominantType(p.types),
+		"distinct": p.distinct.estimate(),
+	}
+	if name != "" {
+		s["name"] = name
+	}
+	if rows > 0 {
+		s["null_ratio"] = math.Round(float64(p.nulls)/float64(rows)*100) / 100
+	}
+
+	switch s["type"] {
+	case typeNumber, typeCurrency, typePercent:
+		if p.hasNum {
+			s["min"] = formatNumber(p.minNum)
+			s["max"] = formatNumber(p.maxNum)
+		}
+	case typeDate:
+		if !p.minDate.IsZero() {
+			s["min"] = p.minDate.Format("2006-01-02")
+			s["max"] = p.maxDate.Format("2006-01-02")
+		}
+	}
+	return s
+}
+
+// numericPart strips currency symbols, spaces and a percent sign
+func numericPart(value string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(currencySymbols, r) || r == ' ' || r == '%' {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// distinctSketch counts distinct values exactly while there are few of
+// them, then switches to a k-minimum-values estimate so memory stays
+// bounded on very large files
+type distinctSketch struct {
+	seen    map[uint64]bool // hashes seen while still exact
+	minimum hashHeap        // the k smallest hashes once estimating
+	inHeap  map[uint64]bool
+}
+
+func (d *distinctSketch) add(value string) {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	sum := h.Sum64()
+
+	if d.seen != nil {
+		d.seen[sum] = true
+		if len(d.seen) <= distinctSketchK*4 {
+			return
+		}
+		// Too many values to keep: seed the sketch with what was seen
+		d.inHeap = make(map[uint64]bool)
+		for s := range d.seen {
+			d.push(s)
+		}
+		d.seen = nil
+		return
+	}
+	d.push(sum)
+}
+
+// push offers a hash to the sketch, keeping only the k smallest
+func (d *distinctSketch) push(sum uint64) {
+	if d.inHeap[sum] {
+		return
+	}
+	if len(d.minimum) < distinctSketchK {
+		heap.Push(&d.minimum, sum)
+		d.inHeap[sum] = true
+		return
+	}
+	if sum < d.minimum[0] {
+		delete(d.inHeap, d.minimum[0])
+		d.minimum[0] = sum
+		heap.Fix(&d.minimum, 0)
+		d.inHeap[sum] = true
+	}
+}
+
+// estimate returns the number of distinct values seen
+func (d *distinctSketch) estimate() int {
+	if d.seen != nil {
+		return len(d.seen)
+	}
+	// With k uniformly distributed hashes, the k-th smallest sits near k/n of the range
+	kth := float64(d.minimum[0]) / math.MaxUint64
+	return int(float64(distinctSketchK-1) / kth)
+}
+
+// hashHeap is a max-heap of hashes, so the largest of the k kept is on top
+type hashHeap []uint64
+
+func (h hashHeap) Len() int           { return len(h) }
+func (h hashHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h hashHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *hashHeap) Push(x any)        { *h = append(*h, x.(uint64)) }
+func (h *hashHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
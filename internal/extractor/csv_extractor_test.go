@@ -0,0 +1,99 @@
+package extractor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample string
+		tsv    bool
+		want   string
+	}{
+		{"comma", "a,b,c\n1,2,3\n4,5,6\n", false, "comma"},
+		{"semicolon with decimal commas", "name;price\nTea;1,50\nCake;12,00\nMilk;0,99\n", false, "semicolon"},
+		{"tab", "a\tb\n1\t2\n3\t4\n", false, "tab"},
+		{"pipe", "id|name|city\n1|Ada|London\n2|Alan|Wilmslow\n", false, "pipe"},
+		{"quoted commas ignored", "name;note\n\"Smith, J\";\"a, b, c\"\n\"Doe, A\";x\n", false, "semicolon"},
+		{"cut last line ignored", "a;b\n1;2\n3;4\n5,6,7,8,9,", false, "semicolon"},
+		{"tsv extension wins", "a,b\tc\n1,2\t3\n", true, "tab"},
+		{"tsv without tabs is sniffed", "a,b\n1,2\n", true, "comma"},
+		{"no delimiter", "just one column\nof text\n", false, "comma"},
+		{"empty", "", false, "comma"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := sniffDelimiter([]byte(tt.sample), tt.tsv); got != tt.want {
+				t.Errorf("sniffDelimiter(%q) = %s, want %s", tt.sample, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVHasHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want bool
+	}{
+		{"text over numbers", [][]string{{"name", "age"}, {"Ada", "36"}, {"Alan", "41"}}, true},
+		{"numbers only", [][]string{{"1", "2"}, {"3", "4"}}, false},
+		{"distinct text", [][]string{{"city", "country"}, {"Lagos", "Nigeria"}, {"Accra", "Ghana"}}, true},
+		{"first row recurs", [][]string{{"red", "small"}, {"blue", "large"}, {"red", "large"}}, false},
+		{"single row", [][]string{{"name", "age"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvHasHeader(tt.rows); got != tt.want {
+				t.Errorf("csvHasHeader(%v) = %v, want %v", tt.rows, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVExtract(t *testing.T) {
+	data := "\xEF\xBB\xBFname;amount;paid\n" +
+		"\"Smith; J\";10,5;2024-01-02\n" +
+		"Doe;7;2024-02-03\n" +
+		"Roe;;2024-03-04\n"
+
+	content, err := CSVExtractor{}.extract(strings.NewReader(data), "payments.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details := content.Details
+	if details["delimiter"] != "semicolon" {
+		t.Errorf("delimiter = %v, want semicolon", details["delimiter"])
+	}
+	if details["has_header"] != true {
+		t.Errorf("has_header = %v, want true", details["has_header"])
+	}
+	// The byte order mark must not stick to the first header
+	if got, want := details["headers"], []string{"name", "amount", "paid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("headers = %q, want %q", got, want)
+	}
+	if details["rows"] != 3 || details["columns"] != 3 {
+		t.Errorf("rows, columns = %v, %v, want 3, 3", details["rows"], details["columns"])
+	}
+	if content.Lines != 4 {
+		t.Errorf("Lines = %d, want 4", content.Lines)
+	}
+	if !strings.Contains(content.Preview, "Smith; J | 10,5 | 2024-01-02") {
+		t.Errorf("preview does not keep the quoted field:\n%s", content.Preview)
+	}
+
+	profile, _ := details["profile"].([]map[string]any)
+	if len(profile) != 3 {
+		t.Fatalf("profile has %d columns, want 3", len(profile))
+	}
+	if profile[1]["name"] != "amount" || profile[1]["null_ratio"] != 0.33 {
+		t.Errorf("amount profile = %v, want name amount and null_ratio 0.33", profile[1])
+	}
+	if profile[2]["type"] != typeDate || profile[2]["min"] != "2024-01-02" || profile[2]["max"] != "2024-03-04" {
+		t.Errorf("paid profile = %v, want dates 2024-01-02 to 2024-03-04", profile[2])
+	}
+}
@@ -1,5 +1,10 @@
 package extractor
 
+import (
+	"path"
+	"strings"
+)
+
 // DetectCategory determines the appropriate extractor for a file
 // based on its extension.
 //
@@ -13,33 +18,109 @@ package extractor
 //   - Extractor: Appropriate extractor implementation for the file type
 //
 // File categories:
-//   - Code: .go, .dart, .js, .ts, .py, .java, .rb, .rs, .c, .cpp
+//   - Code: .go, .dart, .js, .jsx, .mjs, .cjs, .ts, .tsx, .py, .java, .rb,
+//     .rs, .c, .h, .cpp, .cc, .hpp, .php, .sh, .kt, .cs, .swift
 //   - PDF: .pdf
-//   - Word: .docx, .doc
-//   - Excel: .xlsx, .xls
+//   - Word: .docx, .doc (Word 97-2003)
+//   - Excel: .xlsx, .xls (BIFF8)
+//   - PowerPoint: .pptx
+//   - E-book: .epub
+//   - OpenDocument: .odt, .ods, .odp
+//   - Delimited: .csv, .tsv
+//   - Notebook: .ipynb
 //   - Text: .md, .txt
-//   - Structured: .json, .yaml, .xml, .csv, etc.
-//   - Binary: Images, audio, video, or unknown formats
+//   - Web: .html, .htm, .xhtml
+//   - XML: .xml, .svg, .rss, .atom
+//   - Config: .json, .yaml, .yml, .toml
+//   - Text-like: .env, .cmake, etc.
+//   - Image: .png, .jpg, .gif, .webp, .tiff, .heic
+//   - Media: .mp3, .flac, .ogg, .opus, .wav, .m4a, .mp4, .mov
+//   - Archive: .zip, .tar, .tar.gz/.tgz, .gz, .7z
+//   - Binary: Unknown formats
 func DetectCategory(ext string) Extractor {
+	return DetectCategoryWithLimit(ext, 0)
+}
+
+// DetectExtractor picks the extractor for a file by its name: package
+// manifests such as go.mod, Gemfile or package.json are recognised before
+// the extension is considered, and files without an extension are checked
+// for a shebang line.
+//
+// Parameters:
+//   - name: File name or slash-separated path
+//   - limit: Maximum preview size in bytes (0 = DefaultPreviewLimit)
+//
+// Returns:
+//   - Extractor: Appropriate extractor implementation for the file
+func DetectExtractor(name string, limit int) Extractor {
+	if IsManifestFile(name) {
+		return ManifestExtractor{Limit: limit}
+	}
+	if path.Ext(name) == "" {
+		return ScriptExtractor{Limit: limit}
+	}
+	return DetectCategoryWithLimit(strings.ToLower(path.Ext(name)), limit)
+}
+
+// DetectCategoryWithLimit is DetectCategory with a custom preview budget,
+// used when a single file is examined in depth (e.g. by "explain").
+//
+// Parameters:
+//   - ext: File extension including the dot
+//   - limit: Maximum preview size in bytes (0 = DefaultPreviewLimit)
+//
+// Returns:
+//   - Extractor: Appropriate extractor implementation for the file type
+func DetectCategoryWithLimit(ext string, limit int) Extractor {
 	switch ext {
-	case ".go", ".dart", ".js", ".ts", ".py", ".java", ".rb", ".rs", ".c", ".cpp":
-		return CodeExtractor{}
+	case ".go", ".dart", ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts",
+		".py", ".pyw", ".java", ".rb", ".rs", ".c", ".h", ".cpp", ".cc", ".cxx",
+		".hpp", ".hh", ".hxx", ".php", ".sh", ".bash", ".kt", ".kts", ".cs", ".swift":
+		return CodeExtractor{Limit: limit}
 	case ".pdf":
-		return PDFExtractor{}
-	case ".docx", ".doc":
-		return DocxExtractor{}
-	case ".xlsx", ".xls":
-		return ExcelExtractor{}
+		return PDFExtractor{Limit: limit}
+	case ".docx":
+		return DocxExtractor{Limit: limit}
+	case ".doc":
+		return DocExtractor{Limit: limit}
+	case ".xlsx":
+		return ExcelExtractor{Limit: limit}
+	case ".xls":
+		return XLSExtractor{Limit: limit}
+	case ".pptx":
+		return PPTXExtractor{Limit: limit}
+	case ".epub":
+		return EPUBExtractor{Limit: limit}
+	case ".odt":
+		return ODTExtractor{Limit: limit}
+	case ".ods":
+		return ODSExtractor{Limit: limit}
+	case ".odp":
+		return ODPExtractor{Limit: limit}
+	case ".csv", ".tsv":
+		return CSVExtractor{Limit: limit}
+	case ".ipynb":
+		return NotebookExtractor{Limit: limit}
 	case ".md", ".txt":
-		return MarkdownExtractor{}
-	case ".json", ".yaml", ".yml", ".toml", ".env", ".xml", ".csv", ".cmake":
-		return GenericTextExtractor{}
-	case ".png", ".jpg", ".jpeg", ".mp3", ".mp4":
-		return BinaryExtractor{}
+		return MarkdownExtractor{Limit: limit}
+	case ".html", ".htm", ".xhtml":
+		return HTMLExtractor{Limit: limit}
+	case ".xml", ".svg", ".rss", ".atom":
+		return XMLExtractor{Limit: limit}
+	case ".json", ".yaml", ".yml", ".toml":
+		return ConfigExtractor{Limit: limit}
+	case ".env", ".cmake":
+		return GenericTextExtractor{Limit: limit}
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".tif", ".tiff", ".heic", ".heif":
+		return ImageExtractor{}
+	case ".zip", ".tar", ".tgz", ".gz", ".7z":
+		return ArchiveExtractor{Limit: limit, ExtractInner: limit > 0}
+	case ".mp3", ".flac", ".ogg", ".oga", ".opus", ".wav", ".m4a", ".mp4", ".m4v", ".mov":
+		return MediaExtractor{}
 	default:
 		// Fallback: Check if it's a text file by content
 		if IsTextFile(ext) {
-			return GenericTextExtractor{}
+			return GenericTextExtractor{Limit: limit}
 		}
 		return BinaryExtractor{}
 	}
@@ -0,0 +1,202 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// DocExtractor extracts text and summary metadata from a legacy
+// Word 97-2003 (.doc) document
+type DocExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// Word binary format constants ([MS-DOC] 2.5 File Information Block)
+const (
+	wordIdent        = 0xA5EC
+	fibFlagEncrypted = 0x0100
+	fibFlagTable1    = 0x0200
+	fibClxIndex      = 33 // fcClx/lcbClx pair within FibRgFcLcb97
+	fibCcpTextIndex  = 3  // ccpText within FibRgLw97
+)
+
+// Extract word document content
+func (e DocExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	streams, details, err := readOLEStreams(fsys, name, "WordDocument", "0Table", "1Table")
+	if err != nil {
+		return nil, err
+	}
+	details["type"] = "doc"
+
+	wordDoc := streams["WordDocument"]
+	if len(wordDoc) < 0x200 || binary.LittleEndian.Uint16(wordDoc) != wordIdent {
+		return nil, fmt.Errorf("not a Word 97-2003 document")
+	}
+
+	flags := binary.LittleEndian.Uint16(wordDoc[0x0A:])
+	if flags&fibFlagEncrypted != 0 {
+		details["encrypted"] = true
+		return &ExtractedContent{Category: "document", Details: details}, nil
+	}
+
+	table := streams["0Table"]
+	if flags&fibFlagTable1 != 0 {
+		table = streams["1Table"]
+	}
+
+	text, err := wordText(wordDoc, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var paragraphs []string
+	for line := range strings.SplitSeq(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+
+	words := 0
+	for _, p := range paragraphs {
+		words += len(strings.Fields(p))
+	}
+	details["word_count"] = words
+
+	return &ExtractedContent{
+		Category: "document",
+		Preview:  truncatePreview(strings.Join(paragraphs, "\n"), previewLimit(e.Limit)),
+		Lines:    len(paragraphs),
+		Details:  details,
+	}, nil
+}
+
+// wordText reassembles the main document text from the piece table (CLX)
+// stored in the table stream. Each piece maps a range of character
+// positions to either 8-bit (Windows-1252) or UTF-16 text in WordDocument.
+func wordText(wordDoc, table []byte) (string, error) {
+	// Locate FibRgLw97 and FibRgFcLcb97 using the counts stored in the FIB
+	csw := int(binary.LittleEndian.Uint16(wordDoc[32:]))
+	lwStart := 32 + 2 + csw*2 + 2
+	if lwStart+4*(fibCcpTextIndex+1) > len(wordDoc) {
+		return "", fmt.Errorf("truncated Word file information block")
+	}
+	cslw := int(binary.LittleEndian.Uint16(wordDoc[lwStart-2:]))
+	ccpText := int(binary.LittleEndian.Uint32(wordDoc[lwStart+4*fibCcpTextIndex:]))
+
+	fcLcbStart := lwStart + cslw*4 + 2
+	if fcLcbStart+8*(fibClxIndex+1) > len(wordDoc) {
+		return "", fmt.Errorf("truncated Word file information block")
+	}
+	fcClx := int(binary.LittleEndian.Uint32(wordDoc[fcLcbStart+8*fibClxIndex:]))
+	lcbClx := int(binary.LittleEndian.Uint32(wordDoc[fcLcbStart+8*fibClxIndex+4:]))
+	if table == nil || fcClx+lcbClx > len(table) || lcbClx == 0 {
+		return "", fmt.Errorf("piece table missing from Word document")
+	}
+	clx := table[fcClx : fcClx+lcbClx]
+
+	// Skip Prc entries (formatting) until the Pcdt holding the piece table
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 {
+		if pos+3 > len(clx) {
+			return "", fmt.Errorf("malformed Word piece table")
+		}
+		pos += 3 + int(binary.LittleEndian.Uint16(clx[pos+1:]))
+	}
+	if pos+5 > len(clx) || clx[pos] != 0x02 {
+		return "", fmt.Errorf("malformed Word piece table")
+	}
+	lcb := int(binary.LittleEndian.Uint32(clx[pos+1:]))
+	plc := clx[pos+5:]
+	if lcb > len(plc) || lcb < 4 {
+		return "", fmt.Errorf("malformed Word piece table")
+	}
+	plc = plc[:lcb]
+
+	// PlcPcd: (n+1) character positions followed by n 8-byte piece descriptors
+	n := (len(plc) - 4) / 12
+	pcds := plc[(n+1)*4:]
+
+	var raw []rune
+	for i := range n {
+		cpStart := int(binary.LittleEndian.Uint32(plc[i*4:]))
+		cpEnd := int(binary.LittleEndian.Uint32(plc[(i+1)*4:]))
+		// Only the main document; footnotes, headers etc. follow ccpText
+		if cpStart >= ccpText {
+			break
+		}
+		count := min(cpEnd, ccpText) - cpStart
+		if count <= 0 {
+			continue
+		}
+
+		fc := binary.LittleEndian.Uint32(pcds[i*8+2:])
+		if fc&0x40000000 != 0 {
+			offset := int(fc&0x3FFFFFFF) / 2
+			if offset+count > len(wordDoc) {
+				break
+			}
+			raw = append(raw, []rune(decodeWindows1252(wordDoc[offset:offset+count]))...)
+		} else {
+			offset := int(fc)
+			if offset+count*2 > len(wordDoc) {
+				break
+			}
+			units := make([]uint16, count)
+			for j := range units {
+				units[j] = binary.LittleEndian.Uint16(wordDoc[offset+j*2:])
+			}
+			raw = append(raw, utf16.Decode(units)...)
+		}
+	}
+
+	return cleanWordText(raw), nil
+}
+
+// cleanWordText maps Word's control characters to plain text: paragraph,
+// cell and page marks become line breaks, field codes are dropped in favour
+// of their displayed result, and object anchors are removed.
+func cleanWordText(raw []rune) string {
+	var b strings.Builder
+	// One entry per open field, true while its instruction text is read;
+	// a field nested in another's instruction text is hidden with it
+	var inFieldCode []bool
+	for _, r := range raw {
+		switch r {
+		case 0x13: // field begin: instruction text follows
+			inFieldCode = append(inFieldCode, true)
+		case 0x14: // field separator: displayed result follows
+			if len(inFieldCode) > 0 {
+				inFieldCode[len(inFieldCode)-1] = false
+			}
+		case 0x15: // field end
+			if len(inFieldCode) > 0 {
+				inFieldCode = inFieldCode[:len(inFieldCode)-1]
+			}
+		default:
+			if slices.Contains(inFieldCode, true) {
+				continue
+			}
+			switch r {
+			case 0x0D, 0x0B, 0x0C, 0x07:
+				b.WriteByte('\n')
+			case 0x09:
+				b.WriteByte('\t')
+			case 0x1E:
+				b.WriteByte('-')
+			case 0xA0:
+				b.WriteByte(' ')
+			case 0x01, 0x08, 0x1F:
+				// Picture and drawing anchors, optional hyphens
+			default:
+				if r >= 0x20 {
+					b.WriteRune(r)
+				}
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// wordPiece is one piece of document text for buildWordDoc
+type wordPiece struct {
+	text       string
+	compressed bool // stored as 8-bit Windows-1252 instead of UTF-16
+}
+
+// buildWordDoc lays out a WordDocument stream with a minimal FIB and the
+// given text pieces, and a table stream holding a CLX with one Prc entry
+// followed by the piece table
+func buildWordDoc(pieces []wordPiece) (wordDoc, table []byte) {
+	const (
+		csw       = 14
+		cslw      = 22
+		cbRgFcLcb = 93
+	)
+	lwStart := 32 + 2 + csw*2 + 2
+	fcLcbStart := lwStart + cslw*4 + 2
+	textStart := fcLcbStart + cbRgFcLcb*8
+
+	wordDoc = make([]byte, textStart)
+	binary.LittleEndian.PutUint16(wordDoc, wordIdent)
+	binary.LittleEndian.PutUint16(wordDoc[32:], csw)
+	binary.LittleEndian.PutUint16(wordDoc[lwStart-2:], cslw)
+	binary.LittleEndian.PutUint16(wordDoc[fcLcbStart-2:], cbRgFcLcb)
+
+	var cps []uint32
+	var pcds []byte
+	cp := 0
+	for _, p := range pieces {
+		cps = append(cps, uint32(cp))
+		pcd := make([]byte, 8)
+		if p.compressed {
+			binary.LittleEndian.PutUint32(pcd[2:], uint32(len(wordDoc)*2)|0x40000000)
+			wordDoc = append(wordDoc, p.text...)
+			cp += len(p.text)
+		} else {
+			binary.LittleEndian.PutUint32(pcd[2:], uint32(len(wordDoc)))
+			units := utf16.Encode([]rune(p.text))
+			for _, u := range units {
+				wordDoc = binary.LittleEndian.AppendUint16(wordDoc, u)
+			}
+			cp += len(units)
+		}
+		pcds = append(pcds, pcd...)
+	}
+	cps = append(cps, uint32(cp))
+	binary.LittleEndian.PutUint32(wordDoc[lwStart+4*fibCcpTextIndex:], uint32(cp))
+
+	var plc []byte
+	for _, c := range cps {
+		plc = binary.LittleEndian.AppendUint32(plc, c)
+	}
+	plc = append(plc, pcds...)
+
+	// A Prc (formatting) entry the parser must skip, then the Pcdt
+	table = []byte{0x01, 0x02, 0x00, 0xAA, 0xBB, 0x02}
+	table = binary.LittleEndian.AppendUint32(table, uint32(len(plc)))
+	table = append(table, plc...)
+
+	binary.LittleEndian.PutUint32(wordDoc[fcLcbStart+8*fibClxIndex:], 0)
+	binary.LittleEndian.PutUint32(wordDoc[fcLcbStart+8*fibClxIndex+4:], uint32(len(table)))
+	return wordDoc, table
+}
+
+// pieceTableLength returns the offset of lcbClx in a stream from buildWordDoc
+func pieceTableLength(wordDoc []byte) int {
+	csw := int(binary.LittleEndian.Uint16(wordDoc[32:]))
+	lwStart := 32 + 2 + csw*2 + 2
+	cslw := int(binary.LittleEndian.Uint16(wordDoc[lwStart-2:]))
+	return lwStart + cslw*4 + 2 + 8*fibClxIndex + 4
+}
+
+func TestWordText(t *testing.T) {
+	wordDoc, table := buildWordDoc([]wordPiece{
+		{text: "Caf\xE9 \x93menu\x94\r", compressed: true},
+		{text: "Total: \x13 PAGE \x145\x15 pages\rNaïve ☕\r"},
+	})
+	got, err := wordText(wordDoc, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Café “menu”\nTotal: 5 pages\nNaïve ☕\n"; got != want {
+		t.Errorf("wordText = %q, want %q", got, want)
+	}
+}
+
+func TestWordTextTruncated(t *testing.T) {
+	wordDoc, table := buildWordDoc([]wordPiece{{text: "Hello\r"}, {text: "world\r", compressed: true}})
+
+	// Cutting either stream anywhere must give an error or partial text, never a panic
+	for n := 34; n < len(wordDoc); n++ {
+		wordText(wordDoc[:n], table)
+	}
+	for n := range len(table) {
+		if _, err := wordText(wordDoc, table[:n]); err == nil && n < 6 {
+			t.Errorf("table cut to %d bytes: want an error", n)
+		}
+	}
+	if _, err := wordText(wordDoc, nil); err == nil {
+		t.Error("missing table stream: want an error")
+	}
+
+	// A Pcdt whose piece table is too short for even one character position
+	for lcb := range 4 {
+		short := append([]byte{0x02}, binary.LittleEndian.AppendUint32(nil, uint32(lcb))...)
+		short = append(short, make([]byte, lcb)...)
+		binary.LittleEndian.PutUint32(wordDoc[pieceTableLength(wordDoc):], uint32(len(short)))
+		if _, err := wordText(wordDoc, short); err == nil {
+			t.Errorf("piece table of %d bytes: want an error", lcb)
+		}
+	}
+}
+
+func TestCleanWordText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"a\rb\vc\fd\x07", "a\nb\nc\nd\n"},
+		{"non breaking\x1Ehyphen\x1Fsoft", "non breaking-hyphensoft"},
+		{"\x13 HYPERLINK \"x\" \x14link\x15 text", "link text"},
+		// Nested field: the outer result still shows the inner result
+		{"\x13 IF \x13 PAGE \x141\x15 \x14one\x15", "one"},
+		{"\x13 unterminated", ""},
+		{"picture\x01\x08", "picture"},
+	}
+	for _, tt := range tests {
+		if got := cleanWordText([]rune(tt.raw)); got != tt.want {
+			t.Errorf("cleanWordText(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
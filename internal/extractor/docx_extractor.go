@@ -3,59 +3,311 @@ package extractor
 import (
 	"archive/zip"
 	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 // DocxExtractor extracts contents from a word document
-type DocxExtractor struct{}
+type DocxExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// maxTablePreviewRows bounds how many rows of the first table are reported
+const maxTablePreviewRows = 5
+
+// headingStyleRe matches built-in heading style names ("heading 1") and ids ("Heading1")
+var headingStyleRe = regexp.MustCompile(`(?i)^heading\s*([1-9])$`)
+
+// docxCoreProperties is the subset of docProps/core.xml Scout reads
+type docxCoreProperties struct {
+	Title          string `xml:"title"`
+	Subject        string `xml:"subject"`
+	Creator        string `xml:"creator"`
+	LastModifiedBy string `xml:"lastModifiedBy"`
+	Created        string `xml:"created"`
+	Modified       string `xml:"modified"`
+}
+
+// docxStyles is the subset of word/styles.xml needed to recognise headings
+// when a document uses localized style ids (e.g. "Titre1" in French Word)
+type docxStyles struct {
+	Styles []struct {
+		ID   string `xml:"styleId,attr"`
+		Name struct {
+			Val string `xml:"val,attr"`
+		} `xml:"name"`
+	} `xml:"style"`
+}
+
+// docxComments counts the entries of word/comments.xml
+type docxComments struct {
+	Comments []struct{} `xml:"comment"`
+}
 
 // Extract word document content
-func (e DocxExtractor) Extract(path string) (*ExtractedContent, error) {
-	r, err := zip.OpenReader(path)
+func (e DocxExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	r, closer, err := openZip(fsys, name)
 	if err != nil {
 		return nil, err
 	}
-	defer r.Close()
-
-	var content strings.Builder
+	defer closer.Close()
 
-	// Find the main content XML inside the zip
+	files := make(map[string]*zip.File, len(r.File))
 	for _, f := range r.File {
-		if f.Name == "word/document.xml" {
-			rc, err := f.Open()
-			if err != nil {
-				return nil, err
+		files[f.Name] = f
+	}
+
+	body := files["word/document.xml"]
+	if body == nil {
+		return nil, fmt.Errorf("word/document.xml missing from docx file")
+	}
+
+	details := map[string]any{"type": "docx"}
+
+	var core docxCoreProperties
+	if err := decodeZipXML(files["docProps/core.xml"], &core); err == nil {
+		for key, value := range map[string]string{
+			"title":       core.Title,
+			"subject":     core.Subject,
+			"author":      core.Creator,
+			"last_author": core.LastModifiedBy,
+			"created":     core.Created,
+			"modified":    core.Modified,
+		} {
+			if value = strings.TrimSpace(value); value != "" {
+				details[key] = value
 			}
+		}
+	}
 
-			// Simple XML parsing to just grab text content
-			decoder := xml.NewDecoder(rc)
-			for {
-				t, _ := decoder.Token()
-				if t == nil {
-					break
-				}
-				switch se := t.(type) {
-				case xml.CharData:
-					content.Write(se)
-					content.WriteString(" ")
-				}
+	// Map style ids to heading levels ("Title" counts as level 0)
+	headingLevels := make(map[string]int)
+	var styles docxStyles
+	if err := decodeZipXML(files["word/styles.xml"], &styles); err == nil {
+		for _, s := range styles.Styles {
+			if level := headingLevel(s.Name.Val); level >= 0 {
+				headingLevels[s.ID] = level
 			}
-			rc.Close()
-			break
 		}
 	}
 
-	text := content.String()
-	// Clean up XML noise if necessary, or just truncate
-	if len(text) > 1000 {
-		text = text[:1000] + "..."
+	var comments docxComments
+	if err := decodeZipXML(files["word/comments.xml"], &comments); err == nil {
+		details["comment_count"] = len(comments.Comments)
+	}
+
+	doc, err := parseDocxBody(body, headingLevels)
+	if err != nil {
+		return nil, err
+	}
+
+	details["headings"] = doc.headings
+	details["table_count"] = doc.tables
+	if len(doc.tablePreview) > 0 {
+		details["table_preview"] = doc.tablePreview
+	}
+	details["tracked_insertions"] = doc.insertions
+	details["tracked_deletions"] = doc.deletions
+	details["word_count"] = doc.words
+	if _, ok := details["title"]; !ok {
+		switch {
+		case doc.title != "":
+			details["title"] = doc.title
+		case len(doc.headings) > 0:
+			details["title"] = strings.TrimSpace(doc.headings[0])
+		}
 	}
 
 	return &ExtractedContent{
 		Category: "document",
-		Preview:  text,
-		Details: map[string]any{
-			"type": "docx",
-		},
+		Preview:  truncatePreview(strings.Join(doc.blocks, "\n"), previewLimit(e.Limit)),
+		Lines:    len(doc.blocks),
+		Details:  details,
 	}, nil
 }
+
+// docxBody is the structure read from word/document.xml
+type docxBody struct {
+	blocks       []string // paragraphs, with table rows rendered as "a | b | c"
+	headings     []string // Heading 1-3 paragraphs, indented by level
+	title        string   // first paragraph styled "Title"
+	tables       int
+	tablePreview []string // first rows of the first table
+	insertions   int
+	deletions    int
+	words        int
+}
+
+// docxParagraph is a paragraph being read; text box paragraphs nest
+// inside the paragraph that anchors the text box
+type docxParagraph struct {
+	text  strings.Builder
+	level int // heading level from the paragraph style, -1 for body text
+}
+
+// parseDocxBody walks document.xml, tracking paragraph styles, tables and
+// revision marks. Deleted text (<w:delText>) is ignored, so the preview
+// shows the document as it currently reads. Of mc:AlternateContent only
+// the preferred Choice is read; its Fallback repeats the same text.
+func parseDocxBody(f *zip.File, headingLevels map[string]int) (*docxBody, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var (
+		doc        docxBody
+		paras      []*docxParagraph // open paragraphs, innermost last
+		inText     bool
+		propsDepth int // inside run, paragraph or table properties
+		tableDepth int
+		row        []string
+		rows       [][]string
+	)
+	// current returns the innermost open paragraph, or nil between paragraphs
+	current := func() *docxParagraph {
+		if len(paras) == 0 {
+			return nil
+		}
+		return paras[len(paras)-1]
+	}
+
+	decoder := xml.NewDecoder(rc)
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch el := t.(type) {
+		case xml.StartElement:
+			if strings.HasSuffix(el.Name.Local, "Pr") {
+				propsDepth++
+			}
+			para := current()
+			switch el.Name.Local {
+			case "Fallback":
+				decoder.Skip()
+			case "p":
+				paras = append(paras, &docxParagraph{level: -1})
+			case "pStyle":
+				if para == nil {
+					break
+				}
+				if l, ok := headingLevels[attr(el, "val")]; ok {
+					para.level = l
+				} else {
+					para.level = headingLevel(attr(el, "val"))
+				}
+			case "outlineLvl":
+				// Direct outline levels are zero-based
+				if l, err := strconv.Atoi(attr(el, "val")); err == nil && para != nil && para.level < 0 {
+					para.level = l + 1
+				}
+			case "t":
+				inText = true
+			case "tab", "br", "cr":
+				if para != nil && propsDepth == 0 {
+					para.text.WriteString(" ")
+				}
+			case "tbl":
+				tableDepth++
+				if tableDepth == 1 {
+					doc.tables++
+					rows = nil
+				}
+			case "tr":
+				if tableDepth == 1 {
+					row = nil
+				}
+			case "tc":
+				if tableDepth == 1 {
+					row = append(row, "")
+				}
+			case "ins":
+				// Revision marks inside properties track formatting or
+				// paragraph marks, not inserted or deleted text
+				if propsDepth == 0 {
+					doc.insertions++
+				}
+			case "del":
+				if propsDepth == 0 {
+					doc.deletions++
+				}
+			}
+		case xml.CharData:
+			if para := current(); inText && para != nil {
+				para.text.Write(el)
+			}
+		case xml.EndElement:
+			if strings.HasSuffix(el.Name.Local, "Pr") {
+				propsDepth--
+			}
+			switch el.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				para := current()
+				if para == nil {
+					continue
+				}
+				paras = paras[:len(paras)-1]
+				level := para.level
+				text := strings.Join(strings.Fields(para.text.String()), " ")
+				if text == "" {
+					continue
+				}
+				doc.words += len(strings.Fields(text))
+
+				if tableDepth > 0 {
+					// Cell paragraphs are joined into the cell text
+					if n := len(row); tableDepth == 1 && n > 0 {
+						row[n-1] = strings.TrimSpace(row[n-1] + " " + text)
+					}
+					continue
+				}
+
+				doc.blocks = append(doc.blocks, text)
+				switch {
+				case level == 0 && doc.title == "":
+					doc.title = text
+				case level >= 1 && level <= 3:
+					doc.headings = append(doc.headings, strings.Repeat("  ", level-1)+text)
+				}
+			case "tr":
+				if tableDepth == 1 {
+					rows = append(rows, row)
+					doc.blocks = append(doc.blocks, strings.Join(row, " | "))
+				}
+			case "tbl":
+				if tableDepth == 1 && doc.tables == 1 {
+					for i, r := range rows {
+						if i >= maxTablePreviewRows {
+							break
+						}
+						doc.tablePreview = append(doc.tablePreview, strings.Join(r, " | "))
+					}
+				}
+				tableDepth--
+			}
+		}
+	}
+
+	return &doc, nil
+}
+
+// headingLevel returns the level of a heading style name or id
+// (1 for "heading 1"), 0 for "Title" and -1 for anything else
+func headingLevel(style string) int {
+	if strings.EqualFold(style, "title") {
+		return 0
+	}
+	if m := headingStyleRe.FindStringSubmatch(style); m != nil {
+		level, _ := strconv.Atoi(m[1])
+		return level
+	}
+	return -1
+}
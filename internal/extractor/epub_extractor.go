@@ -0,0 +1,247 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// maxTOCEntries bounds how many table-of-contents entries are reported
+const maxTOCEntries = 40
+
+// EPUBExtractor extracts package metadata, the table of contents and a
+// text preview of the first chapters from an EPUB e-book
+type EPUBExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// epubContainer is META-INF/container.xml, which points at the OPF package
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the subset of the OPF package document Scout reads
+type epubPackage struct {
+	Metadata struct {
+		Titles     []string `xml:"title"`
+		Creators   []string `xml:"creator"`
+		Languages  []string `xml:"language"`
+		Publishers []string `xml:"publisher"`
+		Dates      []string `xml:"date"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		TOC      string `xml:"toc,attr"`
+		Itemrefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// Extract e-book content
+func (e EPUBExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	r, closer, err := openZip(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+
+	var container epubContainer
+	if err := decodeZipXML(files["META-INF/container.xml"], &container); err != nil {
+		return nil, fmt.Errorf("reading EPUB container: %w", err)
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("EPUB container lists no package document")
+	}
+
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := decodeZipXML(files[opfPath], &pkg); err != nil {
+		return nil, fmt.Errorf("reading EPUB package: %w", err)
+	}
+	// Manifest hrefs are relative to the OPF file
+	base := path.Dir(opfPath)
+	resolve := func(href string) string {
+		return path.Clean(path.Join(base, href))
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	var navHref, ncxHref string
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = resolve(item.Href)
+		if strings.Contains(item.Properties, "nav") {
+			navHref = resolve(item.Href)
+		}
+		if item.MediaType == "application/x-dtbncx+xml" || item.ID == pkg.Spine.TOC {
+			ncxHref = resolve(item.Href)
+		}
+	}
+
+	// EPUB 3 navigation document first, EPUB 2 NCX as fallback
+	var toc []string
+	if navHref != "" {
+		toc = readTOC(files[navHref], "a", true)
+	}
+	if len(toc) == 0 && ncxHref != "" {
+		toc = readTOC(files[ncxHref], "text", false)
+	}
+	if len(toc) > maxTOCEntries {
+		toc = toc[:maxTOCEntries]
+	}
+
+	// Preview the first chapters in reading order until the budget is filled
+	budget := previewLimit(e.Limit)
+	var preview strings.Builder
+	for _, ref := range pkg.Spine.Itemrefs {
+		if preview.Len() >= budget {
+			break
+		}
+		href := hrefs[ref.IDRef]
+		if href == navHref {
+			continue
+		}
+		if text := readXHTMLText(files[href]); text != "" {
+			preview.WriteString(text)
+			preview.WriteString("\n\n")
+		}
+	}
+
+	details := map[string]any{
+		"type":          "epub",
+		"chapter_count": len(pkg.Spine.Itemrefs),
+		"toc":           toc,
+	}
+	meta := pkg.Metadata
+	for key, values := range map[string][]string{
+		"title":     meta.Titles,
+		"language":  meta.Languages,
+		"publisher": meta.Publishers,
+		"date":      meta.Dates,
+	} {
+		if len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+			details[key] = strings.TrimSpace(values[0])
+		}
+	}
+	if len(meta.Creators) > 0 {
+		details["author"] = strings.Join(meta.Creators, ", ")
+	}
+
+	return &ExtractedContent{
+		Category: "document",
+		Preview:  truncatePreview(strings.TrimSpace(preview.String()), budget),
+		Details:  details,
+	}, nil
+}
+
+// decodeZipXML unmarshals an XML file from the archive into v
+func decodeZipXML(f *zip.File, v any) error {
+	if f == nil {
+		return fmt.Errorf("file missing from archive")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// readTOC collects the text of every element named entry (e.g. <a> in a nav
+// document or <text> in an NCX), which are the table-of-contents labels.
+// With navTOC set, only entries inside <nav epub:type="toc"> count, since a
+// navigation document also holds landmarks and page lists.
+func readTOC(f *zip.File, entry string, navTOC bool) []string {
+	if f == nil {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var (
+		toc      []string
+		depth    int
+		navDepth int // nesting of <nav> elements inside the toc nav
+		label    strings.Builder
+	)
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch el := t.(type) {
+		case xml.StartElement:
+			if navTOC && el.Name.Local == "nav" && (navDepth > 0 || isTOCNav(el)) {
+				navDepth++
+			}
+			if el.Name.Local == entry && (!navTOC || navDepth > 0) {
+				depth++
+				label.Reset()
+			}
+		case xml.CharData:
+			if depth > 0 {
+				label.Write(el)
+			}
+		case xml.EndElement:
+			if el.Name.Local == "nav" && navDepth > 0 {
+				navDepth--
+			}
+			if el.Name.Local == entry && depth > 0 {
+				depth--
+				if text := strings.Join(strings.Fields(label.String()), " "); text != "" {
+					toc = append(toc, text)
+				}
+			}
+		}
+	}
+	return toc
+}
+
+// isTOCNav reports whether a <nav> element is the table of contents, i.e.
+// its epub:type lists "toc"
+func isTOCNav(el xml.StartElement) bool {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == "type" && attr.Name.Space != "" && slices.Contains(strings.Fields(attr.Value), "toc") {
+			return true
+		}
+	}
+	return false
+}
+
+// readXHTMLText returns the readable body text of an XHTML chapter
+func readXHTMLText(f *zip.File) string {
+	if f == nil {
+		return ""
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	return markupText(rc)
+}
@@ -1,49 +1,307 @@
 package extractor
 
 import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"path"
 	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
 
 // ExcelExtractor extracts excel file contents
-type ExcelExtractor struct{}
+type ExcelExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// Rows kept per sheet for header and column type inference; the rest of
+// the sheet is only counted
+const (
+	sheetSampleRows     = 50
+	sheetSampleRowsDeep = 200
+)
+
+// xlsxWorkbook is the subset of xl/workbook.xml needed to locate sheet parts
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships is an OOXML .rels part
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// relsTargets reads the relationships of an OOXML part (e.g.
+// "xl/workbook.xml" from "xl/_rels/workbook.xml.rels") through decode,
+// which unmarshals the named package part, and resolves their targets to
+// package paths. Only relationships whose type ends in relType are kept,
+// or all of them when relType is empty.
+//
+// Returns the target paths by relationship ID, or nil if the part has no
+// readable relationships.
+func relsTargets(decode func(name string, v any) error, part, relType string) map[string]string {
+	dir, base := path.Split(part)
+	var rels xlsxRelationships
+	if decode(dir+"_rels/"+base+".rels", &rels) != nil {
+		return nil
+	}
 
-// Extract extracts content from an excel file or CSV
-func (e ExcelExtractor) Extract(path string) (*ExtractedContent, error) {
-	f, err := excelize.OpenFile(path)
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if !strings.HasSuffix(rel.Type, relType) {
+			continue
+		}
+		// Targets are relative to the part's folder unless they are absolute package paths
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join(dir, rel.Target)
+		}
+	}
+	return targets
+}
+
+// Extract extracts content from an excel file
+func (e ExcelExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := openRandomAccess(fsys, name)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	defer file.Close()
 
-	// Get first sheet name
-	sheetName := f.GetSheetName(0)
-
-	// Get rows (limit to top 5)
-	rows, err := f.GetRows(sheetName, excelize.Options{RawCellValue: true})
+	f, err := excelize.OpenReader(file.section())
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
+
+	sampleSize := sheetSampleRows
+	if e.Limit > 0 {
+		sampleSize = sheetSampleRowsDeep
+	}
+	formulas := xlsxFormulaCounts(f)
 
+	budget := previewLimit(e.Limit)
 	var sb strings.Builder
-	lineCount := 0
-	for _, row := range rows {
-		if lineCount > 5 {
-			break
+	var (
+		names        []string
+		sheets       []map[string]any
+		headers      []string
+		totalRows    int
+		formulaTotal int
+		uncounted    bool // some sheet's formulas could not be counted
+	)
+	seenHeader := make(map[string]bool)
+
+	for _, name := range f.GetSheetList() {
+		s, err := readSheetSample(f, name, sampleSize)
+		if err != nil {
+			continue
+		}
+		sample := s.rows
+		names = append(names, name)
+		totalRows += s.count
+
+		sheet := map[string]any{
+			"name": name,
+			"rows": s.count,
+		}
+		if n, ok := formulas[name]; ok {
+			sheet["formulas"] = n
+			formulaTotal += n
+		} else {
+			sheet["formulas_unknown"] = true
+			uncounted = true
+		}
+		if dim := s.dimension(); dim != "" {
+			sheet["dimension"] = dim
+		}
+		if visible, err := f.GetSheetVisible(name); err == nil && !visible {
+			sheet["hidden"] = true
+		}
+
+		// Describe the columns by their headers when the sheet has them
+		dataRows := sample
+		if h := detectHeaderRow(sample); h >= 0 {
+			header := sample[h]
+			dataRows = sample[h+1:]
+			types := columnTypes(dataRows)
+
+			var columns []string
+			for col, title := range header {
+				title = strings.TrimSpace(title)
+				if title == "" {
+					continue
+				}
+				// Columns without values (e.g. uncalculated formulas) have no type
+				if col < len(types) && types[col] != "" {
+					columns = append(columns, fmt.Sprintf("%s (%s)", title, types[col]))
+				} else {
+					columns = append(columns, title)
+				}
+				if !seenHeader[strings.ToLower(title)] {
+					seenHeader[strings.ToLower(title)] = true
+					headers = append(headers, title)
+				}
+			}
+			sheet["header_row"] = s.rowNumbers[h]
+			sheet["columns"] = columns
+		} else {
+			sheet["column_types"] = columnTypes(dataRows)
+		}
+		sheets = append(sheets, sheet)
+
+		fmt.Fprintf(&sb, "Sheet: %s\n", name)
+		for i, row := range sample {
+			// Scans show six rows per sheet; a larger budget shows rows until it is filled
+			if e.Limit == 0 && i > 5 {
+				break
+			}
+			if sb.Len() >= budget {
+				break
+			}
+			// Join columns with pipe for readability
+			sb.WriteString(strings.Join(row, " | "))
+			sb.WriteString("\n")
 		}
-		// Join columns with pipe for readability
-		sb.WriteString(strings.Join(row, " | "))
-		sb.WriteString("\n")
-		lineCount++
+	}
+
+	var namedRanges []string
+	for _, dn := range f.GetDefinedName() {
+		// Skip names Excel manages itself, such as _xlnm._FilterDatabase
+		if strings.HasPrefix(dn.Name, "_xlnm.") {
+			continue
+		}
+		namedRanges = append(namedRanges, fmt.Sprintf("%s = %s", dn.Name, dn.RefersTo))
+	}
+
+	details := map[string]any{
+		"type":                 "xlsx",
+		"sheet_names":          names,
+		"sheet_count":          len(names),
+		"sheets":               sheets,
+		"total_rows_estimated": totalRows,
+	}
+	if !uncounted {
+		details["formula_count"] = formulaTotal
+	}
+	if len(headers) > 0 {
+		details["headers"] = headers
+	}
+	if len(namedRanges) > 0 {
+		details["named_ranges"] = namedRanges
 	}
 
 	return &ExtractedContent{
 		Category: "spreadsheet",
-		Preview:  sb.String(),
-		Details: map[string]any{
-			"sheet_name":           sheetName,
-			"total_rows_estimated": len(rows),
-		},
+		Preview:  truncatePreview(sb.String(), budget),
+		Details:  details,
 	}, nil
 }
+
+// sheetSample is what readSheetSample keeps of a sheet
+type sheetSample struct {
+	rows       [][]string // first non-empty rows, trailing blank cells trimmed
+	rowNumbers []int      // 1-based sheet row of each sampled row
+	count      int        // non-empty rows in the whole sheet
+	lastRow    int
+	lastCol    int
+}
+
+// readSheetSample streams a sheet, keeping its first sampleSize non-empty
+// rows and counting the rest
+func readSheetSample(f *excelize.File, sheet string, sampleSize int) (*sheetSample, error) {
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	s := &sheetSample{}
+	rowNumber := 0
+	for rows.Next() {
+		rowNumber++
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		for len(cols) > 0 && strings.TrimSpace(cols[len(cols)-1]) == "" {
+			cols = cols[:len(cols)-1]
+		}
+		if len(cols) == 0 {
+			continue
+		}
+		s.count++
+		s.lastRow = rowNumber
+		s.lastCol = max(s.lastCol, len(cols))
+		if len(s.rows) < sampleSize {
+			s.rows = append(s.rows, cols)
+			s.rowNumbers = append(s.rowNumbers, rowNumber)
+		}
+	}
+	return s, rows.Error()
+}
+
+// dimension returns the used range of the sheet, e.g. "A1:F120"
+func (s *sheetSample) dimension() string {
+	if s.count == 0 {
+		return ""
+	}
+	end, err := excelize.CoordinatesToCellName(s.lastCol, s.lastRow)
+	if err != nil {
+		return ""
+	}
+	return "A1:" + end
+}
+
+// xlsxFormulaCounts counts the formula cells of each sheet by scanning the
+// worksheet parts excelize has already unzipped, since excelize only
+// exposes formulas cell by cell. Worksheets too large for excelize to keep
+// in memory (see excelize.Options.UnzipXMLSizeLimit) are not counted and
+// have no entry in the result.
+func xlsxFormulaCounts(f *excelize.File) map[string]int {
+	counts := make(map[string]int)
+
+	part := func(name string) []byte {
+		data, _ := f.Pkg.Load(name)
+		b, _ := data.([]byte)
+		return b
+	}
+	decode := func(name string, v any) error {
+		return xml.Unmarshal(part(name), v)
+	}
+
+	var workbook xlsxWorkbook
+	targets := relsTargets(decode, "xl/workbook.xml", "")
+	if decode("xl/workbook.xml", &workbook) != nil || targets == nil {
+		return counts
+	}
+
+	for _, sheet := range workbook.Sheets {
+		data := part(targets[sheet.RID])
+		if data == nil {
+			continue
+		}
+		counts[sheet.Name] = 0
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			t, err := decoder.Token()
+			if err != nil {
+				break
+			}
+			if el, ok := t.(xml.StartElement); ok && el.Name.Local == "f" {
+				counts[sheet.Name]++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,66 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// buildXLSX writes a workbook with two formulas on Summary and a Data
+// sheet of rows rows without formulas
+func buildXLSX(t *testing.T, rows int) []byte {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetName("Sheet1", "Summary")
+	f.SetSheetRow("Summary", "A1", &[]any{"Item", "Amount"})
+	f.SetSheetRow("Summary", "A2", &[]any{"Rent", 1200})
+	f.SetSheetRow("Summary", "A3", &[]any{"Food", 300})
+	f.SetCellFormula("Summary", "B4", "SUM(B2:B3)")
+	f.SetCellFormula("Summary", "C4", "B4*2")
+
+	f.NewSheet("Data")
+	for i := 1; i <= rows; i++ {
+		f.SetSheetRow("Data", fmt.Sprintf("A%d", i), &[]any{"row", i})
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExcelExtractFormulas(t *testing.T) {
+	fsys := fstest.MapFS{"book.xlsx": {Data: buildXLSX(t, 3)}}
+	content, err := ExcelExtractor{}.Extract(fsys, "book.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content.Details["formula_count"] != 2 {
+		t.Errorf("formula_count = %v, want 2", content.Details["formula_count"])
+	}
+	var perSheet []any
+	for _, sheet := range content.Details["sheets"].([]map[string]any) {
+		perSheet = append(perSheet, sheet["formulas"])
+	}
+	if want := []any{2, 0}; !reflect.DeepEqual(perSheet, want) {
+		t.Errorf("sheet formulas = %v, want %v", perSheet, want)
+	}
+}
+
+func TestXLSXFormulaCountsSkipsLargeSheets(t *testing.T) {
+	// Sheets over UnzipXMLSizeLimit stay on disk, so their formulas are unknown
+	f, err := excelize.OpenReader(bytes.NewReader(buildXLSX(t, 200)), excelize.Options{UnzipXMLSizeLimit: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	counts := xlsxFormulaCounts(f)
+	if want := map[string]int{"Summary": 2}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("xlsxFormulaCounts = %v, want %v", counts, want)
+	}
+}
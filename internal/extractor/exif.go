@@ -0,0 +1,272 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TIFF tags Scout reads from IFD0, the Exif IFD and the GPS IFD
+const (
+	tagImageWidth       = 0x0100
+	tagImageLength      = 0x0101
+	tagPhotometric      = 0x0106
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagLensModel        = 0xA434
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+// maxIFDEntries guards against corrupt entry counts
+const maxIFDEntries = 512
+
+// tiffTypeSizes is the byte size of each TIFF field type
+var tiffTypeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// exifOrientations describes the EXIF orientation values
+var exifOrientations = map[uint32]string{
+	1: "normal",
+	2: "mirrored",
+	3: "rotated 180°",
+	4: "mirrored vertically",
+	5: "mirrored, rotated 90° CCW",
+	6: "rotated 90° CW",
+	7: "mirrored, rotated 90° CW",
+	8: "rotated 90° CCW",
+}
+
+// tiffPhotometric maps PhotometricInterpretation values to color modes
+var tiffPhotometric = map[uint32]string{0: "grayscale", 1: "grayscale", 2: "RGB", 3: "indexed", 5: "CMYK", 6: "YCbCr", 8: "CIELab"}
+
+// exifInfo is the metadata read from a TIFF structure (a TIFF file or an
+// EXIF block embedded in another format)
+type exifInfo struct {
+	make, model string
+	lens        string
+	software    string
+	taken       time.Time
+	orientation uint32
+	latitude    float64
+	longitude   float64
+	hasGPS      bool
+	width       uint32
+	height      uint32
+	photometric uint32
+	hasColor    bool
+}
+
+// tiffEntry is one IFD entry; values of 4 bytes or less are stored inline
+type tiffEntry struct {
+	tag, typ uint16
+	count    uint32
+	value    [4]byte
+}
+
+// tiffReader reads IFDs from a TIFF structure
+type tiffReader struct {
+	r     io.ReaderAt
+	order binary.ByteOrder
+}
+
+// parseTIFF reads IFD0 and the Exif and GPS IFDs it points to
+func parseTIFF(r io.ReaderAt) (*exifInfo, error) {
+	header := make([]byte, 8)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+
+	t := &tiffReader{r: r}
+	switch {
+	case bytes.HasPrefix(header, []byte("II*\x00")):
+		t.order = binary.LittleEndian
+	case bytes.HasPrefix(header, []byte("MM\x00*")):
+		t.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a TIFF structure")
+	}
+
+	info := &exifInfo{}
+	ifd0, err := t.readIFD(t.order.Uint32(header[4:]))
+	if err != nil {
+		return nil, err
+	}
+
+	var exifOffset, gpsOffset uint32
+	for _, e := range ifd0 {
+		switch e.tag {
+		case tagImageWidth:
+			info.width = t.uint(e)
+		case tagImageLength:
+			info.height = t.uint(e)
+		case tagPhotometric:
+			info.photometric, info.hasColor = t.uint(e), true
+		case tagMake:
+			info.make = t.string(e)
+		case tagModel:
+			info.model = t.string(e)
+		case tagOrientation:
+			info.orientation = t.uint(e)
+		case tagSoftware:
+			info.software = t.string(e)
+		case tagDateTime:
+			if info.taken.IsZero() {
+				info.taken = parseExifTime(t.string(e))
+			}
+		case tagExifIFD:
+			exifOffset = t.uint(e)
+		case tagGPSIFD:
+			gpsOffset = t.uint(e)
+		}
+	}
+
+	if exifOffset > 0 {
+		if entries, err := t.readIFD(exifOffset); err == nil {
+			for _, e := range entries {
+				switch e.tag {
+				case tagDateTimeOriginal:
+					// The original capture time beats IFD0's last-modified time
+					if taken := parseExifTime(t.string(e)); !taken.IsZero() {
+						info.taken = taken
+					}
+				case tagLensModel:
+					info.lens = t.string(e)
+				}
+			}
+		}
+	}
+
+	if gpsOffset > 0 {
+		if entries, err := t.readIFD(gpsOffset); err == nil {
+			var latRef, lonRef string
+			var lat, lon []float64
+			for _, e := range entries {
+				switch e.tag {
+				case tagGPSLatitudeRef:
+					latRef = t.string(e)
+				case tagGPSLatitude:
+					lat = t.rationals(e)
+				case tagGPSLongitudeRef:
+					lonRef = t.string(e)
+				case tagGPSLongitude:
+					lon = t.rationals(e)
+				}
+			}
+			if len(lat) == 3 && len(lon) == 3 {
+				info.latitude = lat[0] + lat[1]/60 + lat[2]/3600
+				info.longitude = lon[0] + lon[1]/60 + lon[2]/3600
+				if latRef == "S" {
+					info.latitude = -info.latitude
+				}
+				if lonRef == "W" {
+					info.longitude = -info.longitude
+				}
+				info.hasGPS = info.latitude != 0 || info.longitude != 0
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// readIFD reads the entries of the IFD at offset
+func (t *tiffReader) readIFD(offset uint32) ([]tiffEntry, error) {
+	buf := make([]byte, 2)
+	if _, err := t.r.ReadAt(buf, int64(offset)); err != nil {
+		return nil, err
+	}
+	n := int(t.order.Uint16(buf))
+	if n > maxIFDEntries {
+		return nil, fmt.Errorf("corrupt IFD with %d entries", n)
+	}
+
+	data := make([]byte, n*12)
+	if _, err := t.r.ReadAt(data, int64(offset)+2); err != nil {
+		return nil, err
+	}
+	entries := make([]tiffEntry, n)
+	for i := range entries {
+		raw := data[i*12:]
+		entries[i] = tiffEntry{
+			tag:   t.order.Uint16(raw),
+			typ:   t.order.Uint16(raw[2:]),
+			count: t.order.Uint32(raw[4:]),
+		}
+		copy(entries[i].value[:], raw[8:12])
+	}
+	return entries, nil
+}
+
+// data returns the raw bytes of an entry's value
+func (t *tiffReader) data(e tiffEntry) []byte {
+	size := tiffTypeSizes[e.typ] * e.count
+	if size <= 4 {
+		return e.value[:size]
+	}
+	// Text and rationals are small; anything larger is not metadata we use
+	if size > 64*1024 {
+		return nil
+	}
+	buf := make([]byte, size)
+	if _, err := t.r.ReadAt(buf, int64(t.order.Uint32(e.value[:]))); err != nil {
+		return nil
+	}
+	return buf
+}
+
+// uint returns the first value of a SHORT or LONG entry
+func (t *tiffReader) uint(e tiffEntry) uint32 {
+	switch e.typ {
+	case 3:
+		return uint32(t.order.Uint16(e.value[:]))
+	case 4:
+		return t.order.Uint32(e.value[:])
+	}
+	return 0
+}
+
+// string returns an ASCII entry without its NUL terminator
+func (t *tiffReader) string(e tiffEntry) string {
+	s := string(t.data(e))
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// rationals returns the values of a RATIONAL entry
+func (t *tiffReader) rationals(e tiffEntry) []float64 {
+	if e.typ != 5 {
+		return nil
+	}
+	data := t.data(e)
+	var values []float64
+	for i := 0; i+8 <= len(data); i += 8 {
+		num, den := t.order.Uint32(data[i:]), t.order.Uint32(data[i+4:])
+		if den == 0 {
+			return nil
+		}
+		values = append(values, float64(num)/float64(den))
+	}
+	return values
+}
+
+// parseExifTime parses EXIF's "2006:01:02 15:04:05" timestamps
+func parseExifTime(s string) time.Time {
+	t, err := time.Parse("2006:01:02 15:04:05", strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
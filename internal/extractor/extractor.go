@@ -1,6 +1,16 @@
 // Package extractor is used to mine valuable information from a directory and its contents
 package extractor
 
+import (
+	"fmt"
+	"io/fs"
+	"unicode/utf8"
+)
+
+// DefaultPreviewLimit is the preview size (in bytes) used during directory
+// scans. Extractors accept a larger Limit when a single file is examined.
+const DefaultPreviewLimit = 1000
+
 // ExtractedContent represents metadata extracted from a file.
 // This is the common format returned by all extractor implementations.
 type ExtractedContent struct {
@@ -12,14 +22,48 @@ type ExtractedContent struct {
 
 // Extractor defines the interface for extracting content from files.
 // Each file type (PDF, code, images, etc.) has its own implementation.
+//
+// Files are read through an fs.FS, so the same extractor works on the local
+// disk (see ExtractFile), inside a zip or tar archive, on an embedded FS or
+// on an in-memory fstest.MapFS.
 type Extractor interface {
 	// Extract reads a file and returns structured metadata about its contents.
 	//
 	// Parameters:
-	//   - path: Full path to the file to extract
+	//   - fsys: File system holding the file
+	//   - name: Slash-separated path of the file within fsys
 	//
 	// Returns:
 	//   - *ExtractedContent: Extracted metadata and preview
 	//   - error: Any error encountered during extraction
-	Extract(path string) (*ExtractedContent, error)
+	Extract(fsys fs.FS, name string) (*ExtractedContent, error)
+}
+
+// previewLimit returns limit, or DefaultPreviewLimit when it is unset
+func previewLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPreviewLimit
+	}
+	return limit
+}
+
+// plural formats a count with its noun, e.g. "1 file" or "3 files"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// truncatePreview cuts text to at most limit bytes without splitting a
+// multi-byte character, marking the cut with "..."
+func truncatePreview(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
 }
@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// maxInMemoryFile bounds how much of a file is buffered when its file
+// system cannot seek, e.g. a compressed zip entry
+const maxInMemoryFile = 256 * 1024 * 1024
+
+// ExtractFile runs an extractor on a file on the local disk.
+//
+// Parameters:
+//   - e: Extractor to run
+//   - path: Path to the file to extract
+//
+// Returns:
+//   - *ExtractedContent: Extracted metadata and preview
+//   - error: Any error encountered during extraction
+func ExtractFile(e Extractor, path string) (*ExtractedContent, error) {
+	return e.Extract(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// randomAccessFile is a file opened for the formats that need to seek,
+// such as zip containers, PDF and OLE documents
+type randomAccessFile struct {
+	io.ReaderAt
+	size   int64
+	closer io.Closer
+}
+
+// Close releases the underlying file
+func (f *randomAccessFile) Close() error {
+	return f.closer.Close()
+}
+
+// section returns a reader over the whole file
+func (f *randomAccessFile) section() *io.SectionReader {
+	return io.NewSectionReader(f.ReaderAt, 0, f.size)
+}
+
+// openRandomAccess opens name for random access. OS files and in-memory
+// files support it directly; other files are read into memory.
+func openRandomAccess(fsys fs.FS, name string) (*randomAccessFile, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if ra, ok := file.(io.ReaderAt); ok {
+		return &randomAccessFile{ReaderAt: ra, size: info.Size(), closer: file}, nil
+	}
+
+	defer file.Close()
+	if info.Size() > maxInMemoryFile {
+		return nil, fmt.Errorf("%s is too large to read from this file system (%d bytes)", name, info.Size())
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxInMemoryFile))
+	if err != nil {
+		return nil, err
+	}
+	return &randomAccessFile{ReaderAt: bytes.NewReader(data), size: int64(len(data)), closer: io.NopCloser(nil)}, nil
+}
+
+// openZip opens a zip container such as a docx, epub or zip archive
+func openZip(fsys fs.FS, name string) (*zip.Reader, io.Closer, error) {
+	file, err := openRandomAccess(fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := zip.NewReader(file, file.size)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return r, file, nil
+}
@@ -0,0 +1,275 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxOutlineSymbols = 40 // types, functions or methods kept per list
+	maxOutlineDoc     = 200
+)
+
+// goOutline parses Go source and describes it by its declarations rather
+// than its first lines: the package and its doc comment, the imports
+// (grouped import blocks included), the exported types, functions and
+// methods with their doc comments (every declaration for main packages and
+// files that export nothing), whether it is a main package and which tests
+// it holds. Files that do not parse are outlined as far as the parser got.
+func goOutline(name string, data []byte) (details map[string]any, outline string, ok bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path.Base(name), data, parser.ParseComments|parser.SkipObjectResolution)
+	// Without a package clause there is nothing to outline
+	if file == nil || file.Name == nil || file.Name.Name == "" {
+		return nil, "", false
+	}
+
+	pkg := file.Name.Name
+	isTest := strings.HasSuffix(name, "_test.go")
+	details = map[string]any{
+		"language": "go",
+		"package":  pkg,
+	}
+	if err != nil {
+		details["parse_error"] = firstLine(err.Error())
+	}
+	if isTest {
+		details["is_test"] = true
+	}
+	if ast.IsGenerated(file) {
+		details["generated"] = true
+	}
+	if constraint := buildConstraint(file); constraint != "" {
+		details["build_tags"] = constraint
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s", pkg)
+	if doc := docSentence(file.Doc); doc != "" {
+		details["doc"] = doc
+		fmt.Fprintf(&b, " // %s", doc)
+	}
+	b.WriteString("\n")
+
+	var imports []string
+	for _, spec := range file.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imports = append(imports, importPath)
+		}
+	}
+	if len(imports) > 0 {
+		details["imports"] = imports
+		fmt.Fprintf(&b, "import %s\n", strings.Join(imports, ", "))
+	}
+
+	// Main packages and internal helper files export nothing, so all of
+	// their declarations count
+	all := pkg == "main" || !exportsAnything(file)
+	include := func(ident *ast.Ident) bool { return all || ident.IsExported() }
+	var typeList, funcs, methods, tests []string
+	docs := make(map[string]string)
+	hasMain := false
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if !include(ts.Name) {
+					continue
+				}
+				signature := ts.Name.Name + typeParams(ts.TypeParams) + " " + typeKind(ts.Type)
+				typeList = append(typeList, signature)
+				doc := docSentence(ts.Doc)
+				if doc == "" && len(d.Specs) == 1 {
+					doc = docSentence(d.Doc)
+				}
+				if doc != "" {
+					docs[ts.Name.Name] = doc
+				}
+				writeOutlineLine(&b, "type "+signature, doc)
+			}
+
+		case *ast.FuncDecl:
+			fn := d.Name.Name
+			if d.Recv == nil && pkg == "main" && fn == "main" {
+				hasMain = true
+			}
+			if d.Recv == nil && isTest && isTestFunc(d) {
+				tests = append(tests, fn)
+				continue
+			}
+			if !include(d.Name) {
+				continue
+			}
+			signature := fn + typeParams(d.Type.TypeParams) + "(" + fieldList(d.Type.Params) + ")" + results(d.Type.Results)
+			key := fn
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				receiver := types.ExprString(d.Recv.List[0].Type)
+				// Methods of unexported types are not part of the API
+				if base := strings.TrimLeft(receiver, "*"); !ast.IsExported(strings.SplitN(base, "[", 2)[0]) && !all {
+					continue
+				}
+				signature = "(" + receiver + ") " + signature
+				key = strings.TrimLeft(strings.SplitN(receiver, "[", 2)[0], "*") + "." + fn
+				methods = append(methods, signature)
+			} else {
+				funcs = append(funcs, signature)
+			}
+			doc := docSentence(d.Doc)
+			if doc != "" {
+				docs[key] = doc
+			}
+			writeOutlineLine(&b, "func "+signature, doc)
+		}
+	}
+
+	for key, list := range map[string][]string{"types": typeList, "functions": funcs, "methods": methods} {
+		if len(list) > 0 {
+			details[key] = list[:min(len(list), maxOutlineSymbols)]
+		}
+	}
+	if len(docs) > 0 {
+		details["docs"] = docs
+	}
+	if pkg == "main" {
+		details["has_main"] = hasMain
+	}
+	if len(tests) > 0 {
+		details["tests"] = tests[:min(len(tests), maxOutlineSymbols)]
+		details["test_count"] = len(tests)
+		fmt.Fprintf(&b, "tests: %s\n", strings.Join(tests, ", "))
+	}
+	return details, b.String(), true
+}
+
+// exportsAnything reports whether a file declares an exported type or
+// function outside of its tests
+func exportsAnything(file *ast.File) bool {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.IsExported() {
+					return true
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Name.IsExported() && !isTestFunc(d) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeOutlineLine writes one declaration of the outline, followed by the
+// first sentence of its doc comment
+func writeOutlineLine(b *strings.Builder, declaration, doc string) {
+	b.WriteString(declaration)
+	if doc != "" {
+		fmt.Fprintf(b, "\n\t// %s", doc)
+	}
+	b.WriteString("\n")
+}
+
+// isTestFunc reports whether a function is run by go test: TestX(t
+// *testing.T), BenchmarkX, FuzzX or an ExampleX
+func isTestFunc(fn *ast.FuncDecl) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		if rest, ok := strings.CutPrefix(fn.Name.Name, prefix); ok && (rest == "" || !isLowerStart(rest)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLowerStart reports whether s starts with a lowercase letter, which
+// keeps helpers such as "Testable" out of the test list
+func isLowerStart(s string) bool {
+	return s != "" && s[0] >= 'a' && s[0] <= 'z'
+}
+
+// buildConstraint returns the file's //go:build expression, if any
+func buildConstraint(file *ast.File) string {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if expr, ok := strings.CutPrefix(c.Text, "//go:build "); ok {
+				return strings.TrimSpace(expr)
+			}
+		}
+	}
+	return ""
+}
+
+// docSentence returns the first sentence of a doc comment, bounded in length
+func docSentence(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return firstSentence(group.Text())
+}
+
+// typeKind names what a type declaration declares: struct, interface, or
+// the underlying type ("func(string) error", "map[string]int")
+func typeKind(expr ast.Expr) string {
+	switch expr.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	}
+	return types.ExprString(expr)
+}
+
+// typeParams formats a generic declaration's type parameters ("[K comparable, V any]")
+func typeParams(list *ast.FieldList) string {
+	if list == nil || len(list.List) == 0 {
+		return ""
+	}
+	return "[" + fieldList(list) + "]"
+}
+
+// fieldList formats parameters as "fsys fs.FS, name string"
+func fieldList(list *ast.FieldList) string {
+	if list == nil {
+		return ""
+	}
+	var fields []string
+	for _, field := range list.List {
+		typ := types.ExprString(field.Type)
+		if len(field.Names) == 0 {
+			fields = append(fields, typ)
+			continue
+		}
+		var names []string
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+		fields = append(fields, strings.Join(names, ", ")+" "+typ)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// results formats a function's results as " error" or " (int, error)"
+func results(list *ast.FieldList) string {
+	if list == nil || len(list.List) == 0 {
+		return ""
+	}
+	if len(list.List) == 1 && len(list.List[0].Names) == 0 {
+		return " " + types.ExprString(list.List[0].Type)
+	}
+	return " (" + fieldList(list) + ")"
+}
@@ -0,0 +1,121 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoOutline(t *testing.T) {
+	src := `//go:build linux && !cgo
+
+// Package cache stores results on disk. It is safe for concurrent use.
+package cache
+
+import (
+	"io/fs"
+	jsonv2 "encoding/json"
+)
+
+// Store keeps entries in a directory.
+type Store[K comparable] struct{}
+
+// Loader loads a value.
+type Loader func(key string) ([]byte, error)
+
+type entry struct{}
+
+// Open opens the store at dir.
+func Open(fsys fs.FS, dir string) (*Store[string], error) { return nil, nil }
+
+// Get returns the cached value.
+func (s *Store[K]) Get(key K) ([]byte, bool) { return nil, false }
+
+func (e *entry) size() int { return 0 }
+
+func helper() {}
+`
+	details, outline, ok := goOutline("cache/store.go", []byte(src))
+	if !ok {
+		t.Fatal("goOutline failed")
+	}
+	want := map[string]any{
+		"language":   "go",
+		"package":    "cache",
+		"doc":        "Package cache stores results on disk.",
+		"build_tags": "linux && !cgo",
+		"imports":    []string{"io/fs", "encoding/json"},
+		"types":      []string{"Store[K comparable] struct", "Loader func(key string) ([]byte, error)"},
+		"functions":  []string{"Open(fsys fs.FS, dir string) (*Store[string], error)"},
+		"methods":    []string{"(*Store[K]) Get(key K) ([]byte, bool)"},
+		"docs": map[string]string{
+			"Store": "Store keeps entries in a directory.", "Loader": "Loader loads a value.",
+			"Open": "Open opens the store at dir.", "Store.Get": "Get returns the cached value.",
+		},
+	}
+	if !reflect.DeepEqual(details, want) {
+		t.Errorf("details = %v\nwant %v", details, want)
+	}
+	wantOutline := `package cache // Package cache stores results on disk.
+import io/fs, encoding/json
+type Store[K comparable] struct
+	// Store keeps entries in a directory.
+type Loader func(key string) ([]byte, error)
+	// Loader loads a value.
+func Open(fsys fs.FS, dir string) (*Store[string], error)
+	// Open opens the store at dir.
+func (*Store[K]) Get(key K) ([]byte, bool)
+	// Get returns the cached value.
+`
+	if outline != wantOutline {
+		t.Errorf("outline =\n%s\nwant\n%s", outline, wantOutline)
+	}
+}
+
+func TestGoOutlineMainAndTests(t *testing.T) {
+	details, _, ok := goOutline("main.go", []byte("package main\n\ntype config struct{}\n\nfunc run() error { return nil }\n\nfunc main() {}\n"))
+	if !ok {
+		t.Fatal("goOutline failed")
+	}
+	// Main packages list every declaration
+	if details["has_main"] != true || !reflect.DeepEqual(details["functions"], []string{"run() error", "main()"}) ||
+		!reflect.DeepEqual(details["types"], []string{"config struct"}) {
+		t.Errorf("main details = %v", details)
+	}
+
+	details, _, _ = goOutline("store_test.go", []byte(`// Code generated by mockgen. DO NOT EDIT.
+
+package cache
+
+func TestOpen(t *testing.T) {}
+func Test(t *testing.T) {}
+func Benchmark_Get(b *testing.B) {}
+func FuzzKey(f *testing.F) {}
+func ExampleOpen() {}
+func Testable() {}
+func newFixture() {}
+`))
+	if details["is_test"] != true || details["generated"] != true || details["test_count"] != 5 ||
+		!reflect.DeepEqual(details["tests"], []string{"TestOpen", "Test", "Benchmark_Get", "FuzzKey", "ExampleOpen"}) {
+		t.Errorf("test details = %v", details)
+	}
+	// Testable is exported, so the unexported helper is left out
+	if !reflect.DeepEqual(details["functions"], []string{"Testable()"}) {
+		t.Errorf("functions = %v, want [Testable()]", details["functions"])
+	}
+}
+
+func TestGoOutlineParseError(t *testing.T) {
+	details, _, ok := goOutline("broken.go", []byte("package broken\n\nfunc Good() {}\n\nfunc Bad( {\n"))
+	if !ok {
+		t.Fatal("want a partial outline of a file with a package clause")
+	}
+	if funcs, _ := details["functions"].([]string); details["parse_error"] == nil || len(funcs) == 0 || funcs[0] != "Good()" {
+		t.Errorf("details = %v, want Good() and a parse error", details)
+	}
+
+	for _, src := range []string{"// nothing here\n", "hello world"} {
+		if _, _, ok := goOutline("x.go", []byte(src)); ok {
+			t.Errorf("goOutline(%q): want no outline for a file without a package clause", src)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLExtractor strips web pages to readable text and records their title,
+// description, headings, links and the scripts and stylesheets they load
+type HTMLExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+const (
+	maxHTMLHeadings = 20
+	maxHTMLAssets   = 10 // script and stylesheet URLs kept
+)
+
+// htmlPage is what a single pass over an (X)HTML document collects
+type htmlPage struct {
+	text          string
+	title         string
+	description   string
+	generator     string
+	lang          string
+	headings      []string
+	links         int
+	externalLinks int
+	images        int
+	scripts       []string
+	stylesheets   []string
+	inlineScripts int
+	inlineStyles  int
+}
+
+// htmlBlockElements start a new line of readable text
+var htmlBlockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "header": true, "footer": true, "nav": true,
+	"main": true, "aside": true, "blockquote": true, "pre": true, "table": true,
+	"ul": true, "ol": true, "dt": true, "dd": true, "figcaption": true,
+}
+
+// htmlSkippedElements hold no readable text
+var htmlSkippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+}
+
+// Extract extracts readable text and page metadata from an HTML file
+func (e HTMLExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	page := parseHTML(file)
+	return page.content(previewLimit(e.Limit)), nil
+}
+
+// content converts a parsed page into extractor output
+func (page *htmlPage) content(limit int) *ExtractedContent {
+	details := map[string]any{
+		"type":           "html",
+		"links":          page.links,
+		"external_links": page.externalLinks,
+		"images":         page.images,
+	}
+	for key, value := range map[string]string{
+		"title":       page.title,
+		"description": page.description,
+		"generator":   page.generator,
+		"lang":        page.lang,
+	} {
+		if value != "" {
+			details[key] = value
+		}
+	}
+	if len(page.headings) > 0 {
+		details["headings"] = page.headings
+	}
+	if len(page.scripts) > 0 {
+		details["scripts"] = page.scripts
+	}
+	if len(page.stylesheets) > 0 {
+		details["stylesheets"] = page.stylesheets
+	}
+	if page.inlineScripts > 0 {
+		details["inline_scripts"] = page.inlineScripts
+	}
+	if page.inlineStyles > 0 {
+		details["inline_styles"] = page.inlineStyles
+	}
+
+	preview := page.text
+	if page.title != "" {
+		preview = fmt.Sprintf("%s\n\n%s", page.title, page.text)
+	}
+	lines := 0
+	if page.text != "" {
+		lines = strings.Count(page.text, "\n") + 1
+	}
+
+	return &ExtractedContent{
+		Category: "document",
+		Preview:  truncatePreview(preview, limit),
+		Lines:    lines,
+		Details:  details,
+	}
+}
+
+// parseHTML tokenizes (X)HTML once, collecting the visible text (without
+// scripts and styles) along with the page's metadata, headings and assets
+func parseHTML(r io.Reader) *htmlPage {
+	page := &htmlPage{}
+	z := html.NewTokenizer(r)
+
+	var text, title, heading strings.Builder
+	skip := 0
+	inTitle, inHeading := false, false
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			attrs := make(map[string]string, len(tok.Attr))
+			for _, a := range tok.Attr {
+				attrs[strings.ToLower(a.Key)] = strings.TrimSpace(a.Val)
+			}
+
+			switch tok.Data {
+			case "html":
+				page.lang = attrs["lang"]
+			case "title":
+				inTitle = tt == html.StartTagToken
+			case "meta":
+				switch strings.ToLower(attrs["name"] + attrs["property"]) {
+				case "description", "og:description":
+					if page.description == "" {
+						page.description = attrs["content"]
+					}
+				case "generator":
+					page.generator = attrs["content"]
+				}
+			case "a":
+				if href := attrs["href"]; href != "" {
+					page.links++
+					if isExternalLink(href) {
+						page.externalLinks++
+					}
+				}
+			case "img":
+				page.images++
+			case "script":
+				if src := attrs["src"]; src != "" {
+					if len(page.scripts) < maxHTMLAssets {
+						page.scripts = append(page.scripts, src)
+					}
+				} else {
+					page.inlineScripts++
+				}
+			case "style":
+				page.inlineStyles++
+			case "link":
+				if strings.Contains(strings.ToLower(attrs["rel"]), "stylesheet") && attrs["href"] != "" &&
+					len(page.stylesheets) < maxHTMLAssets {
+					page.stylesheets = append(page.stylesheets, attrs["href"])
+				}
+			case "h1", "h2", "h3":
+				inHeading = true
+				heading.Reset()
+			}
+
+			if htmlSkippedElements[tok.Data] && tt == html.StartTagToken {
+				skip++
+			}
+			if htmlBlockElements[tok.Data] {
+				text.WriteString("\n")
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch tag := string(name); {
+			case tag == "title":
+				inTitle = false
+			case tag == "h1" || tag == "h2" || tag == "h3":
+				if line := collapseSpace(heading.String()); inHeading && line != "" && len(page.headings) < maxHTMLHeadings {
+					page.headings = append(page.headings, line)
+				}
+				inHeading = false
+			case htmlSkippedElements[tag] && skip > 0:
+				skip--
+			}
+			if htmlBlockElements[string(name)] {
+				text.WriteString("\n")
+			}
+
+		case html.TextToken:
+			switch {
+			case inTitle:
+				title.Write(z.Text())
+			case skip == 0:
+				chunk := z.Text()
+				text.Write(chunk)
+				if inHeading {
+					heading.Write(chunk)
+				}
+			}
+		}
+	}
+
+	page.title = collapseSpace(title.String())
+	var lines []string
+	for line := range strings.SplitSeq(text.String(), "\n") {
+		if line = collapseSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	page.text = strings.Join(lines, "\n")
+	return page
+}
+
+// markupText strips tags from (X)HTML, skipping the <title>, <script> and
+// <style>, and collapses whitespace within each block
+func markupText(r io.Reader) string {
+	return parseHTML(r).text
+}
+
+// collapseSpace joins the words of s with single spaces
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// isExternalLink reports whether an href leaves the site
+func isExternalLink(href string) bool {
+	href = strings.ToLower(href)
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") || strings.HasPrefix(href, "//")
+}
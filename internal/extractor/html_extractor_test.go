@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHTML(t *testing.T) {
+	page := parseHTML(strings.NewReader(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <title> Release
+    notes </title>
+  <meta name="description" content="What changed">
+  <meta property="og:description" content="Ignored, description came first">
+  <meta name="generator" content="Hugo 0.120">
+  <link rel="stylesheet" href="/css/site.css">
+  <link rel="icon" href="/favicon.ico">
+  <script src="https://cdn.example.com/app.js"></script>
+  <script>var hidden = "<p>not text</p>";</script>
+  <style>body { color: red }</style>
+</head>
+<body>
+  <nav><a href="/">Home</a> <a href="https://github.com/acme">GitHub</a></nav>
+  <h1>Version <em>2.0</em></h1>
+  <p>Faster   builds.<br>Smaller images.</p>
+  <noscript><p>Enable JavaScript</p></noscript>
+  <h2>Fixes</h2>
+  <ul><li>Crash on start</li><li><img src="bug.png"> Typos</li></ul>
+  <h4>Not collected</h4>
+</body>
+</html>`))
+
+	wantText := "Home GitHub\nVersion 2.0\nFaster builds.\nSmaller images.\nFixes\nCrash on start\nTypos\nNot collected"
+	if page.text != wantText {
+		t.Errorf("text =\n%s\nwant\n%s", page.text, wantText)
+	}
+	want := htmlPage{
+		text:          wantText,
+		title:         "Release notes",
+		description:   "What changed",
+		generator:     "Hugo 0.120",
+		lang:          "en",
+		headings:      []string{"Version 2.0", "Fixes"},
+		links:         2,
+		externalLinks: 1,
+		images:        1,
+		scripts:       []string{"https://cdn.example.com/app.js"},
+		stylesheets:   []string{"/css/site.css"},
+		inlineScripts: 1,
+		inlineStyles:  1,
+	}
+	if !reflect.DeepEqual(*page, want) {
+		t.Errorf("page = %+v\nwant %+v", *page, want)
+	}
+}
+
+func TestHTMLContent(t *testing.T) {
+	content := parseHTML(strings.NewReader("<title>Docs</title><p>One</p><p>Two</p>")).content(DefaultPreviewLimit)
+	if content.Preview != "Docs\n\nOne\nTwo" || content.Lines != 2 || content.Category != "document" {
+		t.Errorf("content = %q, %d lines, %s", content.Preview, content.Lines, content.Category)
+	}
+	if content.Details["title"] != "Docs" || content.Details["description"] != nil {
+		t.Errorf("details = %v, want a title and no empty fields", content.Details)
+	}
+}
+
+func TestIsExternalLink(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com": true,
+		"HTTP://EXAMPLE.COM":  true,
+		"//cdn.example.com/x": true,
+		"/docs/":              false,
+		"#top":                false,
+		"mailto:a@b.c":        false,
+	}
+	for href, want := range tests {
+		if got := isExternalLink(href); got != want {
+			t.Errorf("isExternalLink(%q) = %v, want %v", href, got, want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// id3Frames maps ID3v2.3/2.4 and ID3v2.2 text frames to detail keys
+var id3Frames = map[string]string{
+	"TIT2": "title", "TT2": "title",
+	"TPE1": "artist", "TP1": "artist",
+	"TALB": "album", "TAL": "album",
+	"TYER": "year", "TYE": "year", "TDRC": "year",
+	"TCON": "genre", "TCO": "genre",
+	"TLEN": "length", "TLE": "length",
+}
+
+// id3Genres are the ID3v1 genre numbers, which ID3v2 genres may also refer
+// to as "(17)" or "17"
+var id3Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge", "Hip-Hop",
+	"Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B", "Rap", "Reggae", "Rock",
+	"Techno", "Industrial", "Alternative", "Ska", "Death Metal", "Pranks", "Soundtrack",
+	"Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance",
+	"Classical", "Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"Alternative Rock", "Bass", "Soul", "Punk", "Space", "Meditative", "Instrumental Pop",
+	"Instrumental Rock", "Ethnic", "Gothic", "Darkwave", "Techno-Industrial", "Electronic",
+	"Pop-Folk", "Eurodance", "Dream", "Southern Rock", "Comedy", "Cult", "Gangsta",
+	"Top 40", "Christian Rap", "Pop/Funk", "Jungle", "Native American", "Cabaret",
+	"New Wave", "Psychedelic", "Rave", "Showtunes", "Trailer", "Lo-Fi", "Tribal",
+	"Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+}
+
+// readID3v2 reads the text frames of an ID3v2 tag at the start of r and
+// returns them with the tag's total size (0 when there is no tag)
+func readID3v2(r io.ReaderAt) (map[string]string, int64) {
+	header := make([]byte, 10)
+	if _, err := r.ReadAt(header, 0); err != nil || string(header[:3]) != "ID3" {
+		return nil, 0
+	}
+	version, flags := header[3], header[5]
+	size := int64(syncsafe(header[6:10]))
+	total := 10 + size
+	if flags&0x10 != 0 { // footer present
+		total += 10
+	}
+	if version < 2 || version > 4 || size > 16*1024*1024 {
+		return nil, total
+	}
+
+	tag := make([]byte, size)
+	if _, err := r.ReadAt(tag, 10); err != nil && err != io.EOF {
+		return nil, total
+	}
+	// Unsynchronisation inserts a zero after every 0xFF; undo it tag-wide
+	if flags&0x80 != 0 && version < 4 {
+		tag = []byte(strings.ReplaceAll(string(tag), "\xFF\x00", "\xFF"))
+	}
+	if flags&0x40 != 0 && version == 3 && len(tag) >= 4 {
+		tag = tag[min(len(tag), 4+int(binary.BigEndian.Uint32(tag))):]
+	} else if flags&0x40 != 0 && version == 4 && len(tag) >= 4 {
+		tag = tag[min(len(tag), int(syncsafe(tag[:4]))):]
+	}
+
+	idSize, headerSize := 4, 10
+	if version == 2 {
+		idSize, headerSize = 3, 6
+	}
+
+	fields := make(map[string]string)
+	for len(tag) >= headerSize && tag[0] != 0 {
+		id := string(tag[:idSize])
+		var frameSize int
+		switch version {
+		case 2:
+			frameSize = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 3:
+			frameSize = int(binary.BigEndian.Uint32(tag[4:]))
+		case 4:
+			frameSize = int(syncsafe(tag[4:8]))
+		}
+		if frameSize <= 0 || headerSize+frameSize > len(tag) {
+			break
+		}
+		if key, ok := id3Frames[id]; ok && fields[key] == "" {
+			fields[key] = id3Text(tag[headerSize : headerSize+frameSize])
+		}
+		tag = tag[headerSize+frameSize:]
+	}
+
+	if genre := fields["genre"]; genre != "" {
+		fields["genre"] = id3GenreName(genre)
+	}
+	return fields, total
+}
+
+// readID3v1 reads the fixed-size ID3v1 tag stored in the last 128 bytes
+func readID3v1(r io.ReaderAt, size int64) map[string]string {
+	if size < 128 {
+		return nil
+	}
+	tag := make([]byte, 128)
+	if _, err := r.ReadAt(tag, size-128); err != nil || string(tag[:3]) != "TAG" {
+		return nil
+	}
+	text := func(b []byte) string {
+		if i := strings.IndexByte(string(b), 0); i >= 0 {
+			b = b[:i]
+		}
+		return strings.TrimSpace(decodeANSI(b))
+	}
+	fields := map[string]string{
+		"title":  text(tag[3:33]),
+		"artist": text(tag[33:63]),
+		"album":  text(tag[63:93]),
+		"year":   text(tag[93:97]),
+	}
+	if int(tag[127]) < len(id3Genres) {
+		fields["genre"] = id3Genres[tag[127]]
+	}
+	return fields
+}
+
+// id3Text decodes a text frame: an encoding byte followed by the text,
+// where several values are separated by NULs
+func id3Text(frame []byte) string {
+	if len(frame) < 2 {
+		return ""
+	}
+	var s string
+	switch frame[0] {
+	case 1, 2: // UTF-16 with BOM, UTF-16BE
+		s = decodeUTF16(frame[1:], frame[0] == 2)
+	case 3:
+		s = string(frame[1:])
+	default:
+		s = decodeANSI(frame[1:])
+	}
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// decodeUTF16 decodes UTF-16 text, honouring a byte order mark
+func decodeUTF16(b []byte, bigEndian bool) string {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	if len(b) >= 2 {
+		switch {
+		case b[0] == 0xFE && b[1] == 0xFF:
+			order, b = binary.BigEndian, b[2:]
+		case b[0] == 0xFF && b[1] == 0xFE:
+			order, b = binary.LittleEndian, b[2:]
+		}
+	}
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, order.Uint16(b[i:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// id3GenreName resolves genre references such as "(17)" or "17" to names
+func id3GenreName(genre string) string {
+	ref := strings.TrimSuffix(strings.TrimPrefix(genre, "("), ")")
+	if n, err := strconv.Atoi(ref); err == nil && n >= 0 && n < len(id3Genres) {
+		return id3Genres[n]
+	}
+	// "(17)Rock" style references carry the name after the number
+	if strings.HasPrefix(genre, "(") {
+		if i := strings.IndexByte(genre, ')'); i > 0 && i < len(genre)-1 {
+			return genre[i+1:]
+		}
+	}
+	return genre
+}
+
+// syncsafe decodes a 28-bit ID3 integer stored 7 bits per byte
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
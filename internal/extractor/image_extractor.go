@@ -0,0 +1,473 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // register GIF for image.DecodeConfig
+	_ "image/jpeg" // register JPEG for image.DecodeConfig
+	_ "image/png"  // register PNG for image.DecodeConfig
+	"io"
+	"io/fs"
+	"math"
+	"strings"
+
+	_ "golang.org/x/image/tiff" // register TIFF for image.DecodeConfig
+	_ "golang.org/x/image/webp" // register WebP for image.DecodeConfig
+)
+
+// ImageExtractor reads an image's dimensions, format and color mode from its
+// header, plus the camera, capture date and GPS position from EXIF metadata
+// in JPEG, PNG, WebP, TIFF and HEIC files
+type ImageExtractor struct{}
+
+// maxHEIFMetaSize bounds the HEIF meta box read into memory; it holds only
+// item descriptions, never pixel data
+const maxHEIFMetaSize = 4 * 1024 * 1024
+
+// heifBrands are the ftyp brands of HEIF still images
+var heifBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"hevc": true, "hevx": true, "mif1": true, "msf1": true,
+}
+
+// pngColorTypes maps the IHDR color type byte to a color mode
+var pngColorTypes = map[byte]string{0: "grayscale", 2: "RGB", 3: "indexed", 4: "grayscale+alpha", 6: "RGBA"}
+
+// Extract extracts image metadata
+func (e ImageExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := openRandomAccess(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 32)
+	n, err := file.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	header = header[:n]
+
+	format := imageFormat(header)
+	if format == "" {
+		return nil, fmt.Errorf("unrecognised image format")
+	}
+
+	details := map[string]any{"format": format}
+	var exif *exifInfo
+
+	switch format {
+	case "HEIC":
+		width, height, exifData := readHEIF(file)
+		if width > 0 {
+			details["width"], details["height"] = int(width), int(height)
+		}
+		if exifData != nil {
+			exif, _ = parseTIFF(exifData)
+		}
+	default:
+		if config, _, err := image.DecodeConfig(file.section()); err == nil {
+			details["width"], details["height"] = config.Width, config.Height
+			if mode := colorModelName(config.ColorModel); mode != "" {
+				details["color_mode"] = mode
+			}
+		}
+
+		var exifData io.ReaderAt
+		switch format {
+		case "JPEG":
+			exifData = jpegExif(file)
+		case "PNG":
+			exifData = pngExif(file)
+			if len(header) > 25 {
+				if mode, ok := pngColorTypes[header[25]]; ok {
+					details["color_mode"] = mode
+				}
+			}
+		case "WebP":
+			exifData = webpExif(file)
+		case "TIFF":
+			exifData = file
+		}
+		if exifData != nil {
+			exif, _ = parseTIFF(exifData)
+		}
+	}
+
+	if exif != nil {
+		addExifDetails(details, exif, format)
+	}
+
+	return &ExtractedContent{
+		Category: "image",
+		Preview:  imagePreview(details),
+		Details:  details,
+	}, nil
+}
+
+// imageFormat identifies an image by its magic bytes
+func imageFormat(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("\xFF\xD8\xFF")):
+		return "JPEG"
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1A\n")):
+		return "PNG"
+	case bytes.HasPrefix(header, []byte("GIF8")):
+		return "GIF"
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return "WebP"
+	case bytes.HasPrefix(header, []byte("II*\x00")), bytes.HasPrefix(header, []byte("MM\x00*")):
+		return "TIFF"
+	case len(header) >= 12 && string(header[4:8]) == "ftyp" && heifBrands[string(header[8:12])]:
+		return "HEIC"
+	}
+	return ""
+}
+
+// colorModelName describes the color model image.DecodeConfig reports
+func colorModelName(m color.Model) string {
+	if _, ok := m.(color.Palette); ok {
+		return "indexed"
+	}
+	switch m {
+	case color.GrayModel, color.Gray16Model:
+		return "grayscale"
+	case color.YCbCrModel, color.RGBAModel, color.RGBA64Model:
+		return "RGB"
+	case color.NRGBAModel, color.NRGBA64Model, color.NYCbCrAModel:
+		return "RGBA"
+	case color.CMYKModel:
+		return "CMYK"
+	}
+	return ""
+}
+
+// addExifDetails copies the EXIF fields that were present into details
+func addExifDetails(details map[string]any, exif *exifInfo, format string) {
+	if camera := cameraName(exif.make, exif.model); camera != "" {
+		details["camera"] = camera
+	}
+	if exif.lens != "" {
+		details["lens"] = exif.lens
+	}
+	if !exif.taken.IsZero() {
+		details["taken"] = exif.taken.Format("2006-01-02 15:04:05")
+	}
+	if o, ok := exifOrientations[exif.orientation]; ok {
+		details["orientation"] = o
+	}
+	if exif.hasGPS {
+		details["gps_latitude"] = math.Round(exif.latitude*1e6) / 1e6
+		details["gps_longitude"] = math.Round(exif.longitude*1e6) / 1e6
+	}
+	if exif.software != "" {
+		details["software"] = exif.software
+	}
+
+	// A TIFF file's own IFD0 describes its pixels
+	if format == "TIFF" {
+		if mode, ok := tiffPhotometric[exif.photometric]; ok && exif.hasColor {
+			details["color_mode"] = mode
+		}
+		if _, ok := details["width"]; !ok && exif.width > 0 {
+			details["width"], details["height"] = int(exif.width), int(exif.height)
+		}
+	}
+}
+
+// cameraName joins the EXIF make and model, which often repeats the make
+// (e.g. "Canon" and "Canon EOS R5")
+func cameraName(maker, model string) string {
+	if model == "" {
+		return maker
+	}
+	if maker == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(strings.Fields(maker)[0])) {
+		return model
+	}
+	return maker + " " + model
+}
+
+// imagePreview summarises an image in one line, e.g.
+// "JPEG 4032x3024, Canon EOS R5, taken 2023-07-14"
+func imagePreview(details map[string]any) string {
+	parts := []string{details["format"].(string)}
+	if w, ok := details["width"].(int); ok {
+		parts[0] += fmt.Sprintf(" %dx%d", w, details["height"])
+	}
+	if camera, ok := details["camera"].(string); ok {
+		parts = append(parts, camera)
+	}
+	if taken, ok := details["taken"].(string); ok {
+		parts = append(parts, "taken "+taken[:10])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// jpegExif walks the JPEG markers before the image data and returns the
+// TIFF structure inside the APP1 "Exif" segment
+func jpegExif(r io.ReaderAt) io.ReaderAt {
+	offset := int64(2)
+	marker := make([]byte, 4)
+	for {
+		if _, err := r.ReadAt(marker, offset); err != nil || marker[0] != 0xFF {
+			return nil
+		}
+		// Start of scan: the metadata segments are all behind us
+		if marker[1] == 0xDA || marker[1] == 0xD9 {
+			return nil
+		}
+		length := int64(binary.BigEndian.Uint16(marker[2:]))
+		if length < 2 {
+			return nil
+		}
+		if marker[1] == 0xE1 && length > 8 {
+			id := make([]byte, 6)
+			if _, err := r.ReadAt(id, offset+4); err == nil && string(id) == "Exif\x00\x00" {
+				return io.NewSectionReader(r, offset+10, length-8)
+			}
+		}
+		offset += 2 + length
+	}
+}
+
+// pngExif returns the contents of a PNG eXIf chunk
+func pngExif(r io.ReaderAt) io.ReaderAt {
+	offset := int64(8)
+	chunk := make([]byte, 8)
+	for {
+		if _, err := r.ReadAt(chunk, offset); err != nil {
+			return nil
+		}
+		length := int64(binary.BigEndian.Uint32(chunk))
+		switch string(chunk[4:]) {
+		case "eXIf":
+			return io.NewSectionReader(r, offset+8, length)
+		case "IEND":
+			return nil
+		}
+		offset += 12 + length
+	}
+}
+
+// webpExif returns the contents of a WebP EXIF chunk
+func webpExif(r io.ReaderAt) io.ReaderAt {
+	offset := int64(12)
+	chunk := make([]byte, 8)
+	for {
+		if _, err := r.ReadAt(chunk, offset); err != nil {
+			return nil
+		}
+		length := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		if string(chunk[:4]) == "EXIF" {
+			start := offset + 8
+			// Some writers keep JPEG's "Exif\0\0" prefix
+			id := make([]byte, 6)
+			if _, err := r.ReadAt(id, start); err == nil && string(id) == "Exif\x00\x00" {
+				start, length = start+6, length-6
+			}
+			return io.NewSectionReader(r, start, length)
+		}
+		// Chunks are padded to an even size
+		offset += 8 + length + length%2
+	}
+}
+
+// readHEIF reads the primary image size and the EXIF item from a HEIF
+// file's meta box
+func readHEIF(r io.ReaderAt) (width, height uint32, exif io.ReaderAt) {
+	var meta []byte
+	offset := int64(0)
+	header := make([]byte, 16)
+	for meta == nil {
+		n, _ := r.ReadAt(header, offset)
+		if n < 8 {
+			return 0, 0, nil
+		}
+		size := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		if size == 1 && n == 16 {
+			size, headerSize = int64(binary.BigEndian.Uint64(header[8:])), 16
+		}
+		if size < headerSize {
+			return 0, 0, nil
+		}
+		if string(header[4:8]) == "meta" {
+			if size > maxHEIFMetaSize {
+				return 0, 0, nil
+			}
+			meta = make([]byte, size-headerSize)
+			if _, err := r.ReadAt(meta, offset+headerSize); err != nil {
+				return 0, 0, nil
+			}
+		}
+		offset += size
+	}
+
+	// meta is a full box: skip its version and flags
+	if len(meta) < 4 {
+		return 0, 0, nil
+	}
+
+	var exifItem uint32
+	var locations map[uint32][2]uint64
+	forEachBox(meta[4:], func(typ string, payload []byte) {
+		switch typ {
+		case "iinf":
+			exifItem = heifExifItem(payload)
+		case "iloc":
+			locations = heifItemLocations(payload)
+		case "iprp":
+			forEachBox(payload, func(typ string, payload []byte) {
+				if typ != "ipco" {
+					return
+				}
+				forEachBox(payload, func(typ string, payload []byte) {
+					// Thumbnails and grid tiles have their own ispe; the
+					// primary image is the largest
+					if typ == "ispe" && len(payload) >= 12 {
+						w, h := binary.BigEndian.Uint32(payload[4:]), binary.BigEndian.Uint32(payload[8:])
+						if uint64(w)*uint64(h) > uint64(width)*uint64(height) {
+							width, height = w, h
+						}
+					}
+				})
+			})
+		}
+	})
+
+	loc, ok := locations[exifItem]
+	if exifItem == 0 || !ok || loc[1] < 4 {
+		return width, height, nil
+	}
+	// The Exif item starts with the offset of the TIFF header within it
+	skip := make([]byte, 4)
+	if _, err := r.ReadAt(skip, int64(loc[0])); err != nil {
+		return width, height, nil
+	}
+	start := int64(loc[0]) + 4 + int64(binary.BigEndian.Uint32(skip))
+	return width, height, io.NewSectionReader(r, start, int64(loc[0]+loc[1])-start)
+}
+
+// forEachBox calls fn with the type and payload of each ISO BMFF box in data
+func forEachBox(data []byte, fn func(typ string, payload []byte)) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		headerSize := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size, headerSize = binary.BigEndian.Uint64(data[8:]), 16
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return
+		}
+		fn(string(data[4:8]), data[headerSize:size])
+		data = data[size:]
+	}
+}
+
+// heifExifItem returns the ID of the Exif item listed in an iinf box
+func heifExifItem(iinf []byte) uint32 {
+	// Version 0 has a 16-bit entry count, later versions a 32-bit one
+	headerSize := 6
+	if len(iinf) > 0 && iinf[0] > 0 {
+		headerSize = 8
+	}
+	if len(iinf) < headerSize {
+		return 0
+	}
+	entries := iinf[headerSize:]
+
+	var item uint32
+	forEachBox(entries, func(typ string, infe []byte) {
+		if typ != "infe" || len(infe) < 4 || item != 0 {
+			return
+		}
+		switch version := infe[0]; {
+		case version == 2 && len(infe) >= 12 && string(infe[8:12]) == "Exif":
+			item = uint32(binary.BigEndian.Uint16(infe[4:]))
+		case version == 3 && len(infe) >= 14 && string(infe[10:14]) == "Exif":
+			item = binary.BigEndian.Uint32(infe[4:])
+		}
+	})
+	return item
+}
+
+// heifItemLocations maps item IDs to the file offset and length of their
+// first extent, as listed in an iloc box
+func heifItemLocations(iloc []byte) map[uint32][2]uint64 {
+	locations := make(map[uint32][2]uint64)
+	if len(iloc) < 8 {
+		return locations
+	}
+	version := iloc[0]
+	offsetSize, lengthSize := int(iloc[4]>>4), int(iloc[4]&0x0F)
+	baseSize, indexSize := int(iloc[5]>>4), int(iloc[5]&0x0F)
+	if version == 0 {
+		indexSize = 0
+	}
+
+	p := 6
+	// read returns the next n-byte big-endian field, or false past the end
+	read := func(n int) (uint64, bool) {
+		if p+n > len(iloc) {
+			return 0, false
+		}
+		var v uint64
+		for _, b := range iloc[p : p+n] {
+			v = v<<8 | uint64(b)
+		}
+		p += n
+		return v, true
+	}
+
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	count, ok := read(idSize)
+	for i := uint64(0); ok && i < count; i++ {
+		var id, method, base, extents uint64
+		if id, ok = read(idSize); !ok {
+			break
+		}
+		if version > 0 {
+			if method, ok = read(2); !ok {
+				break
+			}
+		}
+		if _, ok = read(2); !ok { // data reference index
+			break
+		}
+		if base, ok = read(baseSize); !ok {
+			break
+		}
+		if extents, ok = read(2); !ok {
+			break
+		}
+		for j := uint64(0); j < extents; j++ {
+			var off, length uint64
+			if _, ok = read(indexSize); !ok {
+				break
+			}
+			if off, ok = read(offsetSize); !ok {
+				break
+			}
+			if length, ok = read(lengthSize); !ok {
+				break
+			}
+			// Only items stored in the file itself (construction method 0) can be read directly
+			if j == 0 && method&0x0F == 0 {
+				locations[uint32(id)] = [2]uint64{base + off, length}
+			}
+		}
+	}
+	return locations
+}
@@ -0,0 +1,402 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+)
+
+// tiffField is an IFD entry for buildTIFF; ASCII values are written with
+// their NUL terminator, out of line when longer than 4 bytes
+type tiffField struct {
+	tag   uint16
+	typ   uint16
+	value any // string for ASCII, uint32 for SHORT/LONG, []uint32 numerator/denominator pairs for RATIONAL
+}
+
+// buildTIFF lays out a TIFF structure with IFD0, plus an Exif IFD and a GPS
+// IFD when their fields are given. IFD0 gets the pointers to them.
+func buildTIFF(order binary.ByteOrder, ifd0, exif, gps []tiffField) []byte {
+	type ifd struct {
+		fields  []tiffField
+		pointer uint16
+	}
+	ifds := []ifd{{fields: ifd0}}
+	if len(exif) > 0 {
+		ifds = append(ifds, ifd{exif, tagExifIFD})
+	}
+	if len(gps) > 0 {
+		ifds = append(ifds, ifd{gps, tagGPSIFD})
+	}
+	for _, sub := range ifds[1:] {
+		ifds[0].fields = append(ifds[0].fields, tiffField{tag: sub.pointer, typ: 4})
+	}
+
+	// IFD offsets first: each IFD is 2 + 12n + 4 bytes, followed by its data
+	offsets := make([]uint32, len(ifds))
+	next := uint32(8)
+	for i, d := range ifds {
+		size := 2 + 12*uint32(len(d.fields)) + 4
+		for _, f := range d.fields {
+			if n := uint32(len(tiffValue(order, f))); n > 4 {
+				size += n
+			}
+		}
+		offsets[i] = next
+		next += size
+	}
+
+	out := make([]byte, next)
+	if order == binary.LittleEndian {
+		copy(out, "II*\x00")
+	} else {
+		copy(out, "MM\x00*")
+	}
+	order.PutUint32(out[4:], offsets[0])
+	for i, d := range ifds {
+		p := offsets[i]
+		data := p + 2 + 12*uint32(len(d.fields)) + 4
+		order.PutUint16(out[p:], uint16(len(d.fields)))
+		p += 2
+		for _, f := range d.fields {
+			for j, sub := range ifds {
+				if j > 0 && f.tag == sub.pointer && f.typ == 4 {
+					f.value = offsets[j]
+				}
+			}
+			value := tiffValue(order, f)
+			count := uint32(len(value))
+			switch f.typ {
+			case 3:
+				count /= 2
+			case 4:
+				count /= 4
+			case 5:
+				count /= 8
+			}
+			order.PutUint16(out[p:], f.tag)
+			order.PutUint16(out[p+2:], f.typ)
+			order.PutUint32(out[p+4:], count)
+			if len(value) <= 4 {
+				copy(out[p+8:], value)
+			} else {
+				order.PutUint32(out[p+8:], data)
+				copy(out[data:], value)
+				data += uint32(len(value))
+			}
+			p += 12
+		}
+	}
+	return out
+}
+
+// tiffValue encodes a field's value in the given byte order
+func tiffValue(byteOrder binary.ByteOrder, f tiffField) []byte {
+	order := byteOrder.(binary.AppendByteOrder)
+	switch v := f.value.(type) {
+	case string:
+		return append([]byte(v), 0)
+	case uint32:
+		if f.typ == 3 {
+			return order.AppendUint16(nil, uint16(v))
+		}
+		return order.AppendUint32(nil, v)
+	case []uint32:
+		var out []byte
+		for _, n := range v {
+			out = order.AppendUint32(out, n)
+		}
+		return out
+	}
+	return nil
+}
+
+// sampleTIFF is an EXIF block with a camera, a capture time and a GPS
+// position of 51°30'N 0°7'30"W
+func sampleTIFF(order binary.ByteOrder) []byte {
+	return buildTIFF(order,
+		[]tiffField{
+			{tagMake, 2, "Canon"},
+			{tagModel, 2, "Canon EOS R5"},
+			{tagOrientation, 3, uint32(6)},
+			{tagDateTime, 2, "2024:01:01 00:00:00"},
+		},
+		[]tiffField{{tagDateTimeOriginal, 2, "2023:06:15 14:30:05"}},
+		[]tiffField{
+			{tagGPSLatitudeRef, 2, "N"},
+			{tagGPSLatitude, 5, []uint32{51, 1, 30, 1, 0, 1}},
+			{tagGPSLongitudeRef, 2, "W"},
+			{tagGPSLongitude, 5, []uint32{0, 1, 7, 1, 30, 1}},
+		},
+	)
+}
+
+// checkSampleExif verifies the metadata of sampleTIFF
+func checkSampleExif(t *testing.T, info *exifInfo) {
+	t.Helper()
+	if info == nil {
+		t.Fatal("no EXIF metadata")
+	}
+	if info.make != "Canon" || info.model != "Canon EOS R5" {
+		t.Errorf("camera = %q %q, want Canon / Canon EOS R5", info.make, info.model)
+	}
+	if info.orientation != 6 {
+		t.Errorf("orientation = %d, want 6", info.orientation)
+	}
+	if want := time.Date(2023, 6, 15, 14, 30, 5, 0, time.UTC); !info.taken.Equal(want) {
+		t.Errorf("taken = %v, want %v (DateTimeOriginal)", info.taken, want)
+	}
+	if !info.hasGPS || info.latitude != 51.5 || info.longitude != -0.125 {
+		t.Errorf("GPS = %v %v,%v, want 51.5,-0.125", info.hasGPS, info.latitude, info.longitude)
+	}
+}
+
+func TestParseTIFF(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"little endian", sampleTIFF(binary.LittleEndian), false},
+		{"big endian", sampleTIFF(binary.BigEndian), false},
+		{"bad magic", append([]byte("XX*\x00"), sampleTIFF(binary.LittleEndian)[4:]...), true},
+		{"empty", nil, true},
+		{"header only", sampleTIFF(binary.LittleEndian)[:8], true},
+		{"IFD0 past the end", []byte("II*\x00\xFF\xFF\x00\x00"), true},
+		{"corrupt entry count", []byte("II*\x00\x08\x00\x00\x00\xFF\xFF"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseTIFF(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTIFF() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				checkSampleExif(t, info)
+			}
+		})
+	}
+}
+
+func TestParseTIFFTruncated(t *testing.T) {
+	data := sampleTIFF(binary.BigEndian)
+	for n := range data {
+		// Must not panic; out-of-range values are dropped
+		parseTIFF(bytes.NewReader(data[:n]))
+	}
+}
+
+// pngChunk builds a PNG chunk
+func pngChunk(typ string, data []byte) []byte {
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	out = append(out, typ...)
+	out = append(out, data...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[4:]))
+}
+
+// riffChunk builds a WebP chunk, padded to an even size
+func riffChunk(typ string, data []byte) []byte {
+	out := append([]byte(typ), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	out = append(out, data...)
+	if len(data)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// isoBox builds an ISO BMFF box
+func isoBox(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	out = append(out, typ...)
+	return append(out, body...)
+}
+
+// sampleJPEG is a JPEG with a JFIF APP0 segment before the Exif APP1 one
+func sampleJPEG(exif []byte) []byte {
+	out := []byte{0xFF, 0xD8}
+	out = append(out, 0xFF, 0xE0, 0x00, 0x10)
+	out = append(out, "JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"...)
+	segment := append([]byte("Exif\x00\x00"), exif...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(2+len(segment)))
+	out = append(out, segment...)
+	return append(out, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9)
+}
+
+// samplePNG is a PNG with an eXIf chunk after IHDR
+func samplePNG(exif []byte) []byte {
+	out := []byte("\x89PNG\r\n\x1a\n")
+	out = append(out, pngChunk("IHDR", []byte{0, 0, 0, 4, 0, 0, 0, 3, 8, 2, 0, 0, 0})...)
+	out = append(out, pngChunk("eXIf", exif)...)
+	return append(out, pngChunk("IEND", nil)...)
+}
+
+// sampleWebP is an extended WebP with an odd-sized chunk before the EXIF
+// chunk, so the padding is exercised
+func sampleWebP(exif []byte) []byte {
+	body := []byte("WEBP")
+	body = append(body, riffChunk("VP8X", make([]byte, 10))...)
+	body = append(body, riffChunk("ICCP", make([]byte, 3))...)
+	body = append(body, riffChunk("EXIF", exif)...)
+	out := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	return append(out, body...)
+}
+
+// sampleHEIF is a HEIF file whose meta box lists a 4032x3024 primary
+// image, a thumbnail and an Exif item stored after the meta box. iinfVersion
+// selects the 16-bit (0) or 32-bit (1) entry count.
+func sampleHEIF(exif []byte, iinfVersion byte) []byte {
+	ftyp := isoBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic"))
+
+	infe := isoBox("infe", []byte{2, 0, 0, 0, 0, 2, 0, 0}, []byte("Exif"))
+	iinf := []byte{iinfVersion, 0, 0, 0}
+	if iinfVersion == 0 {
+		iinf = append(iinf, 0, 1)
+	} else {
+		iinf = append(iinf, 0, 0, 0, 1)
+	}
+	ispe := func(w, h uint32) []byte {
+		return isoBox("ispe", []byte{0, 0, 0, 0}, binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, w), h))
+	}
+	iprp := isoBox("iprp", isoBox("ipco", ispe(320, 240), ispe(4032, 3024)))
+
+	// The iloc box has a fixed size, so the Exif item's offset is known
+	// before it is built: it follows the meta box
+	buildMeta := func(exifOffset uint32) []byte {
+		iloc := []byte{0, 0, 0, 0, 0x44, 0x00, 0, 1, 0, 2, 0, 0, 0, 1}
+		iloc = binary.BigEndian.AppendUint32(iloc, exifOffset)
+		iloc = binary.BigEndian.AppendUint32(iloc, uint32(4+len(exif)))
+		return isoBox("meta", []byte{0, 0, 0, 0},
+			isoBox("hdlr", make([]byte, 25)),
+			isoBox("iinf", iinf, infe),
+			isoBox("iloc", iloc),
+			iprp,
+		)
+	}
+	meta := buildMeta(0)
+	meta = buildMeta(uint32(len(ftyp) + len(meta) + 8))
+
+	item := append([]byte{0, 0, 0, 0}, exif...)
+	return bytes.Join([][]byte{ftyp, meta, isoBox("mdat", item)}, nil)
+}
+
+func TestEmbeddedExif(t *testing.T) {
+	exif := sampleTIFF(binary.LittleEndian)
+	tests := []struct {
+		name string
+		data []byte
+		find func(io.ReaderAt) io.ReaderAt
+	}{
+		{"JPEG", sampleJPEG(exif), jpegExif},
+		{"PNG", samplePNG(exif), pngExif},
+		{"WebP", sampleWebP(exif), webpExif},
+		{"WebP with Exif prefix", sampleWebP(append([]byte("Exif\x00\x00"), exif...)), webpExif},
+		{"HEIF", sampleHEIF(exif, 0), func(r io.ReaderAt) io.ReaderAt {
+			_, _, block := readHEIF(r)
+			return block
+		}},
+		{"HEIF with iinf version 1", sampleHEIF(exif, 1), func(r io.ReaderAt) io.ReaderAt {
+			_, _, block := readHEIF(r)
+			return block
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := tt.find(bytes.NewReader(tt.data))
+			if block == nil {
+				t.Fatal("EXIF block not found")
+			}
+			info, err := parseTIFF(block)
+			if err != nil {
+				t.Fatalf("parseTIFF() error = %v", err)
+			}
+			checkSampleExif(t, info)
+		})
+
+		t.Run(tt.name+" truncated", func(t *testing.T) {
+			for n := range tt.data {
+				// Must not panic; a cut-off block fails to parse instead
+				if block := tt.find(bytes.NewReader(tt.data[:n])); block != nil {
+					parseTIFF(block)
+				}
+			}
+		})
+	}
+}
+
+func TestReadHEIFSize(t *testing.T) {
+	width, height, _ := readHEIF(bytes.NewReader(sampleHEIF(sampleTIFF(binary.BigEndian), 0)))
+	if width != 4032 || height != 3024 {
+		t.Errorf("readHEIF() size = %dx%d, want the primary image's 4032x3024", width, height)
+	}
+}
+
+func TestHEIFExifItem(t *testing.T) {
+	infe := isoBox("infe", []byte{2, 0, 0, 0, 0, 7, 0, 0}, []byte("Exif"))
+	infe3 := isoBox("infe", []byte{3, 0, 0, 0, 0, 0, 1, 0, 0, 0}, []byte("Exif"))
+	tests := []struct {
+		name string
+		iinf []byte
+		want uint32
+	}{
+		{"version 0", append([]byte{0, 0, 0, 0, 0, 1}, infe...), 7},
+		{"version 1", append([]byte{1, 0, 0, 0, 0, 0, 0, 1}, infe...), 7},
+		{"infe version 3", append([]byte{1, 0, 0, 0, 0, 0, 0, 1}, infe3...), 256},
+		{"empty", nil, 0},
+		{"version 0 truncated", []byte{0, 0, 0, 0, 0}, 0},
+		// Version 1 has a 32-bit entry count; 6 or 7 bytes must not be sliced at 8
+		{"version 1 with 6 bytes", []byte{1, 0, 0, 0, 0, 0}, 0},
+		{"version 1 with 7 bytes", []byte{1, 0, 0, 0, 0, 0, 0}, 0},
+		{"truncated infe", append([]byte{0, 0, 0, 0, 0, 1}, infe[:12]...), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := heifExifItem(tt.iinf); got != tt.want {
+				t.Errorf("heifExifItem() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHEIFItemLocations(t *testing.T) {
+	tests := []struct {
+		name string
+		iloc []byte
+		want map[uint32][2]uint64
+	}{
+		{
+			"version 0",
+			[]byte{0, 0, 0, 0, 0x44, 0x00, 0, 1, 0, 3, 0, 0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0x20},
+			map[uint32][2]uint64{3: {256, 32}},
+		},
+		{
+			"version 1 with base offset",
+			[]byte{1, 0, 0, 0, 0x44, 0x40, 0, 1, 0, 3, 0, 0, 0, 0, 0, 0, 0x10, 0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0x20},
+			map[uint32][2]uint64{3: {0x1000 + 256, 32}},
+		},
+		{
+			"construction method 1 is skipped",
+			[]byte{1, 0, 0, 0, 0x44, 0x00, 0, 1, 0, 3, 0, 1, 0, 0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0x20},
+			map[uint32][2]uint64{},
+		},
+		{"truncated", []byte{0, 0, 0, 0, 0x44, 0x00, 0, 1, 0, 3, 0, 0, 0, 1, 0, 0}, map[uint32][2]uint64{}},
+		{"empty", nil, map[uint32][2]uint64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := heifItemLocations(tt.iloc)
+			if len(got) != len(tt.want) {
+				t.Fatalf("heifItemLocations() = %v, want %v", got, tt.want)
+			}
+			for id, loc := range tt.want {
+				if got[id] != loc {
+					t.Errorf("heifItemLocations()[%d] = %v, want %v", id, got[id], loc)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package extractor
+
+import "strings"
+
+// commentSyntax describes how a language writes comments, for counting
+// comment lines
+type commentSyntax struct {
+	line   []string    // line comment markers
+	blocks [][2]string // block comment delimiters (Python docstrings count as comments, as in cloc)
+	quotes string      // characters that delimit strings, whose contents are code
+}
+
+var cStyleComments = commentSyntax{line: []string{"//"}, blocks: [][2]string{{"/*", "*/"}}, quotes: `"`}
+
+// commentSyntaxes lists the comment syntax of each language in
+// codeLanguages and shebangInterpreters
+var commentSyntaxes = map[string]commentSyntax{
+	"go":         {line: []string{"//"}, blocks: [][2]string{{"/*", "*/"}}, quotes: "\"`"},
+	"javascript": {line: []string{"//"}, blocks: [][2]string{{"/*", "*/"}}, quotes: "\"'`"},
+	"typescript": {line: []string{"//"}, blocks: [][2]string{{"/*", "*/"}}, quotes: "\"'`"},
+	"java":       cStyleComments,
+	"rust":       cStyleComments,
+	"c":          cStyleComments,
+	"cpp":        cStyleComments,
+	"csharp":     cStyleComments,
+	"kotlin":     cStyleComments,
+	"swift":      cStyleComments,
+	"dart":       {line: []string{"//"}, blocks: [][2]string{{"/*", "*/"}}, quotes: `"'`},
+	"php":        {line: []string{"//", "#"}, blocks: [][2]string{{"/*", "*/"}}, quotes: `"'`},
+	"python":     {line: []string{"#"}, blocks: [][2]string{{`"""`, `"""`}, {`'''`, `'''`}}, quotes: `"'`},
+	"ruby":       {line: []string{"#"}, blocks: [][2]string{{"=begin", "=end"}}, quotes: `"'`},
+	"shell":      {line: []string{"#"}, quotes: `"'`},
+}
+
+// countLines counts the blank, comment and code lines of source code the
+// way cloc does: a line with any code on it is a code line, a line holding
+// only comments is a comment line. ok is false for languages whose comment
+// syntax is unknown.
+func countLines(language, text string) (counts map[string]int, ok bool) {
+	syntax, ok := commentSyntaxes[language]
+	if !ok {
+		return nil, false
+	}
+	counts = map[string]int{"blank": 0, "comment": 0, "code": 0}
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return counts, true
+	}
+
+	inBlock := "" // delimiter closing the open block comment
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			counts["blank"]++
+			continue
+		}
+		hasCode := false
+	scan:
+		for i := 0; i < len(line); {
+			if inBlock != "" {
+				end := strings.Index(line[i:], inBlock)
+				if end < 0 {
+					break
+				}
+				i += end + len(inBlock)
+				inBlock = ""
+				continue
+			}
+			rest := line[i:]
+			for _, marker := range syntax.line {
+				if strings.HasPrefix(rest, marker) {
+					break scan
+				}
+			}
+			for _, block := range syntax.blocks {
+				if strings.HasPrefix(rest, block[0]) {
+					inBlock = block[1]
+					i += len(block[0])
+					continue scan
+				}
+			}
+			if line[i] == ' ' || line[i] == '\t' {
+				i++
+				continue
+			}
+			hasCode = true
+			if quote := line[i]; strings.IndexByte(syntax.quotes, quote) >= 0 {
+				// Skip the string so comment markers inside it are ignored
+				i++
+				for i < len(line) && line[i] != quote {
+					if line[i] == '\\' {
+						i++
+					}
+					i++
+				}
+			}
+			i++
+		}
+		if hasCode {
+			counts["code"]++
+		} else {
+			counts["comment"]++
+		}
+	}
+	return counts, true
+}
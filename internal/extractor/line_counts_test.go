@@ -0,0 +1,53 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		text     string
+		want     map[string]int
+	}{
+		{"go", "go", `package main
+
+// main says hello
+func main() {
+	/* block
+	   comment */
+	fmt.Println("// not a comment") // trailing
+}
+`, map[string]int{"blank": 1, "comment": 3, "code": 4}},
+		{"code after block comment", "c", "/* x */ int y;\n/* a */ /* b */\n", map[string]int{"blank": 0, "comment": 1, "code": 1}},
+		{"python docstring", "python", `def f():
+    """Docstring
+    spans lines."""
+    return "# not a comment"  # real comment
+`, map[string]int{"blank": 0, "comment": 2, "code": 2}},
+		{"shell", "shell", "#!/bin/sh\n\necho 'a # b'\n# done\n", map[string]int{"blank": 1, "comment": 2, "code": 1}},
+		{"escaped quote", "javascript", "const s = \"a\\\" // b\";\n", map[string]int{"blank": 0, "comment": 0, "code": 1}},
+		{"go raw string", "go", "s := `/* not`\nx := 1\n", map[string]int{"blank": 0, "comment": 0, "code": 2}},
+		{"ruby block", "ruby", "=begin\ndocs\n=end\nputs 1\n", map[string]int{"blank": 0, "comment": 3, "code": 1}},
+		{"php hash comment", "php", "<?php\n# note\necho 1;\n", map[string]int{"blank": 0, "comment": 1, "code": 2}},
+		{"unclosed block", "java", "/* never\nclosed\n", map[string]int{"blank": 0, "comment": 2, "code": 0}},
+		{"empty", "go", "", map[string]int{"blank": 0, "comment": 0, "code": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := countLines(tt.language, tt.text)
+			if !ok {
+				t.Fatalf("countLines(%s) not supported", tt.language)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("countLines = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := countLines("cobol", "DISPLAY 'HI'."); ok {
+		t.Error("countLines reported counts for a language without comment syntax")
+	}
+}
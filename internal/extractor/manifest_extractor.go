@@ -0,0 +1,1012 @@
+package extractor
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestExtractor reads package manifests (go.mod, package.json,
+// pyproject.toml, Cargo.toml, pom.xml, build.gradle, Gemfile, composer.json,
+// pubspec.yaml, ...) and their lockfiles: the module name, the language
+// version it needs, its direct dependencies, its scripts and targets, and
+// the frameworks those dependencies point to
+type ManifestExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+const (
+	maxManifestDeps    = 40 // dependencies kept per list
+	maxManifestScripts = 20
+)
+
+// manifest is what every manifest parser fills in
+type manifest struct {
+	ecosystem       string
+	name            string
+	version         string
+	languageVersion string
+	dependencies    []string
+	devDependencies []string
+	scripts         map[string]string // npm, Composer, Pipfile and pyproject scripts
+	targets         []string          // binaries, executables and build tasks
+	plugins         []string          // Gradle and Maven build plugins
+	members         []string          // workspace members or Maven modules
+	lockedPackages  int               // lockfiles only
+	lockfile        bool
+	parent          string // Maven parent POM, e.g. org.springframework.boot:spring-boot-starter-parent
+}
+
+// manifestParsers map lowercase file names to their parsers
+var manifestParsers = map[string]func([]byte) (*manifest, error){
+	"go.mod":              parseGoMod,
+	"go.sum":              parseGoSum,
+	"package.json":        parsePackageJSON,
+	"package-lock.json":   parsePackageLock,
+	"npm-shrinkwrap.json": parsePackageLock,
+	"yarn.lock":           parseYarnLock,
+	"pnpm-lock.yaml":      parsePnpmLock,
+	"requirements.txt":    parseRequirements,
+	"pyproject.toml":      parsePyproject,
+	"pipfile":             parsePipfile,
+	"pipfile.lock":        parsePipfileLock,
+	"poetry.lock":         tomlPackageLock("python"),
+	"cargo.toml":          parseCargo,
+	"cargo.lock":          tomlPackageLock("cargo"),
+	"pom.xml":             parsePOM,
+	"build.gradle":        parseGradle,
+	"build.gradle.kts":    parseGradle,
+	"gemfile":             parseGemfile,
+	"gemfile.lock":        parseGemfileLock,
+	"composer.json":       parseComposer,
+	"composer.lock":       parseComposerLock,
+	"pubspec.yaml":        parsePubspec,
+	"pubspec.lock":        parsePubspecLock,
+}
+
+// manifestLabels name each ecosystem in previews
+var manifestLabels = map[string]string{
+	"go":       "Go module",
+	"npm":      "npm package",
+	"python":   "Python project",
+	"cargo":    "Rust crate",
+	"maven":    "Maven project",
+	"gradle":   "Gradle build",
+	"ruby":     "Ruby bundle",
+	"composer": "Composer package",
+	"dart":     "Dart package",
+}
+
+// IsManifestFile reports whether ManifestExtractor understands a file,
+// judging by its name alone (go.mod, Gemfile and Pipfile have no telling
+// extension)
+//
+// Parameters:
+//   - name: File name or path
+//
+// Returns:
+//   - bool: true for package manifests and lockfiles
+func IsManifestFile(name string) bool {
+	_, ok := manifestParser(name)
+	return ok
+}
+
+// manifestParser returns the parser for a file name; requirements-dev.txt
+// and similar files read like requirements.txt
+func manifestParser(name string) (func([]byte) (*manifest, error), bool) {
+	base := strings.ToLower(path.Base(name))
+	if parse, ok := manifestParsers[base]; ok {
+		return parse, true
+	}
+	if strings.HasPrefix(base, "requirements") && path.Ext(base) == ".txt" {
+		return parseRequirements, true
+	}
+	return nil, false
+}
+
+// Extract parses a package manifest or lockfile
+func (e ManifestExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	parse, ok := manifestParser(name)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a known package manifest", path.Base(name))
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path.Base(name), err)
+	}
+
+	details := map[string]any{"type": "manifest", "ecosystem": m.ecosystem}
+	for key, value := range map[string]string{
+		"module":           m.name,
+		"version":          m.version,
+		"language_version": m.languageVersion,
+		"parent":           m.parent,
+	} {
+		if value != "" {
+			details[key] = value
+		}
+	}
+	if m.lockfile {
+		details["lockfile"] = true
+		details["locked_packages"] = m.lockedPackages
+	} else {
+		details["dependency_count"] = len(m.dependencies)
+		details["dev_dependency_count"] = len(m.devDependencies)
+	}
+	for key, list := range map[string][]string{
+		"dependencies":      m.dependencies,
+		"dev_dependencies":  m.devDependencies,
+		"targets":           m.targets,
+		"plugins":           m.plugins,
+		"workspace_members": m.members,
+	} {
+		if len(list) > 0 {
+			details[key] = list[:min(len(list), maxManifestDeps)]
+		}
+	}
+	if len(m.scripts) > 0 {
+		scripts := make(map[string]string, min(len(m.scripts), maxManifestScripts))
+		for _, key := range firstSorted(keySet(m.scripts), maxManifestScripts) {
+			scripts[key] = m.scripts[key]
+		}
+		details["scripts"] = scripts
+	}
+	frameworks := m.frameworks()
+	if len(frameworks) > 0 {
+		details["frameworks"] = frameworks
+	}
+
+	summary := m.summary()
+	if len(frameworks) > 0 {
+		summary += "\nFrameworks: " + strings.Join(frameworks, ", ")
+	}
+	return &ExtractedContent{
+		Category: "config",
+		Preview:  truncatePreview(summary+"\n\n"+string(data), previewLimit(e.Limit)),
+		Lines:    bytes.Count(data, []byte("\n")) + 1,
+		Details:  details,
+	}, nil
+}
+
+// summary is the first preview line, e.g.
+// "Go module github.com/acme/api (go 1.22): 12 dependencies"
+func (m *manifest) summary() string {
+	label := manifestLabels[m.ecosystem]
+	if m.lockfile {
+		return fmt.Sprintf("%s lockfile: %s", label, plural(m.lockedPackages, "locked package"))
+	}
+	if m.name != "" {
+		label += " " + m.name
+	}
+	if m.version != "" {
+		label += " " + m.version
+	}
+	if m.languageVersion != "" {
+		label += fmt.Sprintf(" (%s)", m.languageVersion)
+	}
+	counts := fmt.Sprintf("%d dependencies", len(m.dependencies))
+	if len(m.dependencies) == 1 {
+		counts = "1 dependency"
+	}
+	if len(m.devDependencies) > 0 {
+		counts += fmt.Sprintf(", %d for development", len(m.devDependencies))
+	}
+	return label + ": " + counts
+}
+
+// frameworks returns the well-known frameworks among the dependencies and
+// build plugins, in the order they are listed in frameworkRules
+func (m *manifest) frameworks() []string {
+	candidates := slices.Concat(m.dependencies, m.devDependencies, m.plugins)
+	if m.parent != "" {
+		candidates = append(candidates, m.parent)
+	}
+
+	var found []string
+	seen := make(map[string]bool)
+	for _, rule := range frameworkRules[m.ecosystem] {
+		for _, dep := range candidates {
+			if rule.matches(m.ecosystem, dep) && !seen[rule.name] {
+				seen[rule.name] = true
+				found = append(found, rule.name)
+			}
+		}
+	}
+	return found
+}
+
+// frameworkRule maps a dependency (or dependency prefix) to a framework
+type frameworkRule struct {
+	dep  string
+	name string
+}
+
+// matches compares a dependency with the rule: Go module paths match with
+// their major version suffix, Maven and Gradle coordinates by prefix, and
+// everything else by name
+func (r frameworkRule) matches(ecosystem, dep string) bool {
+	switch ecosystem {
+	case "go":
+		return dep == r.dep || strings.HasPrefix(dep, r.dep+"/")
+	case "maven", "gradle":
+		return strings.HasPrefix(dep, r.dep)
+	case "python":
+		return normalizePythonName(dep) == r.dep
+	}
+	return strings.EqualFold(dep, r.dep)
+}
+
+// frameworkRules lists the frameworks and major libraries worth naming in
+// a report, per ecosystem
+var frameworkRules = map[string][]frameworkRule{
+	"go": {
+		{"github.com/gin-gonic/gin", "Gin"},
+		{"github.com/labstack/echo", "Echo"},
+		{"github.com/gofiber/fiber", "Fiber"},
+		{"github.com/go-chi/chi", "chi"},
+		{"github.com/gorilla/mux", "Gorilla mux"},
+		{"google.golang.org/grpc", "gRPC"},
+		{"gorm.io/gorm", "GORM"},
+		{"entgo.io/ent", "Ent"},
+		{"github.com/spf13/cobra", "Cobra"},
+		{"github.com/urfave/cli", "urfave/cli"},
+		{"github.com/charmbracelet/bubbletea", "Bubble Tea"},
+		{"k8s.io/client-go", "Kubernetes client-go"},
+		{"sigs.k8s.io/controller-runtime", "controller-runtime"},
+	},
+	"npm": {
+		{"next", "Next.js"},
+		{"react", "React"},
+		{"react-native", "React Native"},
+		{"nuxt", "Nuxt"},
+		{"vue", "Vue"},
+		{"@angular/core", "Angular"},
+		{"@sveltejs/kit", "SvelteKit"},
+		{"svelte", "Svelte"},
+		{"astro", "Astro"},
+		{"gatsby", "Gatsby"},
+		{"@remix-run/react", "Remix"},
+		{"solid-js", "Solid"},
+		{"express", "Express"},
+		{"@nestjs/core", "NestJS"},
+		{"fastify", "Fastify"},
+		{"koa", "Koa"},
+		{"electron", "Electron"},
+		{"@prisma/client", "Prisma"},
+		{"tailwindcss", "Tailwind CSS"},
+		{"typescript", "TypeScript"},
+	},
+	"python": {
+		{"django", "Django"},
+		{"flask", "Flask"},
+		{"fastapi", "FastAPI"},
+		{"streamlit", "Streamlit"},
+		{"celery", "Celery"},
+		{"sqlalchemy", "SQLAlchemy"},
+		{"scrapy", "Scrapy"},
+		{"torch", "PyTorch"},
+		{"tensorflow", "TensorFlow"},
+		{"jax", "JAX"},
+		{"transformers", "Hugging Face Transformers"},
+		{"langchain", "LangChain"},
+		{"scikit-learn", "scikit-learn"},
+		{"pandas", "pandas"},
+	},
+	"cargo": {
+		{"actix-web", "Actix Web"},
+		{"axum", "Axum"},
+		{"rocket", "Rocket"},
+		{"warp", "warp"},
+		{"tokio", "Tokio"},
+		{"tauri", "Tauri"},
+		{"bevy", "Bevy"},
+		{"leptos", "Leptos"},
+		{"yew", "Yew"},
+		{"diesel", "Diesel"},
+		{"sqlx", "SQLx"},
+		{"clap", "clap"},
+	},
+	"maven": {
+		{"org.springframework.boot", "Spring Boot"},
+		{"org.springframework:", "Spring"},
+		{"io.quarkus", "Quarkus"},
+		{"io.micronaut", "Micronaut"},
+		{"io.ktor", "Ktor"},
+		{"org.hibernate", "Hibernate"},
+		{"org.apache.spark", "Apache Spark"},
+	},
+	"gradle": {
+		{"com.android.application", "Android"},
+		{"com.android.library", "Android"},
+		{"org.springframework.boot", "Spring Boot"},
+		{"org.springframework:", "Spring"},
+		{"io.quarkus", "Quarkus"},
+		{"io.micronaut", "Micronaut"},
+		{"io.ktor", "Ktor"},
+		{"androidx.compose", "Jetpack Compose"},
+		{"org.jetbrains.kotlin", "Kotlin"},
+		{"org.hibernate", "Hibernate"},
+	},
+	"ruby": {
+		{"rails", "Ruby on Rails"},
+		{"sinatra", "Sinatra"},
+		{"hanami", "Hanami"},
+		{"sidekiq", "Sidekiq"},
+		{"jekyll", "Jekyll"},
+	},
+	"composer": {
+		{"laravel/framework", "Laravel"},
+		{"symfony/framework-bundle", "Symfony"},
+		{"slim/slim", "Slim"},
+		{"cakephp/cakephp", "CakePHP"},
+		{"yiisoft/yii2", "Yii"},
+		{"drupal/core", "Drupal"},
+	},
+	"dart": {
+		{"flutter", "Flutter"},
+		{"flutter_bloc", "Bloc"},
+		{"flutter_riverpod", "Riverpod"},
+		{"provider", "Provider"},
+		{"get", "GetX"},
+		{"shelf", "Shelf"},
+	},
+}
+
+// parseGoMod reads a go.mod: the module path, the go directive and the
+// direct requirements (those not marked // indirect)
+func parseGoMod(data []byte) (*manifest, error) {
+	m := &manifest{ecosystem: "go"}
+	inRequire := false
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		indirect := strings.HasSuffix(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inRequire && fields[0] == ")":
+			inRequire = false
+		case inRequire:
+			if !indirect {
+				m.dependencies = append(m.dependencies, fields[0])
+			}
+		case fields[0] == "module" && len(fields) > 1:
+			m.name = strings.Trim(fields[1], `"`)
+		case fields[0] == "go" && len(fields) > 1:
+			m.languageVersion = "go " + fields[1]
+		case fields[0] == "tool" && len(fields) > 1:
+			m.targets = append(m.targets, fields[1])
+		case fields[0] == "require" && len(fields) > 1:
+			if fields[1] == "(" {
+				inRequire = true
+			} else if !indirect {
+				m.dependencies = append(m.dependencies, fields[1])
+			}
+		}
+	}
+	if m.name == "" {
+		return nil, fmt.Errorf("no module directive")
+	}
+	return m, nil
+}
+
+// parseGoSum counts the modules a go.sum pins
+func parseGoSum(data []byte) (*manifest, error) {
+	modules := make(map[string]bool)
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 3 {
+			modules[fields[0]] = true
+		}
+	}
+	return &manifest{ecosystem: "go", lockfile: true, lockedPackages: len(modules)}, nil
+}
+
+// parsePackageJSON reads an npm package.json
+func parsePackageJSON(data []byte) (*manifest, error) {
+	var pkg struct {
+		Name            string            `json:"name"`
+		Version         string            `json:"version"`
+		Engines         map[string]string `json:"engines"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+		Scripts         map[string]string `json:"scripts"`
+		Bin             json.RawMessage   `json:"bin"`
+		Workspaces      json.RawMessage   `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	m := &manifest{
+		ecosystem:       "npm",
+		name:            pkg.Name,
+		version:         pkg.Version,
+		dependencies:    sortedKeys(pkg.Dependencies),
+		devDependencies: sortedKeys(pkg.DevDependencies),
+		scripts:         pkg.Scripts,
+	}
+	if node := pkg.Engines["node"]; node != "" {
+		m.languageVersion = "node " + node
+	}
+
+	// "bin" is a path (named after the package) or a map of commands
+	var bins map[string]string
+	if json.Unmarshal(pkg.Bin, &bins) == nil {
+		m.targets = sortedKeys(bins)
+	} else if pkg.Name != "" && len(pkg.Bin) > 0 {
+		m.targets = []string{path.Base(pkg.Name)}
+	}
+
+	// "workspaces" is a list of globs or {"packages": [...]}
+	var workspaces struct {
+		Packages []string `json:"packages"`
+	}
+	if json.Unmarshal(pkg.Workspaces, &m.members) != nil && json.Unmarshal(pkg.Workspaces, &workspaces) == nil {
+		m.members = workspaces.Packages
+	}
+	return m, nil
+}
+
+// parsePackageLock counts the packages in package-lock.json (v1 to v3)
+func parsePackageLock(data []byte) (*manifest, error) {
+	var lock struct {
+		Packages     map[string]json.RawMessage `json:"packages"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	count := len(lock.Packages)
+	if _, ok := lock.Packages[""]; ok {
+		// The "" entry is the project itself
+		count--
+	}
+	if len(lock.Packages) == 0 {
+		count = len(lock.Dependencies)
+	}
+	return &manifest{ecosystem: "npm", lockfile: true, lockedPackages: count}, nil
+}
+
+// parseYarnLock counts the entries of a yarn.lock (classic and Berry)
+func parseYarnLock(data []byte) (*manifest, error) {
+	count := 0
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line != "" && line[0] != ' ' && line[0] != '#' && strings.HasSuffix(line, ":") &&
+			!strings.HasPrefix(line, "__metadata") {
+			count++
+		}
+	}
+	return &manifest{ecosystem: "npm", lockfile: true, lockedPackages: count}, nil
+}
+
+// parsePnpmLock counts the packages of a pnpm-lock.yaml
+func parsePnpmLock(data []byte) (*manifest, error) {
+	var lock struct {
+		Packages map[string]any `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return &manifest{ecosystem: "npm", lockfile: true, lockedPackages: len(lock.Packages)}, nil
+}
+
+// parseRequirements reads a pip requirements file, skipping options such
+// as -r, -e and --index-url
+func parseRequirements(data []byte) (*manifest, error) {
+	m := &manifest{ecosystem: "python"}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line, _, _ = strings.Cut(line, " #")
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		if name := requirementName(line); name != "" {
+			m.dependencies = append(m.dependencies, name)
+		}
+	}
+	return m, nil
+}
+
+// requirementNameRe matches the project name at the start of a PEP 508
+// requirement ("requests[socks]>=2.31; python_version>'3.8'")
+var requirementNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+// requirementName returns the project name of a PEP 508 requirement
+func requirementName(requirement string) string {
+	return requirementNameRe.FindString(strings.TrimSpace(requirement))
+}
+
+// normalizePythonName normalizes a project name as PyPI does
+// ("Flask_SQLAlchemy" → "flask-sqlalchemy")
+func normalizePythonName(name string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+}
+
+// parsePyproject reads a pyproject.toml: PEP 621 [project] tables, PEP 735
+// dependency groups and Poetry's [tool.poetry]
+func parsePyproject(data []byte) (*manifest, error) {
+	var p struct {
+		Project struct {
+			Name                 string              `toml:"name"`
+			Version              string              `toml:"version"`
+			RequiresPython       string              `toml:"requires-python"`
+			Dependencies         []string            `toml:"dependencies"`
+			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+			Scripts              map[string]string   `toml:"scripts"`
+		} `toml:"project"`
+		DependencyGroups map[string][]any `toml:"dependency-groups"`
+		Tool             struct {
+			Poetry struct {
+				Name            string         `toml:"name"`
+				Version         string         `toml:"version"`
+				Dependencies    map[string]any `toml:"dependencies"`
+				DevDependencies map[string]any `toml:"dev-dependencies"`
+				Group           map[string]struct {
+					Dependencies map[string]any `toml:"dependencies"`
+				} `toml:"group"`
+				Scripts map[string]any `toml:"scripts"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	m := &manifest{ecosystem: "python", name: p.Project.Name, version: p.Project.Version, scripts: p.Project.Scripts}
+	for _, requirement := range p.Project.Dependencies {
+		m.dependencies = appendName(m.dependencies, requirementName(requirement))
+	}
+	for _, extra := range sortedKeys(p.Project.OptionalDependencies) {
+		for _, requirement := range p.Project.OptionalDependencies[extra] {
+			m.devDependencies = appendName(m.devDependencies, requirementName(requirement))
+		}
+	}
+	for _, group := range sortedKeys(p.DependencyGroups) {
+		for _, item := range p.DependencyGroups[group] {
+			// Groups may include other groups as {include-group = "..."}
+			if requirement, ok := item.(string); ok {
+				m.devDependencies = appendName(m.devDependencies, requirementName(requirement))
+			}
+		}
+	}
+	if p.Project.RequiresPython != "" {
+		m.languageVersion = "python " + p.Project.RequiresPython
+	}
+
+	poetry := p.Tool.Poetry
+	if m.name == "" {
+		m.name, m.version = poetry.Name, poetry.Version
+	}
+	for _, name := range sortedKeys(poetry.Dependencies) {
+		if name == "python" {
+			if version, ok := poetry.Dependencies[name].(string); ok && m.languageVersion == "" {
+				m.languageVersion = "python " + version
+			}
+			continue
+		}
+		m.dependencies = appendName(m.dependencies, name)
+	}
+	for _, name := range sortedKeys(poetry.DevDependencies) {
+		m.devDependencies = appendName(m.devDependencies, name)
+	}
+	for _, group := range sortedKeys(poetry.Group) {
+		for _, name := range sortedKeys(poetry.Group[group].Dependencies) {
+			m.devDependencies = appendName(m.devDependencies, name)
+		}
+	}
+	if len(poetry.Scripts) > 0 && m.scripts == nil {
+		m.scripts = make(map[string]string)
+		for name, script := range poetry.Scripts {
+			m.scripts[name] = fmt.Sprint(script)
+		}
+	}
+	return m, nil
+}
+
+// parsePipfile reads a Pipfile (TOML)
+func parsePipfile(data []byte) (*manifest, error) {
+	var p struct {
+		Packages    map[string]any    `toml:"packages"`
+		DevPackages map[string]any    `toml:"dev-packages"`
+		Scripts     map[string]string `toml:"scripts"`
+		Requires    struct {
+			PythonVersion     string `toml:"python_version"`
+			PythonFullVersion string `toml:"python_full_version"`
+		} `toml:"requires"`
+	}
+	if err := toml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	m := &manifest{
+		ecosystem:       "python",
+		dependencies:    sortedKeys(p.Packages),
+		devDependencies: sortedKeys(p.DevPackages),
+		scripts:         p.Scripts,
+	}
+	if version := cmp.Or(p.Requires.PythonFullVersion, p.Requires.PythonVersion); version != "" {
+		m.languageVersion = "python " + version
+	}
+	return m, nil
+}
+
+// parsePipfileLock counts the packages of a Pipfile.lock
+func parsePipfileLock(data []byte) (*manifest, error) {
+	var lock struct {
+		Default map[string]json.RawMessage `json:"default"`
+		Develop map[string]json.RawMessage `json:"develop"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return &manifest{ecosystem: "python", lockfile: true, lockedPackages: len(lock.Default) + len(lock.Develop)}, nil
+}
+
+// tomlPackageLock counts the [[package]] tables of Cargo.lock and poetry.lock
+func tomlPackageLock(ecosystem string) func([]byte) (*manifest, error) {
+	return func(data []byte) (*manifest, error) {
+		count := 0
+		for line := range strings.SplitSeq(string(data), "\n") {
+			if strings.TrimSpace(line) == "[[package]]" {
+				count++
+			}
+		}
+		return &manifest{ecosystem: ecosystem, lockfile: true, lockedPackages: count}, nil
+	}
+}
+
+// parseCargo reads a Cargo.toml
+func parseCargo(data []byte) (*manifest, error) {
+	var c struct {
+		Package struct {
+			Name        string `toml:"name"`
+			Version     any    `toml:"version"` // a string, or {workspace = true}
+			Edition     any    `toml:"edition"`
+			RustVersion any    `toml:"rust-version"`
+		} `toml:"package"`
+		Dependencies    map[string]any `toml:"dependencies"`
+		DevDependencies map[string]any `toml:"dev-dependencies"`
+		Bin             []struct {
+			Name string `toml:"name"`
+		} `toml:"bin"`
+		Workspace struct {
+			Members      []string       `toml:"members"`
+			Dependencies map[string]any `toml:"dependencies"`
+		} `toml:"workspace"`
+	}
+	if err := toml.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	m := &manifest{
+		ecosystem:       "cargo",
+		name:            c.Package.Name,
+		dependencies:    sortedKeys(c.Dependencies),
+		devDependencies: sortedKeys(c.DevDependencies),
+		members:         c.Workspace.Members,
+	}
+	if len(m.dependencies) == 0 {
+		// A virtual workspace manifest declares the shared dependencies
+		m.dependencies = sortedKeys(c.Workspace.Dependencies)
+	}
+	if version, ok := c.Package.Version.(string); ok {
+		m.version = version
+	}
+	if version, ok := c.Package.RustVersion.(string); ok {
+		m.languageVersion = "rust " + version
+	} else if edition, ok := c.Package.Edition.(string); ok {
+		m.languageVersion = "edition " + edition
+	}
+	for _, bin := range c.Bin {
+		m.targets = append(m.targets, bin.Name)
+	}
+	return m, nil
+}
+
+// pomCoordinate is a Maven dependency, plugin or parent
+type pomCoordinate struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// String formats a coordinate as groupId:artifactId
+func (c pomCoordinate) String() string {
+	if c.GroupID == "" {
+		return c.ArtifactID
+	}
+	return c.GroupID + ":" + c.ArtifactID
+}
+
+// pomLanguageProperties name the language version in a POM's <properties>,
+// most specific first
+var pomLanguageProperties = []struct{ key, language string }{
+	{"java.version", "java"},
+	{"maven.compiler.release", "java"},
+	{"maven.compiler.source", "java"},
+	{"kotlin.version", "kotlin"},
+}
+
+// parsePOM reads a Maven pom.xml
+func parsePOM(data []byte) (*manifest, error) {
+	var pom struct {
+		pomCoordinate
+		Parent     pomCoordinate `xml:"parent"`
+		Properties struct {
+			Entries []struct {
+				XMLName xml.Name
+				Value   string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"properties"`
+		Modules      []string        `xml:"modules>module"`
+		Dependencies []pomCoordinate `xml:"dependencies>dependency"`
+		Plugins      []pomCoordinate `xml:"build>plugins>plugin"`
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	if err := decoder.Decode(&pom); err != nil {
+		return nil, err
+	}
+
+	if pom.GroupID == "" {
+		pom.GroupID = pom.Parent.GroupID
+	}
+	m := &manifest{ecosystem: "maven", name: pom.pomCoordinate.String(), version: pom.Version, members: pom.Modules}
+	if pom.Parent.ArtifactID != "" {
+		m.parent = pom.Parent.String()
+	}
+	for _, dep := range pom.Dependencies {
+		if dep.Scope == "test" {
+			m.devDependencies = append(m.devDependencies, dep.String())
+		} else {
+			m.dependencies = append(m.dependencies, dep.String())
+		}
+	}
+	for _, plugin := range pom.Plugins {
+		m.plugins = append(m.plugins, plugin.String())
+	}
+
+	properties := make(map[string]string)
+	for _, entry := range pom.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+	for _, property := range pomLanguageProperties {
+		if version := properties[property.key]; version != "" {
+			m.languageVersion = property.language + " " + version
+			break
+		}
+	}
+	return m, nil
+}
+
+var (
+	// gradleDependencyRe matches implementation("group:artifact:version")
+	// and testImplementation 'group:artifact:version'
+	gradleDependencyRe = regexp.MustCompile(`^\s*(\w+)\s*\(?\s*(?:platform\()?["']([^"':]+:[^"':]+)[^"']*["']`)
+	// gradlePluginRe matches id("org.jetbrains.kotlin.jvm") and apply plugin: 'java'
+	gradlePluginRe = regexp.MustCompile(`^\s*(?:id\s*\(?|apply\s+plugin\s*:)\s*["']([^"']+)["']`)
+	// gradleJavaRe matches sourceCompatibility = JavaVersion.VERSION_17,
+	// jvmToolchain(17) and JavaLanguageVersion.of(21)
+	gradleJavaRe = regexp.MustCompile(`(?:sourceCompatibility\s*=\s*(?:JavaVersion\.VERSION_)?["']?|jvmToolchain\(\s*|JavaLanguageVersion\.of\(\s*)([\d._]+)`)
+	// gradleTaskRe matches task hello { and tasks.register("hello")
+	gradleTaskRe = regexp.MustCompile(`^\s*(?:task\s+(\w+)|tasks\.register(?:<\w+>)?\(\s*["'](\w+)["'])`)
+	// gradleGroupRe matches group = 'com.acme' and version = "1.0"
+	gradleGroupRe = regexp.MustCompile(`^\s*(group|version)\s*=\s*["']([^"']+)["']`)
+)
+
+// gradleConfigurations are the dependency configurations Scout reads
+var gradleConfigurations = map[string]bool{
+	"implementation": true, "api": true, "compileOnly": true, "runtimeOnly": true,
+	"kapt": true, "ksp": true, "annotationProcessor": true, "classpath": true, "compile": true,
+}
+
+// parseGradle reads a build.gradle or build.gradle.kts line by line; the
+// build script itself is code, so only the common declarations are read
+func parseGradle(data []byte) (*manifest, error) {
+	m := &manifest{ecosystem: "gradle"}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if match := gradleDependencyRe.FindStringSubmatch(line); match != nil {
+			switch configuration := match[1]; {
+			case strings.HasPrefix(configuration, "test") || strings.HasPrefix(configuration, "androidTest"):
+				m.devDependencies = append(m.devDependencies, match[2])
+			case gradleConfigurations[configuration]:
+				m.dependencies = append(m.dependencies, match[2])
+			}
+		}
+		if match := gradlePluginRe.FindStringSubmatch(line); match != nil {
+			m.plugins = append(m.plugins, match[1])
+		}
+		if match := gradleJavaRe.FindStringSubmatch(line); match != nil && m.languageVersion == "" {
+			m.languageVersion = "java " + strings.TrimPrefix(strings.ReplaceAll(match[1], "_", "."), "1.")
+		}
+		if match := gradleTaskRe.FindStringSubmatch(line); match != nil {
+			m.targets = append(m.targets, match[1]+match[2])
+		}
+		if match := gradleGroupRe.FindStringSubmatch(line); match != nil {
+			if match[1] == "group" {
+				m.name = match[2]
+			} else {
+				m.version = match[2]
+			}
+		}
+	}
+	return m, nil
+}
+
+var (
+	// gemRe matches gem "rails", "~> 7.1"
+	gemRe = regexp.MustCompile(`^\s*gem\s+["']([^"']+)["']`)
+	// gemRubyRe matches ruby "3.2.2"
+	gemRubyRe = regexp.MustCompile(`^\s*ruby\s+["']([^"']+)["']`)
+)
+
+// parseGemfile reads a Bundler Gemfile; gems in the development and test
+// groups count as development dependencies
+func parseGemfile(data []byte) (*manifest, error) {
+	m := &manifest{ecosystem: "ruby"}
+	devGroup := false
+	for line := range strings.SplitSeq(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "group ") && strings.HasSuffix(trimmed, " do"):
+			devGroup = strings.Contains(trimmed, ":development") || strings.Contains(trimmed, ":test")
+		case trimmed == "end":
+			devGroup = false
+		}
+		if match := gemRe.FindStringSubmatch(line); match != nil {
+			if devGroup || strings.Contains(line, "group: :development") || strings.Contains(line, "group: :test") {
+				m.devDependencies = append(m.devDependencies, match[1])
+			} else {
+				m.dependencies = append(m.dependencies, match[1])
+			}
+		}
+		if match := gemRubyRe.FindStringSubmatch(line); match != nil {
+			m.languageVersion = "ruby " + match[1]
+		}
+	}
+	return m, nil
+}
+
+// parseGemfileLock counts the gems a Gemfile.lock pins: spec lines are
+// indented by four spaces, their own dependencies by six
+func parseGemfileLock(data []byte) (*manifest, error) {
+	gems := make(map[string]bool)
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if strings.HasPrefix(line, "    ") && !strings.HasPrefix(line, "     ") {
+			name, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+			gems[name] = true
+		}
+	}
+	return &manifest{ecosystem: "ruby", lockfile: true, lockedPackages: len(gems)}, nil
+}
+
+// parseComposer reads a PHP composer.json
+func parseComposer(data []byte) (*manifest, error) {
+	var c struct {
+		Name       string                     `json:"name"`
+		Version    string                     `json:"version"`
+		Require    map[string]string          `json:"require"`
+		RequireDev map[string]string          `json:"require-dev"`
+		Scripts    map[string]json.RawMessage `json:"scripts"`
+		Bin        []string                   `json:"bin"`
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	m := &manifest{ecosystem: "composer", name: c.Name, version: c.Version, targets: c.Bin}
+	if php := c.Require["php"]; php != "" {
+		m.languageVersion = "php " + php
+	}
+	// PHP itself and its extensions (ext-json) are platform requirements
+	isPackage := func(name string) bool { return name != "php" && !strings.HasPrefix(name, "ext-") }
+	for _, name := range sortedKeys(c.Require) {
+		if isPackage(name) {
+			m.dependencies = append(m.dependencies, name)
+		}
+	}
+	for _, name := range sortedKeys(c.RequireDev) {
+		if isPackage(name) {
+			m.devDependencies = append(m.devDependencies, name)
+		}
+	}
+
+	// A script is one command or a list of them
+	if len(c.Scripts) > 0 {
+		m.scripts = make(map[string]string, len(c.Scripts))
+		for name, raw := range c.Scripts {
+			var commands []string
+			var command string
+			if json.Unmarshal(raw, &command) == nil {
+				commands = []string{command}
+			} else {
+				_ = json.Unmarshal(raw, &commands)
+			}
+			m.scripts[name] = strings.Join(commands, " && ")
+		}
+	}
+	return m, nil
+}
+
+// parseComposerLock counts the packages of a composer.lock
+func parseComposerLock(data []byte) (*manifest, error) {
+	var lock struct {
+		Packages    []json.RawMessage `json:"packages"`
+		PackagesDev []json.RawMessage `json:"packages-dev"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return &manifest{ecosystem: "composer", lockfile: true, lockedPackages: len(lock.Packages) + len(lock.PackagesDev)}, nil
+}
+
+// parsePubspec reads a Dart or Flutter pubspec.yaml
+func parsePubspec(data []byte) (*manifest, error) {
+	var p struct {
+		Name            string            `yaml:"name"`
+		Version         string            `yaml:"version"`
+		Environment     map[string]string `yaml:"environment"`
+		Dependencies    map[string]any    `yaml:"dependencies"`
+		DevDependencies map[string]any    `yaml:"dev_dependencies"`
+		Executables     map[string]any    `yaml:"executables"`
+	}
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	m := &manifest{
+		ecosystem:       "dart",
+		name:            p.Name,
+		version:         p.Version,
+		dependencies:    sortedKeys(p.Dependencies),
+		devDependencies: sortedKeys(p.DevDependencies),
+		targets:         sortedKeys(p.Executables),
+	}
+	if sdk := p.Environment["sdk"]; sdk != "" {
+		m.languageVersion = "dart " + sdk
+	}
+	return m, nil
+}
+
+// parsePubspecLock counts the packages of a pubspec.lock
+func parsePubspecLock(data []byte) (*manifest, error) {
+	var lock struct {
+		Packages map[string]any `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return &manifest{ecosystem: "dart", lockfile: true, lockedPackages: len(lock.Packages)}, nil
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendName appends a dependency name unless it is empty or already listed
+func appendName(names []string, name string) []string {
+	if name == "" || slices.Contains(names, name) {
+		return names
+	}
+	return append(names, name)
+}
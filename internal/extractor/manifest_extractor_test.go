@@ -0,0 +1,278 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifestParsers(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want manifest
+	}{
+		{"go.mod", `module github.com/acme/api
+
+go 1.22
+
+require github.com/gin-gonic/gin v1.9.1
+
+require (
+	github.com/google/uuid v1.6.0
+	golang.org/x/text v0.14.0 // indirect
+)
+
+tool golang.org/x/tools/cmd/stringer
+`, manifest{
+			ecosystem: "go", name: "github.com/acme/api", languageVersion: "go 1.22",
+			dependencies: []string{"github.com/gin-gonic/gin", "github.com/google/uuid"},
+			targets:      []string{"golang.org/x/tools/cmd/stringer"},
+		}},
+		{"package.json", `{
+  "name": "@acme/cli", "version": "1.0.0", "engines": {"node": ">=18"},
+  "dependencies": {"react": "^18", "express": "^4"},
+  "devDependencies": {"jest": "^29"},
+  "scripts": {"test": "jest"},
+  "bin": "./cli.js",
+  "workspaces": ["packages/*"]
+}`, manifest{
+			ecosystem: "npm", name: "@acme/cli", version: "1.0.0", languageVersion: "node >=18",
+			dependencies: []string{"express", "react"}, devDependencies: []string{"jest"},
+			scripts: map[string]string{"test": "jest"}, targets: []string{"cli"}, members: []string{"packages/*"},
+		}},
+		{"package.json", `{"name": "tools", "bin": {"b": "b.js", "a": "a.js"}, "workspaces": {"packages": ["apps/*", "libs/*"]}}`, manifest{
+			ecosystem: "npm", name: "tools", dependencies: []string{}, devDependencies: []string{},
+			targets: []string{"a", "b"}, members: []string{"apps/*", "libs/*"},
+		}},
+		{"requirements-dev.txt", `# pinned
+-r requirements.txt
+--index-url https://pypi.example.com
+Django>=4.2  # web
+requests[socks]>=2.31; python_version > "3.8"
+-e git+https://github.com/acme/lib.git#egg=lib
+zope.interface==6.0
+`, manifest{ecosystem: "python", dependencies: []string{"Django", "requests", "zope.interface"}}},
+		{"pyproject.toml", `[project]
+name = "svc"
+version = "0.1.0"
+requires-python = ">=3.11"
+dependencies = ["fastapi>=0.110", "pydantic[email]"]
+[project.optional-dependencies]
+docs = ["mkdocs"]
+[project.scripts]
+svc = "svc.main:run"
+[dependency-groups]
+test = ["pytest", {include-group = "docs"}]
+`, manifest{
+			ecosystem: "python", name: "svc", version: "0.1.0", languageVersion: "python >=3.11",
+			dependencies: []string{"fastapi", "pydantic"}, devDependencies: []string{"mkdocs", "pytest"},
+			scripts: map[string]string{"svc": "svc.main:run"},
+		}},
+		{"pyproject.toml", `[tool.poetry]
+name = "bot"
+version = "2.0"
+[tool.poetry.dependencies]
+python = "^3.10"
+aiohttp = "^3.9"
+[tool.poetry.group.dev.dependencies]
+black = "*"
+[tool.poetry.scripts]
+bot = "bot:main"
+`, manifest{
+			ecosystem: "python", name: "bot", version: "2.0", languageVersion: "python ^3.10",
+			dependencies: []string{"aiohttp"}, devDependencies: []string{"black"},
+			scripts: map[string]string{"bot": "bot:main"},
+		}},
+		{"Pipfile", "[packages]\nflask = \"*\"\n[dev-packages]\npytest = \"*\"\n[requires]\npython_version = \"3.12\"\n", manifest{
+			ecosystem: "python", dependencies: []string{"flask"}, devDependencies: []string{"pytest"}, languageVersion: "python 3.12",
+		}},
+		{"Cargo.toml", `[package]
+name = "ripper"
+version = "0.3.0"
+edition = "2021"
+[dependencies]
+tokio = { version = "1", features = ["full"] }
+serde = "1"
+[dev-dependencies]
+criterion = "0.5"
+[[bin]]
+name = "rip"
+`, manifest{
+			ecosystem: "cargo", name: "ripper", version: "0.3.0", languageVersion: "edition 2021",
+			dependencies: []string{"serde", "tokio"}, devDependencies: []string{"criterion"}, targets: []string{"rip"},
+		}},
+		{"Cargo.toml", "[workspace]\nmembers = [\"core\", \"cli\"]\n[workspace.dependencies]\nanyhow = \"1\"\n", manifest{
+			ecosystem: "cargo", dependencies: []string{"anyhow"}, devDependencies: []string{}, members: []string{"core", "cli"},
+		}},
+		{"pom.xml", `<project>
+  <parent><groupId>org.springframework.boot</groupId><artifactId>spring-boot-starter-parent</artifactId></parent>
+  <artifactId>shop</artifactId><version>1.0</version>
+  <properties><maven.compiler.source>17</maven.compiler.source><java.version>21</java.version></properties>
+  <modules><module>web</module></modules>
+  <dependencies>
+    <dependency><groupId>org.springframework.boot</groupId><artifactId>spring-boot-starter-web</artifactId></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><scope>test</scope></dependency>
+  </dependencies>
+  <build><plugins><plugin><artifactId>maven-jar-plugin</artifactId></plugin></plugins></build>
+</project>`, manifest{
+			ecosystem: "maven", name: "org.springframework.boot:shop", version: "1.0", languageVersion: "java 21",
+			parent:          "org.springframework.boot:spring-boot-starter-parent",
+			dependencies:    []string{"org.springframework.boot:spring-boot-starter-web"},
+			devDependencies: []string{"junit:junit"}, plugins: []string{"maven-jar-plugin"}, members: []string{"web"},
+		}},
+		{"build.gradle.kts", `plugins {
+    id("org.springframework.boot") version "3.2.0"
+}
+group = "com.acme"
+version = "0.1"
+java { toolchain { languageVersion = JavaLanguageVersion.of(21) } }
+dependencies {
+    implementation(platform("org.springframework.boot:spring-boot-dependencies:3.2.0"))
+    implementation("com.squareup.okhttp3:okhttp:4.12.0")
+    testImplementation("org.junit.jupiter:junit-jupiter:5.10.0")
+    debugImplementation("ignored:lib:1")
+}
+tasks.register("hello") { }
+`, manifest{
+			ecosystem: "gradle", name: "com.acme", version: "0.1", languageVersion: "java 21",
+			plugins:         []string{"org.springframework.boot"},
+			dependencies:    []string{"org.springframework.boot:spring-boot-dependencies", "com.squareup.okhttp3:okhttp"},
+			devDependencies: []string{"org.junit.jupiter:junit-jupiter"}, targets: []string{"hello"},
+		}},
+		{"Gemfile", `source "https://rubygems.org"
+ruby "3.2.2"
+gem "rails", "~> 7.1"
+gem "rubocop", group: :development
+group :development, :test do
+  gem "rspec-rails"
+end
+gem 'puma'
+`, manifest{
+			ecosystem: "ruby", languageVersion: "ruby 3.2.2",
+			dependencies: []string{"rails", "puma"}, devDependencies: []string{"rubocop", "rspec-rails"},
+		}},
+		{"composer.json", `{"name": "acme/site", "require": {"php": ">=8.1", "ext-json": "*", "laravel/framework": "^10"},
+  "require-dev": {"phpunit/phpunit": "^10"}, "bin": ["bin/site"],
+  "scripts": {"test": "phpunit", "fix": ["php-cs-fixer fix", "phpstan"]}}`, manifest{
+			ecosystem: "composer", name: "acme/site", languageVersion: "php >=8.1",
+			dependencies: []string{"laravel/framework"}, devDependencies: []string{"phpunit/phpunit"},
+			targets: []string{"bin/site"}, scripts: map[string]string{"test": "phpunit", "fix": "php-cs-fixer fix && phpstan"},
+		}},
+		{"pubspec.yaml", "name: app\nversion: 1.0.0+1\nenvironment:\n  sdk: '>=3.0.0 <4.0.0'\ndependencies:\n  flutter:\n    sdk: flutter\n  http: ^1.1.0\ndev_dependencies:\n  lints: ^3.0.0\n", manifest{
+			ecosystem: "dart", name: "app", version: "1.0.0+1", languageVersion: "dart >=3.0.0 <4.0.0",
+			dependencies: []string{"flutter", "http"}, devDependencies: []string{"lints"}, targets: []string{},
+		}},
+	}
+	for _, tt := range tests {
+		parse, ok := manifestParser(tt.name)
+		if !ok {
+			t.Fatalf("no parser for %s", tt.name)
+		}
+		got, err := parse([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestManifestLockfiles(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+	}{
+		{"go.sum", "a v1.0.0 h1:x=\na v1.0.0/go.mod h1:y=\nb v2.0.0/go.mod h1:z=\n", 2},
+		{"package-lock.json", `{"lockfileVersion": 3, "packages": {"": {}, "node_modules/a": {}, "node_modules/b": {}}}`, 2},
+		{"package-lock.json", `{"lockfileVersion": 1, "dependencies": {"a": {}, "b": {}, "c": {}}}`, 3},
+		{"yarn.lock", "# yarn lockfile v1\n\n\"a@^1\", \"a@^1.2\":\n  version \"1.2.0\"\n\nb@^2:\n  version \"2.0.0\"\n", 2},
+		{"yarn.lock", "__metadata:\n  version: 6\n\n\"a@npm:^1\":\n  version: 1.0.0\n", 1},
+		{"pnpm-lock.yaml", "lockfileVersion: '9.0'\npackages:\n  a@1.0.0: {}\n  b@2.0.0: {}\n", 2},
+		{"Pipfile.lock", `{"default": {"flask": {}}, "develop": {"pytest": {}, "black": {}}}`, 3},
+		{"poetry.lock", "[[package]]\nname = \"a\"\n\n[[package]]\nname = \"b\"\n", 2},
+		{"Cargo.lock", "version = 3\n\n[[package]]\nname = \"a\"\n", 1},
+		{"Gemfile.lock", "GEM\n  specs:\n    rails (7.1.0)\n      actionpack (= 7.1.0)\n    puma (6.4.0)\n", 2},
+		{"composer.lock", `{"packages": [{}, {}], "packages-dev": [{}]}`, 3},
+		{"pubspec.lock", "packages:\n  http:\n    version: \"1.1.0\"\n", 1},
+	}
+	for _, tt := range tests {
+		parse, _ := manifestParser(tt.name)
+		got, err := parse([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !got.lockfile || got.lockedPackages != tt.want {
+			t.Errorf("%s: locked %d (lockfile %v), want %d", tt.name, got.lockedPackages, got.lockfile, tt.want)
+		}
+	}
+}
+
+func TestManifestExtract(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":        {Data: []byte("module example.com/web\n\ngo 1.22\n\nrequire github.com/gin-gonic/gin v1.9.1\n")},
+		"broken/go.mod": {Data: []byte("go 1.22\n")},
+	}
+	content, err := ManifestExtractor{}.Extract(fsys, "go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"type": "manifest", "ecosystem": "go", "module": "example.com/web", "language_version": "go 1.22",
+		"dependency_count": 1, "dev_dependency_count": 0,
+		"dependencies": []string{"github.com/gin-gonic/gin"}, "frameworks": []string{"Gin"},
+	}
+	if !reflect.DeepEqual(content.Details, want) {
+		t.Errorf("details = %v\nwant %v", content.Details, want)
+	}
+	if want := "Go module example.com/web (go 1.22): 1 dependency\nFrameworks: Gin\n\n"; content.Preview[:len(want)] != want {
+		t.Errorf("preview = %q, want it to start with %q", content.Preview, want)
+	}
+
+	if _, err := (ManifestExtractor{}).Extract(fsys, "broken/go.mod"); err == nil {
+		t.Error("go.mod without a module directive: want an error")
+	}
+}
+
+func TestRequirementName(t *testing.T) {
+	tests := map[string]string{
+		"requests[socks]>=2.31":           "requests",
+		"Flask_SQLAlchemy==3.1":           "Flask_SQLAlchemy",
+		"  numpy ; python_version<'3.12'": "numpy",
+		"git+https://x/y.git":             "git",
+		">=1.0":                           "",
+	}
+	for requirement, want := range tests {
+		if got := requirementName(requirement); got != want {
+			t.Errorf("requirementName(%q) = %q, want %q", requirement, got, want)
+		}
+	}
+	if got := normalizePythonName("Flask_SQLAlchemy.ext"); got != "flask-sqlalchemy-ext" {
+		t.Errorf("normalizePythonName = %q, want flask-sqlalchemy-ext", got)
+	}
+}
+
+func TestFrameworkRuleMatches(t *testing.T) {
+	tests := []struct {
+		rule      frameworkRule
+		ecosystem string
+		dep       string
+		want      bool
+	}{
+		{frameworkRule{"github.com/labstack/echo", "Echo"}, "go", "github.com/labstack/echo/v4", true},
+		{frameworkRule{"github.com/labstack/echo", "Echo"}, "go", "github.com/labstack/echox", false},
+		{frameworkRule{"org.springframework.boot", "Spring Boot"}, "maven", "org.springframework.boot:spring-boot-starter", true},
+		{frameworkRule{"flask-sqlalchemy", "x"}, "python", "Flask_SQLAlchemy", true},
+		{frameworkRule{"react", "React"}, "npm", "React", true},
+		{frameworkRule{"react", "React"}, "npm", "react-dom", false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.matches(tt.ecosystem, tt.dep); got != tt.want {
+			t.Errorf("%s rule %q matches(%q) = %v, want %v", tt.ecosystem, tt.rule.dep, tt.dep, got, tt.want)
+		}
+	}
+}
@@ -1,22 +1,28 @@
 package extractor
 
 import (
-	"os"
+	"io/fs"
 	"strings"
 )
 
 // MarkdownExtractor extracts markdown files content
-type MarkdownExtractor struct{}
+type MarkdownExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
 
 // Extract extracts content from a markdown file
-func (m MarkdownExtractor) Extract(path string) (*ExtractedContent, error) {
-
-	data, err := os.ReadFile(path)
+func (m MarkdownExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
 		return nil, err
 	}
+	return m.extract(data), nil
+}
 
-	content := string(data)
+// extract builds the preview and title from markdown source, decoded to
+// UTF-8 first (see decodeText)
+func (m MarkdownExtractor) extract(data []byte) *ExtractedContent {
+	content, encodingName, lineEnding := decodeText(data)
 	lines := strings.Split(content, "\n")
 
 	title := ""
@@ -28,13 +34,18 @@ func (m MarkdownExtractor) Extract(path string) (*ExtractedContent, error) {
 	}
 
 	preview := strings.Join(lines[:min(len(lines), 20)], "\n")
+	if m.Limit > 0 {
+		preview = truncatePreview(content, m.Limit)
+	}
 
 	return &ExtractedContent{
 		Category: "markdown",
 		Preview:  preview,
 		Lines:    len(lines),
 		Details: map[string]any{
-			"title": title,
+			"title":        title,
+			"encoding":     encodingName,
+			"line_endings": lineEnding,
 		},
-	}, nil
+	}
 }
@@ -0,0 +1,539 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"strings"
+)
+
+// MediaExtractor reads tags and stream properties from audio and video
+// containers (MP3, FLAC, Ogg, WAV, MP4/MOV) without decoding any media
+type MediaExtractor struct{}
+
+// mediaScanBytes bounds how far into a file the first MP3 frame or the Ogg
+// header pages are looked for
+const mediaScanBytes = 256 * 1024
+
+// MPEG audio layer III bitrates (kbps) by bitrate index
+var (
+	mpeg1L3Bitrates = []int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mpeg2L3Bitrates = []int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mpeg1Rates      = []int{44100, 48000, 32000}
+)
+
+// vorbisCommentKeys maps Vorbis comment fields to detail keys
+var vorbisCommentKeys = map[string]string{
+	"TITLE": "title", "ARTIST": "artist", "ALBUM": "album",
+	"DATE": "year", "YEAR": "year", "GENRE": "genre",
+}
+
+// riffInfoKeys maps WAV LIST/INFO chunks to detail keys
+var riffInfoKeys = map[string]string{
+	"INAM": "title", "IART": "artist", "IPRD": "album", "ICRD": "year", "IGNR": "genre",
+}
+
+// mediaInfo collects what the container parsers find
+type mediaInfo struct {
+	format     string
+	codec      string
+	audioCodec string // audio track codec of a video
+	duration   float64
+	bitrate    int // kbps
+	sampleRate int
+	channels   int
+	width      int
+	height     int
+	created    string
+	video      bool
+	tags       map[string]string
+}
+
+// Extract extracts audio and video metadata
+func (e MediaExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := openRandomAccess(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	size := file.size
+
+	header := make([]byte, 12)
+	n, _ := file.ReadAt(header, 0)
+	header = header[:n]
+
+	var info *mediaInfo
+	switch {
+	case len(header) >= 8 && (string(header[4:8]) == "ftyp" || string(header[4:8]) == "moov" ||
+		string(header[4:8]) == "mdat" || string(header[4:8]) == "wide"):
+		info = mp4Media(file, header, size)
+	case bytes.HasPrefix(header, []byte("OggS")):
+		info = oggMedia(file, size)
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		info = wavMedia(file, size)
+	default:
+		// FLAC files occasionally carry an ID3v2 tag in front of "fLaC"
+		tags, tagSize := readID3v2(file)
+		magic := make([]byte, 4)
+		file.ReadAt(magic, tagSize)
+		if string(magic) == "fLaC" {
+			info = flacMedia(file, tagSize+4, size)
+		} else {
+			info = mp3Media(file, tags, tagSize, size)
+		}
+	}
+	if info == nil {
+		return nil, fmt.Errorf("unrecognised audio or video format")
+	}
+
+	category := "audio"
+	if info.video {
+		category = "video"
+	}
+	return &ExtractedContent{
+		Category: category,
+		Preview:  mediaPreview(info),
+		Details:  info.details(),
+	}, nil
+}
+
+// details converts the parsed properties into extractor details
+func (info *mediaInfo) details() map[string]any {
+	details := map[string]any{"format": info.format}
+	if info.codec != "" {
+		details["codec"] = info.codec
+	}
+	if info.audioCodec != "" {
+		details["audio_codec"] = info.audioCodec
+	}
+	if info.duration > 0 {
+		details["duration_seconds"] = int(math.Round(info.duration))
+	}
+	if info.bitrate > 0 {
+		details["bitrate_kbps"] = info.bitrate
+	}
+	if info.sampleRate > 0 {
+		details["sample_rate"] = info.sampleRate
+	}
+	if info.channels > 0 {
+		details["channels"] = info.channels
+	}
+	if info.width > 0 && info.height > 0 {
+		details["width"], details["height"] = info.width, info.height
+	}
+	if info.created != "" {
+		details["created"] = info.created
+	}
+	for _, key := range []string{"title", "artist", "album", "year", "genre"} {
+		value := strings.TrimSpace(info.tags[key])
+		// Dates such as "2001-05-14" or "2001-05-14T00:00:00Z" are reduced to the year
+		if key == "year" && len(value) > 4 && isDigits(value[:4]) {
+			value = value[:4]
+		}
+		if value != "" {
+			details[key] = value
+		}
+	}
+	return details
+}
+
+// mediaPreview summarises a file in one line, e.g.
+// "MP3 3:45, 320 kbps, Daft Punk - One More Time (Discovery, 2001)"
+func mediaPreview(info *mediaInfo) string {
+	first := info.format
+	if info.duration > 0 {
+		first += " " + formatDuration(info.duration)
+	}
+	parts := []string{first}
+	if info.width > 0 && info.height > 0 {
+		parts = append(parts, fmt.Sprintf("%dx%d", info.width, info.height))
+	}
+	if info.codec != "" && info.codec != info.format {
+		parts = append(parts, info.codec)
+	}
+	if info.bitrate > 0 {
+		parts = append(parts, fmt.Sprintf("%d kbps", info.bitrate))
+	}
+
+	artist, title := info.tags["artist"], info.tags["title"]
+	track := artist + title
+	if artist != "" && title != "" {
+		track = artist + " - " + title
+	}
+	var extra []string
+	if album := info.tags["album"]; album != "" {
+		extra = append(extra, album)
+	}
+	if year := info.tags["year"]; len(year) >= 4 {
+		extra = append(extra, year[:4])
+	}
+	if len(extra) > 0 {
+		track = strings.TrimSpace(track + " (" + strings.Join(extra, ", ") + ")")
+	}
+	if track != "" {
+		parts = append(parts, track)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatDuration formats seconds as "3:45" or "1:02:03"
+func formatDuration(seconds float64) string {
+	s := int(math.Round(seconds))
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// averageBitrate returns the bitrate in kbps of size bytes played over seconds
+func averageBitrate(size int64, seconds float64) int {
+	if seconds <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(size) * 8 / seconds / 1000))
+}
+
+// mp3Media reads the ID3 tags and the first MPEG audio frame. Duration comes
+// from the Xing/Info frame count of VBR files, otherwise from the bitrate.
+func mp3Media(r io.ReaderAt, tags map[string]string, tagSize, size int64) *mediaInfo {
+	buf := make([]byte, mediaScanBytes)
+	n, _ := r.ReadAt(buf, tagSize)
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		h := binary.BigEndian.Uint32(buf[i:])
+		version, layer := (h>>19)&3, (h>>17)&3
+		bitrateIndex, rateIndex := (h>>12)&0xF, (h>>10)&3
+		// Only layer III with a valid bitrate and sample rate is an MP3 frame
+		if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+			continue
+		}
+
+		info := &mediaInfo{format: "MP3", codec: "MP3", tags: tags}
+		if info.tags == nil {
+			info.tags = make(map[string]string)
+		}
+		mono := (h>>6)&3 == 3
+		info.channels = 2
+		if mono {
+			info.channels = 1
+		}
+
+		bitrate := mpeg1L3Bitrates[bitrateIndex]
+		info.sampleRate = mpeg1Rates[rateIndex]
+		samplesPerFrame := 1152
+		if version != 3 { // MPEG-2 and 2.5 use lower rates and half-size frames
+			bitrate = mpeg2L3Bitrates[bitrateIndex]
+			info.sampleRate /= 2
+			if version == 0 {
+				info.sampleRate /= 2
+			}
+			samplesPerFrame = 576
+		}
+
+		audioSize := size - tagSize - int64(i)
+		if v1 := readID3v1(r, size); v1 != nil {
+			audioSize -= 128
+			for key, value := range v1 {
+				if info.tags[key] == "" && value != "" {
+					info.tags[key] = value
+				}
+			}
+		}
+
+		// The Xing/Info header sits after the side information of the first frame
+		sideInfo := 32
+		switch {
+		case version == 3 && mono:
+			sideInfo = 17
+		case version != 3 && !mono:
+			sideInfo = 17
+		case version != 3 && mono:
+			sideInfo = 9
+		}
+		if x := i + 4 + sideInfo; x+12 <= len(buf) && (string(buf[x:x+4]) == "Xing" || string(buf[x:x+4]) == "Info") {
+			if flags := binary.BigEndian.Uint32(buf[x+4:]); flags&1 != 0 {
+				frames := binary.BigEndian.Uint32(buf[x+8:])
+				info.duration = float64(frames) * float64(samplesPerFrame) / float64(info.sampleRate)
+			}
+		}
+		if info.duration == 0 {
+			info.duration = float64(audioSize) * 8 / float64(bitrate*1000)
+		}
+		info.bitrate = averageBitrate(audioSize, info.duration)
+		return info
+	}
+
+	// A tag without audio frames is still worth reporting
+	if len(tags) > 0 {
+		return &mediaInfo{format: "MP3", tags: tags}
+	}
+	return nil
+}
+
+// flacMedia reads the STREAMINFO and VORBIS_COMMENT metadata blocks
+func flacMedia(r io.ReaderAt, offset, size int64) *mediaInfo {
+	info := &mediaInfo{format: "FLAC", codec: "FLAC", tags: make(map[string]string)}
+	header := make([]byte, 4)
+	for {
+		if _, err := r.ReadAt(header, offset); err != nil {
+			break
+		}
+		last, blockType := header[0]&0x80 != 0, header[0]&0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		switch {
+		case blockType == 0 && length >= 18:
+			block := make([]byte, 18)
+			if _, err := r.ReadAt(block, offset+4); err == nil {
+				b := block[10:]
+				info.sampleRate = int(b[0])<<12 | int(b[1])<<4 | int(b[2])>>4
+				info.channels = int(b[2]>>1&7) + 1
+				samples := uint64(b[3]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(b[4:]))
+				if info.sampleRate > 0 {
+					info.duration = float64(samples) / float64(info.sampleRate)
+				}
+			}
+		case blockType == 4 && length < 16*1024*1024:
+			block := make([]byte, length)
+			if _, err := r.ReadAt(block, offset+4); err == nil {
+				readVorbisComments(block, info.tags)
+			}
+		}
+
+		offset += 4 + length
+		if last {
+			break
+		}
+	}
+	info.bitrate = averageBitrate(size, info.duration)
+	return info
+}
+
+// readVorbisComments reads a Vorbis comment block (vendor string, then
+// KEY=value pairs, all length-prefixed little-endian) into tags
+func readVorbisComments(b []byte, tags map[string]string) {
+	if len(b) < 8 {
+		return
+	}
+	vendor := int(binary.LittleEndian.Uint32(b))
+	if 4+vendor+4 > len(b) {
+		return
+	}
+	b = b[4+vendor:]
+	count := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+	for i := 0; i < count && len(b) >= 4; i++ {
+		length := int(binary.LittleEndian.Uint32(b))
+		if 4+length > len(b) {
+			return
+		}
+		key, value, ok := strings.Cut(string(b[4:4+length]), "=")
+		if name, known := vorbisCommentKeys[strings.ToUpper(key)]; ok && known && tags[name] == "" {
+			tags[name] = value
+		}
+		b = b[4+length:]
+	}
+}
+
+// oggMedia reassembles the header packets of an Ogg stream to read the
+// Vorbis or Opus identification and comment headers; the duration comes
+// from the granule position of the last page
+func oggMedia(r io.ReaderAt, size int64) *mediaInfo {
+	buf := make([]byte, mediaScanBytes)
+	n, _ := r.ReadAt(buf, 0)
+	buf = buf[:n]
+
+	// Collect the first two packets, which may span several pages
+	var packets [][]byte
+	var current []byte
+	for p := 0; len(packets) < 2 && p+27 <= len(buf) && string(buf[p:p+4]) == "OggS"; {
+		segments := int(buf[p+26])
+		if p+27+segments > len(buf) {
+			break
+		}
+		table := buf[p+27 : p+27+segments]
+		data := p + 27 + segments
+		for _, lacing := range table {
+			if data+int(lacing) > len(buf) {
+				break
+			}
+			current = append(current, buf[data:data+int(lacing)]...)
+			data += int(lacing)
+			// A lacing value under 255 ends the packet
+			if lacing < 255 {
+				packets = append(packets, current)
+				current = nil
+			}
+		}
+		p = data
+	}
+	if len(packets) == 0 {
+		return nil
+	}
+
+	info := &mediaInfo{tags: make(map[string]string)}
+	id := packets[0]
+	granuleRate := 0
+	switch {
+	case bytes.HasPrefix(id, []byte("\x01vorbis")) && len(id) >= 24:
+		info.format, info.codec = "Ogg Vorbis", "Vorbis"
+		info.channels = int(id[11])
+		info.sampleRate = int(binary.LittleEndian.Uint32(id[12:]))
+		granuleRate = info.sampleRate
+	case bytes.HasPrefix(id, []byte("OpusHead")) && len(id) >= 16:
+		info.format, info.codec = "Opus", "Opus"
+		info.channels = int(id[9])
+		info.sampleRate = int(binary.LittleEndian.Uint32(id[12:]))
+		// Opus granule positions always count 48 kHz samples
+		granuleRate = 48000
+	case bytes.HasPrefix(id, []byte("\x7FFLAC")):
+		info.format, info.codec = "Ogg FLAC", "FLAC"
+	case bytes.HasPrefix(id, []byte("\x80theora")):
+		info.format, info.codec, info.video = "Ogg Theora", "Theora", true
+	default:
+		info.format = "Ogg"
+	}
+
+	if len(packets) > 1 {
+		switch comments := packets[1]; {
+		case bytes.HasPrefix(comments, []byte("\x03vorbis")):
+			readVorbisComments(comments[7:], info.tags)
+		case bytes.HasPrefix(comments, []byte("OpusTags")):
+			readVorbisComments(comments[8:], info.tags)
+		}
+	}
+
+	if granuleRate > 0 {
+		tail := make([]byte, min(int(size), 64*1024))
+		if _, err := r.ReadAt(tail, size-int64(len(tail))); err == nil || err == io.EOF {
+			if i := bytes.LastIndex(tail, []byte("OggS")); i >= 0 && i+14 <= len(tail) {
+				granule := binary.LittleEndian.Uint64(tail[i+6:])
+				info.duration = float64(granule) / float64(granuleRate)
+			}
+		}
+	}
+	info.bitrate = averageBitrate(size, info.duration)
+	return info
+}
+
+// wavMedia reads the fmt, data and LIST/INFO chunks of a RIFF WAVE file
+func wavMedia(r io.ReaderAt, size int64) *mediaInfo {
+	info := &mediaInfo{format: "WAV", tags: make(map[string]string)}
+	var byteRate, dataSize int64
+	offset := int64(12)
+	chunk := make([]byte, 8)
+	for {
+		if _, err := r.ReadAt(chunk, offset); err != nil {
+			break
+		}
+		length := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		switch string(chunk[:4]) {
+		case "fmt ":
+			f := make([]byte, 16)
+			if _, err := r.ReadAt(f, offset+8); err == nil {
+				switch format := binary.LittleEndian.Uint16(f); format {
+				case 1, 0xFFFE:
+					info.codec = "PCM"
+				case 3:
+					info.codec = "PCM (float)"
+				case 0x55:
+					info.codec = "MP3"
+				default:
+					info.codec = fmt.Sprintf("format 0x%04X", format)
+				}
+				info.channels = int(binary.LittleEndian.Uint16(f[2:]))
+				info.sampleRate = int(binary.LittleEndian.Uint32(f[4:]))
+				byteRate = int64(binary.LittleEndian.Uint32(f[8:]))
+			}
+		case "data":
+			dataSize = length
+			if remaining := size - offset - 8; remaining < dataSize {
+				dataSize = remaining // truncated recordings
+			}
+		case "LIST":
+			if length < 1024*1024 {
+				list := make([]byte, length)
+				if _, err := r.ReadAt(list, offset+8); err == nil && bytes.HasPrefix(list, []byte("INFO")) {
+					readRIFFInfo(list[4:], info.tags)
+				}
+			}
+		}
+		// Chunks are padded to an even size
+		offset += 8 + length + length%2
+	}
+
+	if byteRate > 0 {
+		info.duration = float64(dataSize) / float64(byteRate)
+		info.bitrate = int(byteRate * 8 / 1000)
+	}
+	return info
+}
+
+// readRIFFInfo reads the NUL-terminated text subchunks of a LIST/INFO chunk
+func readRIFFInfo(b []byte, tags map[string]string) {
+	for len(b) >= 8 {
+		id := string(b[:4])
+		length := int(binary.LittleEndian.Uint32(b[4:]))
+		if 8+length > len(b) {
+			return
+		}
+		if key, ok := riffInfoKeys[id]; ok {
+			value := b[8 : 8+length]
+			if i := bytes.IndexByte(value, 0); i >= 0 {
+				value = value[:i]
+			}
+			tags[key] = strings.TrimSpace(decodeANSI(value))
+		}
+		b = b[min(len(b), 8+length+length%2):]
+	}
+}
+
+// mp4Media reads an MP4, M4A or QuickTime movie
+func mp4Media(r io.ReaderAt, header []byte, size int64) *mediaInfo {
+	movie := readMP4(r)
+	if movie == nil {
+		return nil
+	}
+
+	info := &mediaInfo{format: "MP4", tags: movie.tags}
+	if string(header[4:8]) == "ftyp" && len(header) >= 12 {
+		switch string(header[8:12]) {
+		case "qt  ":
+			info.format = "QuickTime"
+		case "M4A ", "M4B ":
+			info.format = "M4A"
+		}
+	}
+
+	info.duration = movie.duration
+	info.bitrate = averageBitrate(size, movie.duration)
+	if !movie.created.IsZero() && movie.created.Year() > 1970 {
+		info.created = movie.created.Format("2006-01-02 15:04:05")
+	}
+	if movie.videoCodec != "" {
+		info.video = true
+		info.codec, info.audioCodec = movie.videoCodec, movie.audioCodec
+		info.width, info.height = movie.width, movie.height
+	} else {
+		info.codec = movie.audioCodec
+		info.sampleRate, info.channels = movie.sampleRate, movie.channels
+	}
+	return info
+}
+
+// isDigits reports whether s is non-empty and made only of ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,306 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+	"unicode/utf16"
+)
+
+// be16 and be32 encode big-endian integers for box and frame bodies
+func be16(v int) []byte { return binary.BigEndian.AppendUint16(nil, uint16(v)) }
+func be32(v int) []byte { return binary.BigEndian.AppendUint32(nil, uint32(v)) }
+
+// id3Frame builds an ID3v2.3 frame (ID3v2.4 uses a syncsafe size instead)
+func id3Frame(id string, data []byte) []byte {
+	out := append([]byte(id), be32(len(data))...)
+	return append(append(out, 0, 0), data...)
+}
+
+// id3Tag wraps frames in an ID3v2 header of the given major version
+func id3Tag(version byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	size := len(body)
+	return append([]byte{'I', 'D', '3', version, 0, 0,
+		byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}, body...)
+}
+
+// utf16Frame encodes text as an ID3 UTF-16 text frame body with a BOM
+func utf16Frame(s string) []byte {
+	out := []byte{1, 0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, u)
+	}
+	return out
+}
+
+// id3v1Tag builds the 128-byte ID3v1 tag stored at the end of an MP3
+func id3v1Tag(title, artist, album, year string, genre byte) []byte {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	copy(tag[93:97], year)
+	tag[127] = genre
+	return tag
+}
+
+// sampleMP3 is an ID3v2.3 tag, an MPEG-1 layer III frame at 128 kbps and
+// 44.1 kHz carrying a Xing header for 1000 frames, then an ID3v1 tag
+func sampleMP3() []byte {
+	tag := id3Tag(3,
+		id3Frame("TIT2", append([]byte{0}, "One More Time"...)),
+		id3Frame("TPE1", utf16Frame("Daft Punk")),
+		id3Frame("TCON", append([]byte{0}, "(17)"...)),
+	)
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x64})
+	copy(frame[4+32:], "Xing")
+	copy(frame[4+32+4:], be32(1))
+	copy(frame[4+32+8:], be32(1000))
+
+	out := append(tag, frame...)
+	return append(out, id3v1Tag("Ignored", "", "Discovery", "2001", 13)...)
+}
+
+// sampleFLAC has a STREAMINFO block for 10 s of 44.1 kHz stereo and a
+// Vorbis comment block
+func sampleFLAC() []byte {
+	info := make([]byte, 34)
+	rate, channels, bits, samples := 44100, 2, 16, 441000
+	b := info[10:]
+	b[0], b[1] = byte(rate>>12), byte(rate>>4)
+	b[2] = byte(rate&0xF)<<4 | byte(channels-1)<<1 | byte(bits-1)>>4
+	b[3] = byte(bits-1)&0xF<<4 | byte(samples>>32)
+	binary.BigEndian.PutUint32(b[4:], uint32(samples))
+
+	comments := vorbisComments("ARTIST=Nina Simone", "title=Feeling Good", "DATE=1965-06-01")
+	out := append([]byte("fLaC"), 0, 0, 0, 34)
+	out = append(out, info...)
+	out = append(out, 0x84, byte(len(comments)>>16), byte(len(comments)>>8), byte(len(comments)))
+	return append(out, comments...)
+}
+
+// vorbisComments builds a Vorbis comment block
+func vorbisComments(fields ...string) []byte {
+	out := append(binary.LittleEndian.AppendUint32(nil, 4), "test"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(fields)))
+	for _, f := range fields {
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(f)))
+		out = append(out, f...)
+	}
+	return out
+}
+
+// oggPage builds an Ogg page holding whole packets shorter than 255 bytes
+func oggPage(granule uint64, packets ...[]byte) []byte {
+	out := append([]byte("OggS"), 0, 0)
+	out = binary.LittleEndian.AppendUint64(out, granule)
+	out = append(out, make([]byte, 12)...) // serial, sequence, checksum
+	out = append(out, byte(len(packets)))
+	for _, p := range packets {
+		out = append(out, byte(len(p)))
+	}
+	return append(out, bytes.Join(packets, nil)...)
+}
+
+// sampleOgg is an Ogg Vorbis stream of 10 s at 44.1 kHz
+func sampleOgg() []byte {
+	id := append([]byte("\x01vorbis"), 0, 0, 0, 0, 2)
+	id = binary.LittleEndian.AppendUint32(id, 44100)
+	id = append(id, make([]byte, 18)...)
+	comments := append([]byte("\x03vorbis"), vorbisComments("ALBUM=Kind of Blue", "GENRE=Jazz")...)
+
+	out := oggPage(0, id, comments)
+	out = append(out, oggPage(220500, make([]byte, 100))...)
+	return append(out, oggPage(441000, make([]byte, 100))...)
+}
+
+// sampleWAV is 2 s of 8 kHz mono 8-bit PCM with a LIST/INFO title
+func sampleWAV() []byte {
+	format := append(u16(1), u16(1)...)
+	format = append(format, u32(8000)...)
+	format = append(format, u32(8000)...)
+	format = append(format, u16(1)...)
+	format = append(format, u16(8)...)
+
+	info := append([]byte("INFO"), riffChunk("INAM", []byte("Field recording\x00"))...)
+	body := append([]byte("WAVE"), riffChunk("fmt ", format)...)
+	body = append(body, riffChunk("LIST", info)...)
+	body = append(body, riffChunk("data", make([]byte, 16000))...)
+	return append(append([]byte("RIFF"), u32(len(body))...), body...)
+}
+
+// mp4Track builds a trak box with a handler and one sample entry
+func mp4Track(handler, codec string, tkhd, entryBody []byte) []byte {
+	hdlr := isoBox("hdlr", make([]byte, 8), []byte(handler), make([]byte, 12))
+	entry := isoBox(codec, entryBody)
+	stsd := isoBox("stsd", make([]byte, 4), be32(1), entry)
+	mdia := isoBox("mdia", hdlr, isoBox("minf", isoBox("stbl", stsd)))
+	if tkhd == nil {
+		return isoBox("trak", mdia)
+	}
+	return isoBox("trak", isoBox("tkhd", tkhd), mdia)
+}
+
+// sampleMP4 is a movie with an H.264 video track, an AAC audio track and
+// iTunes tags, with moov after mdat as cameras write it
+func sampleMP4(created time.Time) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[4:], uint32(created.Sub(mp4Epoch)/time.Second))
+	binary.BigEndian.PutUint32(mvhd[12:], 600)
+	binary.BigEndian.PutUint32(mvhd[16:], 600*95)
+
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], 1920<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], 1080<<16)
+	video := mp4Track("vide", "avc1", tkhd, make([]byte, 70))
+
+	audioEntry := make([]byte, 28)
+	binary.BigEndian.PutUint16(audioEntry[16:], 2)
+	binary.BigEndian.PutUint32(audioEntry[24:], 48000<<16)
+	audio := mp4Track("soun", "mp4a", nil, audioEntry)
+
+	data := func(s string) []byte { return isoBox("data", be32(1), be32(0), []byte(s)) }
+	ilst := isoBox("ilst",
+		isoBox("\xA9nam", data("Holiday")),
+		isoBox("gnre", isoBox("data", be32(0), be32(0), be16(18))), // Rock
+	)
+	udta := isoBox("udta", isoBox("meta", make([]byte, 4), ilst))
+
+	out := isoBox("ftyp", []byte("isom"), be32(0))
+	out = append(out, isoBox("mdat", make([]byte, 64))...)
+	return append(out, isoBox("moov", isoBox("mvhd", mvhd), video, audio, udta)...)
+}
+
+// extractMedia runs MediaExtractor on data held in memory
+func extractMedia(data []byte) (*ExtractedContent, error) {
+	return MediaExtractor{}.Extract(fstest.MapFS{"media": {Data: data}}, "media")
+}
+
+func TestMediaExtract(t *testing.T) {
+	created := time.Date(2023, 7, 14, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		data     []byte
+		category string
+		want     map[string]any
+	}{
+		{"mp3", sampleMP3(), "audio", map[string]any{
+			"format": "MP3", "codec": "MP3", "duration_seconds": 26, "bitrate_kbps": 0,
+			"sample_rate": 44100, "channels": 2,
+			"title": "One More Time", "artist": "Daft Punk", "album": "Discovery", "year": "2001", "genre": "Rock",
+		}},
+		{"flac", sampleFLAC(), "audio", map[string]any{
+			"format": "FLAC", "codec": "FLAC", "duration_seconds": 10, "bitrate_kbps": 0,
+			"sample_rate": 44100, "channels": 2,
+			"title": "Feeling Good", "artist": "Nina Simone", "year": "1965",
+		}},
+		{"ogg", sampleOgg(), "audio", map[string]any{
+			"format": "Ogg Vorbis", "codec": "Vorbis", "duration_seconds": 10, "bitrate_kbps": 0,
+			"sample_rate": 44100, "channels": 2, "album": "Kind of Blue", "genre": "Jazz",
+		}},
+		{"wav", sampleWAV(), "audio", map[string]any{
+			"format": "WAV", "codec": "PCM", "duration_seconds": 2, "bitrate_kbps": 64,
+			"sample_rate": 8000, "channels": 1, "title": "Field recording",
+		}},
+		{"mp4", sampleMP4(created), "video", map[string]any{
+			"format": "MP4", "codec": "H.264", "audio_codec": "AAC", "duration_seconds": 95, "bitrate_kbps": 0,
+			"width": 1920, "height": 1080, "created": "2023-07-14 09:30:00", "title": "Holiday", "genre": "Rock",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := extractMedia(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content.Category != tt.category {
+				t.Errorf("category = %s, want %s", content.Category, tt.category)
+			}
+			// The bitrate of these tiny samples is not meaningful unless set
+			if tt.want["bitrate_kbps"] == 0 {
+				delete(content.Details, "bitrate_kbps")
+				delete(tt.want, "bitrate_kbps")
+			}
+			if !reflect.DeepEqual(content.Details, tt.want) {
+				t.Errorf("details = %v\nwant %v", content.Details, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaPreview(t *testing.T) {
+	content, err := extractMedia(sampleMP3())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "MP3 0:26, Daft Punk - One More Time (Discovery, 2001)"; content.Preview != want {
+		t.Errorf("preview = %q, want %q", content.Preview, want)
+	}
+}
+
+func TestMediaExtractTruncated(t *testing.T) {
+	samples := [][]byte{sampleMP3(), sampleFLAC(), sampleOgg(), sampleWAV(), sampleMP4(time.Now())}
+	// Every prefix must give a result or an error, never a panic
+	for _, data := range samples {
+		for n := range len(data) {
+			extractMedia(data[:n])
+		}
+	}
+}
+
+func TestReadID3v2Versions(t *testing.T) {
+	v22 := append([]byte("ID3\x02\x00\x00\x00\x00\x00\x0E"), "TT2\x00\x00\x06\x00Title"...)
+	v22 = append(v22, 0, 0, 0, 0)
+	if tags, size := readID3v2(bytes.NewReader(v22)); tags["title"] != "Title" || size != 24 {
+		t.Errorf("v2.2: tags = %v, size = %d, want title and size 24", tags, size)
+	}
+
+	// v2.4 frame sizes are syncsafe: 200 bytes is stored as 0x01 0x48
+	long := append([]byte{3}, bytes.Repeat([]byte("a"), 199)...)
+	frame := append([]byte("TALB\x00\x00\x01\x48\x00\x00"), long...)
+	if tags, _ := readID3v2(bytes.NewReader(id3Tag(4, frame))); len(tags["album"]) != 199 {
+		t.Errorf("v2.4: album length = %d, want 199", len(tags["album"]))
+	}
+
+	// Unsynchronisation inserts a zero after each 0xFF; the v2.3 frame size
+	// counts the bytes before it was applied
+	unsync := id3Tag(3, append([]byte("TIT2"), 0, 0, 0, 4, 0, 0, 0, 'x', 0xFF, 0x00, 'y'))
+	unsync[5] = 0x80
+	if tags, _ := readID3v2(bytes.NewReader(unsync)); tags["title"] != "xÿy" {
+		t.Errorf("unsynchronised: title = %q, want %q", tags["title"], "xÿy")
+	}
+
+	if tags, size := readID3v2(bytes.NewReader([]byte("ID3\x05\x00\x00\x00\x00\x00\x00"))); tags != nil || size != 10 {
+		t.Errorf("unknown version: tags = %v, size = %d, want none and 10", tags, size)
+	}
+}
+
+func TestID3GenreName(t *testing.T) {
+	tests := map[string]string{
+		"(17)":      "Rock",
+		"17":        "Rock",
+		"(17)Rocks": "Rocks",
+		"(999)":     "(999)",
+		"Synthwave": "Synthwave",
+	}
+	for genre, want := range tests {
+		if got := id3GenreName(genre); got != want {
+			t.Errorf("id3GenreName(%q) = %q, want %q", genre, got, want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[float64]string{0: "0:00", 59.6: "1:00", 225: "3:45", 3723: "1:02:03"}
+	for seconds, want := range tests {
+		if got := formatDuration(seconds); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", seconds, got, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// memFS is a read-only in-memory file system, used for the files read out
+// of a tarball. Parent directories are created as files are added, so the
+// tree can be walked like a directory on disk.
+type memFS struct {
+	entries  map[string]*memEntry // by slash-separated path; "." is the root
+	children map[string][]string  // base names of each directory's entries
+}
+
+// memEntry is a file or directory of a memFS
+type memEntry struct {
+	name    string // base name
+	data    []byte
+	dir     bool
+	modTime time.Time
+}
+
+// newMemFS returns a memFS holding only its root directory
+func newMemFS() *memFS {
+	return &memFS{
+		entries:  map[string]*memEntry{".": {name: ".", dir: true}},
+		children: make(map[string][]string),
+	}
+}
+
+// addFile stores a file, creating its parent directories
+func (m *memFS) addFile(name string, data []byte, modTime time.Time) {
+	m.add(name, &memEntry{name: path.Base(name), data: data, modTime: modTime})
+}
+
+// addDir records a directory explicitly listed in the archive, keeping its
+// modification time
+func (m *memFS) addDir(name string, modTime time.Time) {
+	if entry, ok := m.entries[name]; ok {
+		entry.modTime = modTime
+		return
+	}
+	m.add(name, &memEntry{name: path.Base(name), dir: true, modTime: modTime})
+}
+
+// add links entry into the tree under its parent directory
+func (m *memFS) add(name string, entry *memEntry) {
+	if _, exists := m.entries[name]; exists {
+		// A later tar entry with the same name replaces the earlier one
+		m.entries[name] = entry
+		return
+	}
+	parent := path.Dir(name)
+	if _, ok := m.entries[parent]; !ok {
+		m.add(parent, &memEntry{name: path.Base(parent), dir: true, modTime: entry.modTime})
+	}
+	m.entries[name] = entry
+	m.children[parent] = append(m.children[parent], entry.name)
+}
+
+// Open opens the named file or directory
+func (m *memFS) Open(name string) (fs.File, error) {
+	entry, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.dir {
+		return &memDir{entry: entry, fsys: m, path: name}, nil
+	}
+	return &memFile{entry: entry, Reader: bytes.NewReader(entry.data)}, nil
+}
+
+// ReadDir lists a directory sorted by name, as fs.ReadDir expects
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	names := append([]string(nil), m.children[name]...)
+	sort.Strings(names)
+	list := make([]fs.DirEntry, len(names))
+	for i, child := range names {
+		list[i] = fs.FileInfoToDirEntry(m.entries[path.Join(name, child)].info())
+	}
+	return list, nil
+}
+
+// lookup finds the entry for a valid path
+func (m *memFS) lookup(op, name string) (*memEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := m.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entry, nil
+}
+
+// info describes the entry for Stat and ReadDir
+func (e *memEntry) info() fs.FileInfo {
+	return memInfo{e}
+}
+
+// memInfo implements fs.FileInfo for a memEntry
+type memInfo struct{ entry *memEntry }
+
+func (i memInfo) Name() string       { return i.entry.name }
+func (i memInfo) Size() int64        { return int64(len(i.entry.data)) }
+func (i memInfo) ModTime() time.Time { return i.entry.modTime }
+func (i memInfo) IsDir() bool        { return i.entry.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.entry.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// memFile is an open memFS file; it supports ReadAt and Seek so the
+// formats that need random access can read it in place
+type memFile struct {
+	*bytes.Reader
+	entry *memEntry
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.entry.info(), nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an open memFS directory
+type memDir struct {
+	entry  *memEntry
+	fsys   *memFS
+	path   string
+	offset int // entries already returned by ReadDir
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.entry.info(), nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir returns the next n entries of the directory, or all remaining
+// entries when n <= 0
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	list, err := d.fsys.ReadDir(d.path)
+	if err != nil {
+		return nil, err
+	}
+	rest := list[d.offset:]
+	if n > 0 {
+		if len(rest) == 0 {
+			return nil, io.EOF
+		}
+		rest = rest[:min(n, len(rest))]
+	}
+	d.offset += len(rest)
+	return rest, nil
+}
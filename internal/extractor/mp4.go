@@ -0,0 +1,214 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxMoovSize bounds the MP4 movie box read into memory; it holds track
+// tables and tags, never the media itself
+const maxMoovSize = 32 * 1024 * 1024
+
+// mp4Epoch is the start of MP4/QuickTime timestamps
+var mp4Epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mp4Tags maps iTunes-style ilst atoms to detail keys
+var mp4Tags = map[string]string{
+	"\xA9nam": "title",
+	"\xA9ART": "artist",
+	"aART":    "artist",
+	"\xA9alb": "album",
+	"\xA9day": "year",
+	"\xA9gen": "genre",
+}
+
+// mp4Codecs names common sample entry types
+var mp4Codecs = map[string]string{
+	"avc1": "H.264", "avc3": "H.264", "hvc1": "HEVC", "hev1": "HEVC",
+	"av01": "AV1", "vp09": "VP9", "mp4v": "MPEG-4 Visual", "apcn": "ProRes",
+	"apch": "ProRes", "apcs": "ProRes", "ap4h": "ProRes", "jpeg": "Motion JPEG",
+	"mp4a": "AAC", "alac": "ALAC", "ac-3": "AC-3", "ec-3": "E-AC-3",
+	"Opus": "Opus", "fLaC": "FLAC", "lpcm": "PCM", "sowt": "PCM", "twos": "PCM",
+}
+
+// mp4Info is what readMP4 finds in the movie box
+type mp4Info struct {
+	duration   float64 // seconds
+	created    time.Time
+	width      int
+	height     int
+	videoCodec string
+	audioCodec string
+	sampleRate int
+	channels   int
+	tags       map[string]string
+}
+
+// readMP4 locates the moov box (before or after the media data) and reads
+// the movie header, track headers, sample descriptions and tags
+func readMP4(r io.ReaderAt) *mp4Info {
+	var moov []byte
+	offset := int64(0)
+	header := make([]byte, 16)
+	for moov == nil {
+		n, _ := r.ReadAt(header, offset)
+		if n < 8 {
+			return nil
+		}
+		size := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		if size == 1 && n == 16 {
+			size, headerSize = int64(binary.BigEndian.Uint64(header[8:])), 16
+		}
+		if size < headerSize {
+			return nil
+		}
+		if string(header[4:8]) == "moov" {
+			if size > maxMoovSize {
+				return nil
+			}
+			moov = make([]byte, size-headerSize)
+			if _, err := r.ReadAt(moov, offset+headerSize); err != nil {
+				return nil
+			}
+		}
+		offset += size
+	}
+
+	info := &mp4Info{tags: make(map[string]string)}
+	forEachBox(moov, func(typ string, payload []byte) {
+		switch typ {
+		case "mvhd":
+			info.readMovieHeader(payload)
+		case "trak":
+			info.readTrack(payload)
+		case "udta":
+			info.readUserData(payload)
+		}
+	})
+	return info
+}
+
+// readMovieHeader reads the creation time and duration from mvhd
+func (info *mp4Info) readMovieHeader(mvhd []byte) {
+	var created, timescale, duration uint64
+	switch {
+	case len(mvhd) >= 32 && mvhd[0] == 1:
+		created = binary.BigEndian.Uint64(mvhd[4:])
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:]))
+		duration = binary.BigEndian.Uint64(mvhd[24:])
+	case len(mvhd) >= 20:
+		created = uint64(binary.BigEndian.Uint32(mvhd[4:]))
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:]))
+	default:
+		return
+	}
+	if timescale > 0 {
+		info.duration = float64(duration) / float64(timescale)
+	}
+	// Unset creation times are zero, i.e. 1904
+	if created > 0 {
+		info.created = mp4Epoch.Add(time.Duration(created) * time.Second)
+	}
+}
+
+// readTrack reads a trak box: its handler says whether it is video or
+// audio, tkhd holds the display size and stsd the codec
+func (info *mp4Info) readTrack(trak []byte) {
+	var handler, codec string
+	var width, height, sampleRate, channels int
+	forEachBox(trak, func(typ string, payload []byte) {
+		switch typ {
+		case "tkhd":
+			// Width and height are 16.16 fixed point at the end of the box
+			if len(payload) >= 84 {
+				width = int(binary.BigEndian.Uint32(payload[len(payload)-8:]) >> 16)
+				height = int(binary.BigEndian.Uint32(payload[len(payload)-4:]) >> 16)
+			}
+		case "mdia":
+			forEachBox(payload, func(typ string, payload []byte) {
+				switch typ {
+				case "hdlr":
+					if len(payload) >= 12 {
+						handler = string(payload[8:12])
+					}
+				case "minf":
+					forEachBox(payload, func(typ string, payload []byte) {
+						if typ != "stbl" {
+							return
+						}
+						forEachBox(payload, func(typ string, payload []byte) {
+							// stsd is a full box with an entry count before its sample entries
+							if typ != "stsd" || len(payload) < 16 {
+								return
+							}
+							entry := payload[8:]
+							codec = string(entry[4:8])
+							// Audio sample entries hold the channel count at 24 and the rate (16.16) at 32
+							if len(entry) >= 36 {
+								channels = int(binary.BigEndian.Uint16(entry[24:]))
+								sampleRate = int(binary.BigEndian.Uint32(entry[32:]) >> 16)
+							}
+						})
+					})
+				}
+			})
+		}
+	})
+
+	name := mp4Codecs[codec]
+	if name == "" {
+		name = strings.TrimSpace(codec)
+	}
+	switch handler {
+	case "vide":
+		if info.videoCodec == "" {
+			info.videoCodec = name
+			info.width, info.height = width, height
+		}
+	case "soun":
+		if info.audioCodec == "" {
+			info.audioCodec = name
+			info.sampleRate, info.channels = sampleRate, channels
+		}
+	}
+}
+
+// readUserData reads iTunes-style tags from udta/meta/ilst
+func (info *mp4Info) readUserData(udta []byte) {
+	forEachBox(udta, func(typ string, meta []byte) {
+		if typ != "meta" {
+			return
+		}
+		// MP4 meta is a full box; QuickTime's is a plain box starting with hdlr
+		if len(meta) >= 8 && string(meta[4:8]) != "hdlr" {
+			meta = meta[4:]
+		}
+		forEachBox(meta, func(typ string, ilst []byte) {
+			if typ != "ilst" {
+				return
+			}
+			forEachBox(ilst, func(tag string, item []byte) {
+				forEachBox(item, func(typ string, data []byte) {
+					// data boxes start with a type indicator and a locale
+					if typ != "data" || len(data) < 8 {
+						return
+					}
+					value := data[8:]
+					if key, ok := mp4Tags[tag]; ok && info.tags[key] == "" {
+						info.tags[key] = strings.TrimSpace(string(value))
+					}
+					// gnre holds an ID3v1 genre number plus one
+					if tag == "gnre" && len(value) == 2 && info.tags["genre"] == "" {
+						if n := int(binary.BigEndian.Uint16(value)); n > 0 && n <= len(id3Genres) {
+							info.tags["genre"] = id3Genres[n-1]
+						}
+					}
+				})
+			})
+		})
+	})
+}
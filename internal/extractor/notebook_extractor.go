@@ -0,0 +1,318 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// NotebookExtractor parses Jupyter notebooks (.ipynb) cell by cell instead
+// of previewing their raw JSON
+type NotebookExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+const (
+	maxNotebookHeadings = 20
+	maxNotebookErrors   = 5
+)
+
+// notebook is the part of the nbformat 4 (and 3) schema Scout reads
+type notebook struct {
+	NBFormat      int              `json:"nbformat"`
+	NBFormatMinor int              `json:"nbformat_minor"`
+	Cells         []notebookCell   `json:"cells"`
+	Worksheets    []notebookSheet  `json:"worksheets"` // nbformat 3
+	Metadata      notebookMetadata `json:"metadata"`
+}
+
+type notebookSheet struct {
+	Cells []notebookCell `json:"cells"`
+}
+
+type notebookMetadata struct {
+	KernelSpec struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"display_name"`
+		Language    string `json:"language"`
+	} `json:"kernelspec"`
+	LanguageInfo struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"language_info"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Input    notebookText     `json:"input"`    // nbformat 3 code cells
+	Language string           `json:"language"` // nbformat 3 code cells
+	Level    int              `json:"level"`    // nbformat 3 heading cells
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                     `json:"output_type"`
+	Data       map[string]json.RawMessage `json:"data"`
+	EName      string                     `json:"ename"`
+	EValue     string                     `json:"evalue"`
+	PNG        json.RawMessage            `json:"png"`  // nbformat 3
+	JPEG       json.RawMessage            `json:"jpeg"` // nbformat 3
+}
+
+// notebookText is cell source, stored either as one string or as a list of lines
+type notebookText string
+
+// UnmarshalJSON accepts both forms of multi-line notebook text
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = notebookText(s)
+	return nil
+}
+
+var (
+	// rImportRe matches library(x), require(x) and requireNamespace("x")
+	rImportRe = regexp.MustCompile(`\b(?:library|require|requireNamespace)\(\s*["']?([\w.]+)`)
+	// markdownHeadingRe matches ATX headings ("## Results")
+	markdownHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+)
+
+// Extract parses a notebook's cells, kernel and outputs
+func (e NotebookExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, fmt.Errorf("not a valid notebook: %v", err)
+	}
+	cells := nb.Cells
+	for _, sheet := range nb.Worksheets {
+		cells = append(cells, sheet.Cells...)
+	}
+
+	language := strings.ToLower(nb.Metadata.KernelSpec.Language)
+	if language == "" {
+		language = strings.ToLower(nb.Metadata.LanguageInfo.Name)
+	}
+	kernel := nb.Metadata.KernelSpec.DisplayName
+	if kernel == "" {
+		kernel = nb.Metadata.KernelSpec.Name
+	}
+
+	var (
+		codeCells, markdownCells, rawCells int
+		lines                              int
+		headings, imports, failures        []string
+		title                              string
+		errorCount, imageOutputs           int
+		preview                            strings.Builder
+	)
+	seenImports := make(map[string]bool)
+
+	for _, cell := range cells {
+		source := string(cell.Source)
+		if cell.CellType == "code" && source == "" {
+			source = string(cell.Input)
+		}
+		source = strings.TrimRight(source, "\n")
+		if source != "" {
+			lines += strings.Count(source, "\n") + 1
+		}
+
+		// nbformat 3 keeps headings in cells of their own
+		if cell.CellType == "heading" && cell.Level > 0 {
+			cell.CellType = "markdown"
+			source = strings.Repeat("#", min(cell.Level, 6)) + " " + source
+		}
+
+		switch cell.CellType {
+		case "markdown":
+			markdownCells++
+			for _, heading := range markdownHeadings(source) {
+				if title == "" && strings.HasPrefix(heading, "# ") {
+					title = strings.TrimPrefix(heading, "# ")
+				}
+				if len(headings) < maxNotebookHeadings {
+					headings = append(headings, strings.TrimLeft(heading, "# "))
+				}
+			}
+			fmt.Fprintf(&preview, "%s\n\n", source)
+
+		case "code":
+			codeCells++
+			if language == "" {
+				language = strings.ToLower(cell.Language)
+			}
+			for _, module := range notebookImports(source, language) {
+				if !seenImports[module] {
+					seenImports[module] = true
+					imports = append(imports, module)
+				}
+			}
+			for _, out := range cell.Outputs {
+				switch {
+				case out.OutputType == "error" || out.OutputType == "pyerr":
+					errorCount++
+					if len(failures) < maxNotebookErrors {
+						failures = append(failures, strings.TrimSuffix(out.EName+": "+firstLine(out.EValue), ": "))
+					}
+				case hasImageOutput(out):
+					imageOutputs++
+				}
+			}
+			fmt.Fprintf(&preview, "In [%d]:\n%s\n\n", codeCells, source)
+
+		case "raw":
+			rawCells++
+		}
+	}
+
+	// Notebooks without an H1 are often titled by their first heading
+	if title == "" && len(headings) > 0 {
+		title = headings[0]
+	}
+
+	details := map[string]any{
+		"type":           "jupyter",
+		"nbformat":       fmt.Sprintf("%d.%d", nb.NBFormat, nb.NBFormatMinor),
+		"code_cells":     codeCells,
+		"markdown_cells": markdownCells,
+		"has_errors":     errorCount > 0,
+		"has_images":     imageOutputs > 0,
+	}
+	if rawCells > 0 {
+		details["raw_cells"] = rawCells
+	}
+	if kernel != "" {
+		details["kernel"] = kernel
+	}
+	if language != "" {
+		details["language"] = language
+	}
+	if version := nb.Metadata.LanguageInfo.Version; version != "" {
+		details["language_version"] = version
+	}
+	if title != "" {
+		details["title"] = title
+	}
+	if len(headings) > 0 {
+		details["headings"] = headings
+	}
+	if len(imports) > 0 {
+		details["imports"] = imports
+	}
+	if errorCount > 0 {
+		details["error_count"] = errorCount
+		details["errors"] = failures
+	}
+	if imageOutputs > 0 {
+		details["image_outputs"] = imageOutputs
+	}
+
+	cellCounts := plural(codeCells, "code cell") + ", " + plural(markdownCells, "markdown cell")
+	summary := "Jupyter notebook: " + cellCounts
+	if kernel != "" {
+		summary = fmt.Sprintf("Jupyter notebook (%s): %s", kernel, cellCounts)
+	}
+
+	return &ExtractedContent{
+		Category: "notebook",
+		Preview:  truncatePreview(summary+"\n\n"+preview.String(), previewLimit(e.Limit)),
+		Lines:    lines,
+		Details:  details,
+	}, nil
+}
+
+// markdownHeadings returns the ATX headings of a markdown cell with their
+// leading #s, skipping fenced code blocks
+func markdownHeadings(source string) []string {
+	var headings []string
+	fenced := false
+	for line := range strings.SplitSeq(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if m := markdownHeadingRe.FindStringSubmatch(trimmed); m != nil && !fenced {
+			headings = append(headings, m[1]+" "+m[2])
+		}
+	}
+	return headings
+}
+
+// notebookImports returns the top-level libraries a code cell loads:
+// Python imports, R library() calls and Julia using/import statements
+func notebookImports(source, language string) []string {
+	var modules []string
+	add := func(module string) {
+		module = strings.TrimSpace(module)
+		// Only the top-level package matters ("sklearn" for sklearn.metrics)
+		if i := strings.IndexAny(module, ".:"); i >= 0 && language != "r" {
+			module = module[:i]
+		}
+		if module != "" {
+			modules = append(modules, module)
+		}
+	}
+
+	if language == "r" {
+		for _, m := range rImportRe.FindAllStringSubmatch(source, -1) {
+			add(m[1])
+		}
+		return modules
+	}
+
+	for line := range strings.SplitSeq(source, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "from "):
+			// Relative imports ("from .utils import x") name no library
+			if module, _, ok := strings.Cut(strings.TrimPrefix(line, "from "), " import"); ok && !strings.HasPrefix(module, ".") {
+				add(module)
+			}
+		case strings.HasPrefix(line, "import "), language == "julia" && strings.HasPrefix(line, "using "):
+			_, list, _ := strings.Cut(line, " ")
+			// "using Plots: plot" lists names after the colon
+			list, _, _ = strings.Cut(list, ":")
+			for item := range strings.SplitSeq(list, ",") {
+				module, _, _ := strings.Cut(strings.TrimSpace(item), " as ")
+				add(module)
+			}
+		}
+	}
+	return modules
+}
+
+// hasImageOutput reports whether a cell output is a rendered image
+// (a plot or figure)
+func hasImageOutput(out notebookOutput) bool {
+	if out.PNG != nil || out.JPEG != nil {
+		return true
+	}
+	for mime := range out.Data {
+		if strings.HasPrefix(mime, "image/") {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,98 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// extractNotebook runs NotebookExtractor on a notebook held in memory
+func extractNotebook(t *testing.T, data string) *ExtractedContent {
+	t.Helper()
+	content, err := NotebookExtractor{}.Extract(fstest.MapFS{"nb.ipynb": {Data: []byte(data)}}, "nb.ipynb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestNotebookExtract(t *testing.T) {
+	content := extractNotebook(t, `{
+  "nbformat": 4, "nbformat_minor": 5,
+  "metadata": {
+    "kernelspec": {"name": "python3", "display_name": "Python 3", "language": "python"},
+    "language_info": {"name": "python", "version": "3.11.4"}
+  },
+  "cells": [
+    {"cell_type": "markdown", "source": ["# Churn analysis\n", "\n", "## Load data\n", "`+"```"+`\n", "# not a heading\n", "`+"```"+`"]},
+    {"cell_type": "code", "source": "import pandas as pd, numpy as np\nfrom sklearn.metrics import f1_score\nfrom .utils import helper", "outputs": [
+      {"output_type": "display_data", "data": {"image/png": "iVBOR", "text/plain": "<Figure>"}}
+    ]},
+    {"cell_type": "code", "source": ["df.fit()"], "outputs": [
+      {"output_type": "error", "ename": "NameError", "evalue": "name 'df' is not defined\nmore"}
+    ]},
+    {"cell_type": "raw", "source": "notes"}
+  ]
+}`)
+
+	want := map[string]any{
+		"type": "jupyter", "nbformat": "4.5",
+		"code_cells": 2, "markdown_cells": 1, "raw_cells": 1,
+		"kernel": "Python 3", "language": "python", "language_version": "3.11.4",
+		"title": "Churn analysis", "headings": []string{"Churn analysis", "Load data"},
+		"imports":     []string{"pandas", "numpy", "sklearn"},
+		"has_errors":  true,
+		"error_count": 1,
+		"errors":      []string{"NameError: name 'df' is not defined"},
+		"has_images":  true, "image_outputs": 1,
+	}
+	if !reflect.DeepEqual(content.Details, want) {
+		t.Errorf("details = %v\nwant %v", content.Details, want)
+	}
+	if content.Lines != 11 {
+		t.Errorf("lines = %d, want 11", content.Lines)
+	}
+}
+
+func TestNotebookExtractV3(t *testing.T) {
+	content := extractNotebook(t, `{
+  "nbformat": 3, "nbformat_minor": 0, "metadata": {},
+  "worksheets": [{"cells": [
+    {"cell_type": "heading", "level": 2, "source": "Setup"},
+    {"cell_type": "code", "language": "python", "input": ["import os"], "outputs": [
+      {"output_type": "pyerr", "ename": "OSError"},
+      {"output_type": "display_data", "png": "iVBOR"}
+    ]}
+  ]}]
+}`)
+	d := content.Details
+	if d["title"] != "Setup" || d["language"] != "python" || d["error_count"] != 1 || d["image_outputs"] != 1 ||
+		!reflect.DeepEqual(d["imports"], []string{"os"}) || !reflect.DeepEqual(d["errors"], []string{"OSError"}) {
+		t.Errorf("details = %v", d)
+	}
+}
+
+func TestNotebookExtractInvalid(t *testing.T) {
+	_, err := NotebookExtractor{}.Extract(fstest.MapFS{"nb.ipynb": {Data: []byte("not json")}}, "nb.ipynb")
+	if err == nil {
+		t.Error("want an error for a file that is not JSON")
+	}
+}
+
+func TestNotebookImports(t *testing.T) {
+	tests := []struct {
+		language string
+		source   string
+		want     []string
+	}{
+		{"python", "import os.path\nimport torch as t\n  from matplotlib import pyplot", []string{"os", "torch", "matplotlib"}},
+		{"python", "from . import sibling\n# import commented", nil},
+		{"r", "library(ggplot2)\nrequire('data.table')\nrequireNamespace(\"dplyr\")", []string{"ggplot2", "data.table", "dplyr"}},
+		{"julia", "using DataFrames, CSV\nusing Plots: plot\nimport Flux", []string{"DataFrames", "CSV", "Plots", "Flux"}},
+	}
+	for _, tt := range tests {
+		if got := notebookImports(tt.source, tt.language); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("notebookImports(%q, %s) = %q, want %q", tt.source, tt.language, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package extractor
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// ODTExtractor extracts text, headings and metadata from an OpenDocument text file
+type ODTExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// ODSExtractor extracts sheet previews from an OpenDocument spreadsheet
+type ODSExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// ODPExtractor extracts slide titles, text and notes from an OpenDocument presentation
+type ODPExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// maxRepeatedCells caps how often a repeated spreadsheet cell is expanded;
+// LibreOffice pads rows with e.g. 1000 repeated empty cells
+const maxRepeatedCells = 20
+
+// odfMeta is the subset of meta.xml Scout reads
+type odfMeta struct {
+	Meta struct {
+		Title          string `xml:"title"`
+		InitialCreator string `xml:"initial-creator"`
+		Creator        string `xml:"creator"`
+		CreationDate   string `xml:"creation-date"`
+		Date           string `xml:"date"`
+		Statistic      struct {
+			PageCount string `xml:"page-count,attr"`
+		} `xml:"document-statistic"`
+	} `xml:"meta"`
+}
+
+// Extract text document content
+func (e ODTExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	r, content, details, err := openODF(fsys, name, "odt")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	defer content.Close()
+
+	var (
+		paragraphs []string
+		headings   []string
+		tables     int
+		block      strings.Builder
+		depth      int
+		level      int
+	)
+
+	decoder := xml.NewDecoder(content)
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch el := t.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "h", "p":
+				if depth == 0 {
+					block.Reset()
+					level = 0
+					if el.Name.Local == "h" {
+						level = 1
+						if v := attr(el, "outline-level"); v != "" {
+							level, _ = strconv.Atoi(v)
+						}
+					}
+				}
+				depth++
+			case "s", "tab", "line-break":
+				block.WriteString(" ")
+			case "table":
+				tables++
+			}
+		case xml.CharData:
+			if depth > 0 {
+				block.Write(el)
+			}
+		case xml.EndElement:
+			if (el.Name.Local == "h" || el.Name.Local == "p") && depth > 0 {
+				depth--
+				if depth > 0 {
+					continue
+				}
+				text := strings.Join(strings.Fields(block.String()), " ")
+				if text == "" {
+					continue
+				}
+				paragraphs = append(paragraphs, text)
+				if level > 0 {
+					headings = append(headings, fmt.Sprintf("%s%s", strings.Repeat("  ", max(level-1, 0)), text))
+				}
+			}
+		}
+	}
+
+	words := 0
+	for _, p := range paragraphs {
+		words += len(strings.Fields(p))
+	}
+
+	details["headings"] = headings
+	details["table_count"] = tables
+	details["word_count"] = words
+	if _, ok := details["title"]; !ok && len(headings) > 0 {
+		details["title"] = strings.TrimSpace(headings[0])
+	}
+
+	return &ExtractedContent{
+		Category: "document",
+		Preview:  truncatePreview(strings.Join(paragraphs, "\n"), previewLimit(e.Limit)),
+		Lines:    len(paragraphs),
+		Details:  details,
+	}, nil
+}
+
+// Extract spreadsheet content
+func (e ODSExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	r, content, details, err := openODF(fsys, name, "ods")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	defer content.Close()
+
+	type sheet struct {
+		name string
+		rows [][]string
+	}
+	var (
+		sheets  []*sheet
+		current *sheet
+		row     []string
+		cell    strings.Builder
+		inCell  bool
+		repeat  int
+		rowsRep int
+	)
+
+	decoder := xml.NewDecoder(content)
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch el := t.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "table":
+				current = &sheet{name: attr(el, "name")}
+				sheets = append(sheets, current)
+			case "table-row":
+				row = nil
+				rowsRep, _ = strconv.Atoi(attr(el, "number-rows-repeated"))
+			case "table-cell", "covered-table-cell":
+				inCell = true
+				cell.Reset()
+				repeat, _ = strconv.Atoi(attr(el, "number-columns-repeated"))
+			case "p":
+				if inCell && cell.Len() > 0 {
+					cell.WriteString(" ")
+				}
+			}
+		case xml.CharData:
+			if inCell {
+				cell.Write(el)
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "table-cell", "covered-table-cell":
+				inCell = false
+				value := strings.TrimSpace(cell.String())
+				n := min(max(repeat, 1), maxRepeatedCells)
+				for range n {
+					row = append(row, value)
+				}
+			case "table-row":
+				// Drop trailing padding cells, then skip rows that are entirely empty
+				for len(row) > 0 && row[len(row)-1] == "" {
+					row = row[:len(row)-1]
+				}
+				if current == nil || len(row) == 0 {
+					continue
+				}
+				n := min(max(rowsRep, 1), maxRepeatedCells)
+				for range n {
+					current.rows = append(current.rows, row)
+				}
+			}
+		}
+	}
+
+	budget := previewLimit(e.Limit)
+	var sb strings.Builder
+	var names []string
+	totalRows := 0
+	for _, s := range sheets {
+		names = append(names, s.name)
+		totalRows += len(s.rows)
+
+		fmt.Fprintf(&sb, "Sheet: %s\n", s.name)
+		for i, row := range s.rows {
+			// Scans show six rows per sheet; a larger budget shows rows until it is filled
+			if e.Limit == 0 && i > 5 {
+				break
+			}
+			if sb.Len() >= budget {
+				break
+			}
+			sb.WriteString(strings.Join(row, " | "))
+			sb.WriteString("\n")
+		}
+	}
+
+	details["sheet_names"] = names
+	details["sheet_count"] = len(sheets)
+	details["total_rows_estimated"] = totalRows
+
+	return &ExtractedContent{
+		Category: "spreadsheet",
+		Preview:  truncatePreview(sb.String(), budget),
+		Details:  details,
+	}, nil
+}
+
+// Extract presentation content
+func (e ODPExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	r, content, details, err := openODF(fsys, name, "odp")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	defer content.Close()
+
+	var (
+		slides  []*slide
+		current *slide
+		inNotes bool
+		class   string
+		frame   []string
+		para    strings.Builder
+		inPara  int
+	)
+
+	decoder := xml.NewDecoder(content)
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch el := t.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "page":
+				current = &slide{number: len(slides) + 1}
+				slides = append(slides, current)
+			case "notes":
+				inNotes = true
+			case "frame":
+				class = attr(el, "class")
+				frame = nil
+			case "p", "h":
+				if inPara == 0 {
+					para.Reset()
+				}
+				inPara++
+			case "s", "tab", "line-break":
+				para.WriteString(" ")
+			}
+		case xml.CharData:
+			if inPara > 0 {
+				para.Write(el)
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "p", "h":
+				if inPara > 0 {
+					inPara--
+				}
+				if inPara == 0 {
+					if text := strings.Join(strings.Fields(para.String()), " "); text != "" {
+						frame = append(frame, text)
+					}
+				}
+			case "frame":
+				if current == nil {
+					continue
+				}
+				switch {
+				case inNotes:
+					current.notes = append(current.notes, frame...)
+				case class == "title":
+					current.title = strings.Join(frame, " ")
+				case class == "page-number", class == "footer", class == "header", class == "date-time":
+					// Boilerplate placeholders
+				default:
+					current.bullets = append(current.bullets, frame...)
+				}
+				frame = nil
+			case "notes":
+				inNotes = false
+			}
+		}
+	}
+
+	presentation := summarizeSlides(slides, "odp", previewLimit(e.Limit))
+	for key, value := range details {
+		if _, ok := presentation.Details[key]; !ok {
+			presentation.Details[key] = value
+		}
+	}
+
+	return presentation, nil
+}
+
+// openODF opens an OpenDocument package, reads its metadata and returns the
+// archive (to close) and an open reader on content.xml
+func openODF(fsys fs.FS, name, docType string) (io.Closer, io.ReadCloser, map[string]any, error) {
+	r, closer, err := openZip(fsys, name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	details := map[string]any{"type": docType}
+	var content io.ReadCloser
+
+	for _, f := range r.File {
+		switch f.Name {
+		case "meta.xml":
+			var meta odfMeta
+			if err := decodeZipXML(f, &meta); err == nil {
+				addODFMeta(details, meta)
+			}
+		case "content.xml":
+			if content, err = f.Open(); err != nil {
+				closer.Close()
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	if content == nil {
+		closer.Close()
+		return nil, nil, nil, fmt.Errorf("content.xml missing from %s file", docType)
+	}
+
+	return closer, content, details, nil
+}
+
+// addODFMeta copies the non-empty metadata fields into details
+func addODFMeta(details map[string]any, meta odfMeta) {
+	m := meta.Meta
+	author := m.InitialCreator
+	if author == "" {
+		author = m.Creator
+	}
+	for key, value := range map[string]string{
+		"title":    m.Title,
+		"author":   author,
+		"created":  m.CreationDate,
+		"modified": m.Date,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			details[key] = value
+		}
+	}
+	if pages, err := strconv.Atoi(m.Statistic.PageCount); err == nil {
+		details["pages"] = pages
+	}
+}
+
+// attr returns the value of the attribute with the given local name
+func attr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
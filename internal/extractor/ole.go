@@ -0,0 +1,118 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/richardlehane/mscfb"
+	"github.com/richardlehane/msoleps"
+	"github.com/richardlehane/msoleps/types"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// summaryInformationStream is the OLE property set holding title, author
+// and dates (mscfb strips its leading \x05 into File.Initial)
+const summaryInformationStream = "SummaryInformation"
+
+// readOLEStreams opens an OLE compound file (the container behind legacy
+// .doc and .xls files) and reads the named top-level streams into memory.
+// Streams that are missing are left out of the returned map. Summary
+// information (title, author, dates) is returned as extractor details.
+func readOLEStreams(fsys fs.FS, name string, names ...string) (map[string][]byte, map[string]any, error) {
+	f, err := openRandomAccess(fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	doc, err := mscfb.New(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not an OLE compound file: %v", err)
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, stream := range names {
+		wanted[stream] = true
+	}
+
+	streams := make(map[string][]byte)
+	details := make(map[string]any)
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		// Only root-level streams matter; embedded objects live in storages
+		if len(entry.Path) > 0 {
+			continue
+		}
+		switch {
+		case entry.Name == summaryInformationStream && msoleps.IsMSOLEPS(entry.Initial):
+			if props, err := msoleps.NewFrom(entry); err == nil {
+				addSummaryInformation(details, props)
+			}
+		case wanted[entry.Name]:
+			data, err := io.ReadAll(entry)
+			if err != nil {
+				return nil, nil, fmt.Errorf("reading %s stream: %v", entry.Name, err)
+			}
+			streams[entry.Name] = data
+		}
+	}
+
+	return streams, details, nil
+}
+
+// addSummaryInformation copies the useful SummaryInformation properties into details
+func addSummaryInformation(details map[string]any, props *msoleps.Reader) {
+	keys := map[string]string{
+		"Title":        "title",
+		"Subject":      "subject",
+		"Author":       "author",
+		"LastAuthor":   "last_author",
+		"CreateTime":   "created",
+		"LastSaveTime": "modified",
+		"AppName":      "application",
+	}
+
+	for _, prop := range props.Property {
+		key, ok := keys[prop.Name]
+		if !ok || prop.T == nil {
+			continue
+		}
+		switch v := prop.T.(type) {
+		case types.FileTime:
+			// Unset dates are stored as zero FILETIMEs (year 1601)
+			if t := v.Time(); t.Year() > 1980 {
+				details[key] = t.UTC().Format(time.RFC3339)
+			}
+		case *types.CodeString:
+			if text := strings.TrimSpace(decodeANSI([]byte(v.String()))); text != "" {
+				details[key] = text
+			}
+		default:
+			if text := strings.TrimSpace(prop.String()); text != "" {
+				details[key] = text
+			}
+		}
+	}
+}
+
+// decodeANSI converts 8-bit text from Office's default Windows-1252 code
+// page to UTF-8, leaving text that is already valid UTF-8 untouched
+func decodeANSI(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return decodeWindows1252(b)
+}
+
+// decodeWindows1252 converts Windows-1252 bytes to UTF-8
+func decodeWindows1252(b []byte) string {
+	out, err := charmap.Windows1252.NewDecoder().Bytes(b)
+	if err != nil {
+		return string(bytes.ToValidUTF8(b, []byte("?")))
+	}
+	return string(out)
+}
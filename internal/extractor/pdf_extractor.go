@@ -1,54 +1,128 @@
 package extractor
 
 import (
-	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/ledongthuc/pdf"
 )
 
 // PDFExtractor extracts content from a pdf file
-type PDFExtractor struct{}
+type PDFExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// Page sampling: scans look for a table of contents in the first few pages
+// and add a handful of evenly spaced pages; a larger budget samples more.
+const (
+	pdfTOCSearchPages     = 4
+	pdfTOCSearchPagesDeep = 15
+	pdfSpacedSamples      = 3
+	pdfSpacedSamplesDeep  = 10
+	maxOutlineDepth       = 3
+	maxPageTreeDepth      = 32
+)
+
+// tocLeaderRe matches table-of-contents lines such as "Introduction ..... 4"
+var tocLeaderRe = regexp.MustCompile(`(\.\s?){4,}\s*\d+`)
+
+// pdfPageSizes are common paper sizes in points (portrait)
+var pdfPageSizes = []struct {
+	name          string
+	width, height float64
+}{
+	{"A3", 842, 1191},
+	{"A4", 595, 842},
+	{"A5", 420, 595},
+	{"Letter", 612, 792},
+	{"Legal", 612, 1008},
+	{"Tabloid", 792, 1224},
+}
 
 // Extract extracts content from a pdf file
-func (e PDFExtractor) Extract(path string) (*ExtractedContent, error) {
-	f, r, err := pdf.Open(path)
+func (e PDFExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	f, err := openRandomAccess(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := pdf.NewReader(f, f.size)
 	if err != nil {
+		details := map[string]any{
+			"type":  "pdf",
+			"error": "read_failed",
+		}
+		if errors.Is(err, pdf.ErrInvalidPassword) {
+			details["encrypted"] = true
+		}
 
 		return &ExtractedContent{
 			Category: "document",
 			Preview:  "[PDF content could not be extracted - likely encrypted or unsupported format]",
-			Details: map[string]any{
-				"type":  "pdf",
-				"error": "read_failed",
-			},
+			Details:  details,
 		}, nil
 	}
-	defer f.Close()
 
-	var buf bytes.Buffer
-	limit := 3
-	if r.NumPage() < limit {
-		limit = r.NumPage()
+	details := map[string]any{"type": "pdf"}
+	numPages := addPDFMetadata(details, r)
+
+	budget := previewLimit(e.Limit)
+	tocSearch, spaced := pdfTOCSearchPages, pdfSpacedSamples
+	if e.Limit > 0 {
+		tocSearch, spaced = pdfTOCSearchPagesDeep, pdfSpacedSamplesDeep
 	}
 
-	for i := 1; i <= limit; i++ {
-		p := r.Page(i)
-		if p.V.IsNull() {
-			continue
+	// Page 1 is always read; look for a contents page right after it
+	texts := map[int]string{1: pdfPageText(r, 1)}
+	tocPage := 0
+	for i := 2; i <= min(tocSearch, numPages) && tocPage == 0; i++ {
+		texts[i] = pdfPageText(r, i)
+		if looksLikeTOC(texts[i]) {
+			tocPage = i
 		}
-
-		text, _ := p.GetPlainText(nil)
-		buf.WriteString(text)
-		buf.WriteString("\n")
+	}
+	if tocPage > 0 {
+		details["toc_page"] = tocPage
 	}
 
-	preview := buf.String()
+	pages := samplePages(numPages, tocPage, spaced)
+	details["sampled_pages"] = pages
+
+	// Share the budget between the samples; space left by short pages
+	// rolls over to the following ones
+	var sb strings.Builder
+	for i, n := range pages {
+		remaining := budget - sb.Len()
+		if remaining <= 0 {
+			break
+		}
+		text, ok := texts[n]
+		if !ok {
+			text = pdfPageText(r, n)
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
 
-	// Safety Truncation
-	if len(preview) > 1000 {
-		preview = preview[:1000] + "..."
+		share := remaining / (len(pages) - i)
+		// Label pages after the opening one so the preview still starts with the document's first line
+		if sb.Len() > 0 {
+			fmt.Fprintf(&sb, "[Page %d]\n", n)
+		}
+		sb.WriteString(truncatePreview(text, share))
+		sb.WriteString("\n")
 	}
 
+	preview := truncatePreview(sb.String(), budget)
+
 	if len(preview) == 0 {
 		preview = "[Scanned PDF or Image-based - No text extracted]"
 	}
@@ -56,9 +130,194 @@ func (e PDFExtractor) Extract(path string) (*ExtractedContent, error) {
 	return &ExtractedContent{
 		Category: "document",
 		Preview:  preview,
-		Details: map[string]any{
-			"pages": r.NumPage(),
-			"type":  "pdf",
-		},
+		Details:  details,
 	}, nil
 }
+
+// addPDFMetadata copies the page count, encryption flag, Info dictionary,
+// outline and first page size into details, and returns the page count.
+// The pdf package panics on some malformed object trees; the fields read
+// before the panic are kept.
+func addPDFMetadata(details map[string]any, r *pdf.Reader) (numPages int) {
+	defer func() {
+		recover()
+	}()
+
+	numPages = r.NumPage()
+	details["pages"] = numPages
+	details["encrypted"] = !r.Trailer().Key("Encrypt").IsNull()
+	addPDFInfo(details, r.Trailer().Key("Info"))
+
+	if outline := pdfOutline(r); len(outline) > 0 {
+		details["toc"] = outline
+	}
+	if numPages > 0 {
+		if size := pdfPageSize(r.Page(1)); size != "" {
+			details["page_size"] = size
+		}
+	}
+	return numPages
+}
+
+// samplePages picks which pages to preview: the beginning, the contents
+// page (if any) and evenly spaced pages through the rest of the document
+func samplePages(numPages, tocPage, spaced int) []int {
+	if numPages <= 0 {
+		return nil
+	}
+
+	seen := map[int]bool{1: true}
+	if numPages > 1 {
+		seen[2] = true
+	}
+	if tocPage > 0 {
+		seen[tocPage] = true
+	}
+	for k := 1; k <= spaced; k++ {
+		seen[1+(numPages-1)*k/(spaced+1)] = true
+	}
+
+	pages := make([]int, 0, len(seen))
+	for n := range seen {
+		if n <= numPages {
+			pages = append(pages, n)
+		}
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+// pdfPageText returns the plain text of page n. The pdf package panics on
+// some malformed content streams, which only loses that page's text.
+func pdfPageText(r *pdf.Reader, n int) (text string) {
+	defer func() {
+		if recover() != nil {
+			text = ""
+		}
+	}()
+
+	p := r.Page(n)
+	if p.V.IsNull() {
+		return ""
+	}
+	text, _ = p.GetPlainText(nil)
+	return text
+}
+
+// looksLikeTOC reports whether a page reads like a table of contents
+func looksLikeTOC(text string) bool {
+	head := strings.ToLower(text[:min(len(text), 200)])
+	if strings.Contains(head, "contents") {
+		return true
+	}
+	return len(tocLeaderRe.FindAllStringIndex(text, -1)) >= 5
+}
+
+// addPDFInfo copies the document Info dictionary into details
+func addPDFInfo(details map[string]any, info pdf.Value) {
+	if info.IsNull() {
+		return
+	}
+	for key, field := range map[string]string{
+		"title":    "Title",
+		"author":   "Author",
+		"subject":  "Subject",
+		"keywords": "Keywords",
+		"creator":  "Creator",
+		"producer": "Producer",
+	} {
+		if value := strings.TrimSpace(info.Key(field).Text()); value != "" {
+			details[key] = value
+		}
+	}
+	for key, field := range map[string]string{
+		"created":  "CreationDate",
+		"modified": "ModDate",
+	} {
+		if value := parsePDFDate(info.Key(field).Text()); value != "" {
+			details[key] = value
+		}
+	}
+}
+
+// parsePDFDate converts a PDF date ("D:20240301120000+01'00'") to RFC 3339,
+// returning the raw value if it cannot be parsed
+func parsePDFDate(raw string) string {
+	s := strings.TrimPrefix(strings.TrimSpace(raw), "D:")
+	if s == "" {
+		return ""
+	}
+	s = strings.ReplaceAll(s, "'", "")
+	// "Z" marks UTC, sometimes followed by a redundant "00'00'"
+	if i := strings.IndexByte(s, 'Z'); i >= 0 {
+		s = s[:i]
+	}
+
+	for _, layout := range []string{"20060102150405-0700", "20060102150405", "200601021504", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return raw
+}
+
+// pdfOutline flattens the document bookmarks into indented titles, up to
+// maxOutlineDepth levels and maxTOCEntries entries
+func pdfOutline(r *pdf.Reader) (entries []string) {
+	defer func() {
+		// Broken outline trees should not lose the rest of the extraction
+		if recover() != nil {
+			entries = nil
+		}
+	}()
+
+	// Next links can form cycles in damaged files; bounding the number of
+	// nodes visited ends them
+	visited := 0
+	var walk func(node pdf.Value, depth int)
+	walk = func(node pdf.Value, depth int) {
+		for child := node.Key("First"); child.Kind() == pdf.Dict && len(entries) < maxTOCEntries && visited < maxTOCEntries*10; child = child.Key("Next") {
+			visited++
+			if title := strings.Join(strings.Fields(child.Key("Title").Text()), " "); title != "" {
+				entries = append(entries, strings.Repeat("  ", depth)+title)
+			}
+			if depth+1 < maxOutlineDepth {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(r.Trailer().Key("Root").Key("Outlines"), 0)
+	return entries
+}
+
+// pdfPageSize describes a page's MediaBox, naming the paper size when it
+// matches a common one (e.g. "A4 (595 x 842 pt)"). The MediaBox may be
+// inherited from a parent node of the page tree.
+func pdfPageSize(p pdf.Page) string {
+	// Parent links can form cycles in damaged files; the depth bound ends them
+	var box pdf.Value
+	v := p.V
+	for depth := 0; depth < maxPageTreeDepth && !v.IsNull() && box.IsNull(); depth++ {
+		box = v.Key("MediaBox")
+		v = v.Key("Parent")
+	}
+	if box.Len() != 4 {
+		return ""
+	}
+	width := math.Abs(box.Index(2).Float64() - box.Index(0).Float64())
+	height := math.Abs(box.Index(3).Float64() - box.Index(1).Float64())
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	size := fmt.Sprintf("%.0f x %.0f pt", width, height)
+	for _, known := range pdfPageSizes {
+		switch {
+		case math.Abs(width-known.width) < 3 && math.Abs(height-known.height) < 3:
+			return fmt.Sprintf("%s (%s)", known.name, size)
+		case math.Abs(width-known.height) < 3 && math.Abs(height-known.width) < 3:
+			return fmt.Sprintf("%s landscape (%s)", known.name, size)
+		}
+	}
+	return size
+}
@@ -0,0 +1,283 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PPTXExtractor extracts slide titles, bullet text and speaker notes
+// from a PowerPoint presentation
+type PPTXExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// pptxPresentation is the subset of ppt/presentation.xml that lists the
+// slides in presentation order
+type pptxPresentation struct {
+	Slides []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sldIdLst>sldId"`
+}
+
+// slidePartRe matches slide and notes parts and captures the slide number
+var slidePartRe = regexp.MustCompile(`^ppt/(slides/slide|notesSlides/notesSlide)(\d+)\.xml$`)
+
+// slide holds the text found on one slide
+type slide struct {
+	number  int
+	title   string
+	bullets []string
+	notes   []string
+}
+
+// Extract presentation content
+func (e PPTXExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	r, closer, err := openZip(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+
+	ordered := pptxSlideOrder(files)
+	if len(ordered) == 0 {
+		ordered = pptxSlidesByName(files)
+	}
+
+	slides := make([]*slide, 0, len(ordered))
+	for i, part := range ordered {
+		s := &slide{number: i + 1}
+		if err := readSlidePart(files[part.slide], &s.title, &s.bullets); err != nil {
+			return nil, err
+		}
+		// Notes pages repeat the slide image; only the body text matters
+		var notesTitle string
+		if err := readSlidePart(files[part.notes], &notesTitle, &s.notes); err != nil {
+			return nil, err
+		}
+		slides = append(slides, s)
+	}
+
+	return summarizeSlides(slides, "pptx", previewLimit(e.Limit)), nil
+}
+
+// pptxSlideParts names the slide part of one slide and its notes part,
+// which is empty when the slide has no notes
+type pptxSlideParts struct {
+	slide, notes string
+}
+
+// pptxSlideOrder lists the slide parts in presentation order, which comes
+// from the slide list of ppt/presentation.xml; slide file numbers follow
+// the order slides were created in, not where they were moved to. Each
+// slide's notes part is found through the slide's relationships.
+func pptxSlideOrder(files map[string]*zip.File) []pptxSlideParts {
+	decode := func(name string, v any) error {
+		return decodeZipXML(files[name], v)
+	}
+
+	var presentation pptxPresentation
+	if decode("ppt/presentation.xml", &presentation) != nil {
+		return nil
+	}
+	targets := relsTargets(decode, "ppt/presentation.xml", "/slide")
+
+	var parts []pptxSlideParts
+	for _, s := range presentation.Slides {
+		part := targets[s.RID]
+		if files[part] == nil {
+			continue
+		}
+		var notes string
+		for _, target := range relsTargets(decode, part, "/notesSlide") {
+			notes = target
+		}
+		parts = append(parts, pptxSlideParts{slide: part, notes: notes})
+	}
+	return parts
+}
+
+// pptxSlidesByName lists the slide parts by their file number, pairing
+// each with the notes part of the same number, for presentations whose
+// slide list cannot be read
+func pptxSlidesByName(files map[string]*zip.File) []pptxSlideParts {
+	byNumber := make(map[int]*pptxSlideParts)
+	for name := range files {
+		m := slidePartRe.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		if byNumber[n] == nil {
+			byNumber[n] = &pptxSlideParts{}
+		}
+		if m[1] == "slides/slide" {
+			byNumber[n].slide = name
+		} else {
+			byNumber[n].notes = name
+		}
+	}
+
+	numbers := make([]int, 0, len(byNumber))
+	for n, part := range byNumber {
+		if part.slide != "" {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	parts := make([]pptxSlideParts, 0, len(numbers))
+	for _, n := range numbers {
+		parts = append(parts, *byNumber[n])
+	}
+	return parts
+}
+
+// readSlidePart parses a slide or notes part into title and paragraphs,
+// leaving them unset when the part is missing
+func readSlidePart(f *zip.File, title *string, paragraphs *[]string) error {
+	if f == nil {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	*title, *paragraphs = parseSlideXML(rc)
+	return nil
+}
+
+// summarizeSlides builds the preview and details shared by presentation
+// formats: one "Slide N: title" line per slide followed by its bullets and notes
+func summarizeSlides(slides []*slide, docType string, budget int) *ExtractedContent {
+	var preview strings.Builder
+	var titles []string
+	notesCount := 0
+	words := 0
+
+	for _, s := range slides {
+		if s.title != "" {
+			titles = append(titles, s.title)
+		}
+		if len(s.notes) > 0 {
+			notesCount++
+		}
+
+		fmt.Fprintf(&preview, "Slide %d: %s\n", s.number, s.title)
+		for _, b := range s.bullets {
+			fmt.Fprintf(&preview, "  - %s\n", b)
+		}
+		for _, note := range s.notes {
+			fmt.Fprintf(&preview, "  (notes) %s\n", note)
+		}
+
+		words += len(strings.Fields(s.title))
+		for _, t := range s.bullets {
+			words += len(strings.Fields(t))
+		}
+		for _, t := range s.notes {
+			words += len(strings.Fields(t))
+		}
+	}
+
+	details := map[string]any{
+		"type":              docType,
+		"slide_count":       len(slides),
+		"slide_titles":      titles,
+		"slides_with_notes": notesCount,
+		"word_count":        words,
+	}
+	if len(titles) > 0 {
+		details["title"] = titles[0]
+	}
+
+	return &ExtractedContent{
+		Category: "presentation",
+		Preview:  truncatePreview(preview.String(), budget),
+		Details:  details,
+	}
+}
+
+// parseSlideXML reads a slide (or notes) part and returns the title
+// placeholder text and the remaining paragraphs, in document order.
+// Slide number, date and footer placeholders are skipped.
+func parseSlideXML(r io.Reader) (title string, paragraphs []string) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		inShape     bool
+		inText      bool
+		placeholder string
+		para        strings.Builder
+		shapeParas  []string
+	)
+
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch el := t.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "sp":
+				inShape = true
+				placeholder = ""
+				shapeParas = nil
+			case "ph":
+				placeholder = "body"
+				for _, attr := range el.Attr {
+					if attr.Name.Local == "type" {
+						placeholder = attr.Value
+					}
+				}
+			case "p":
+				para.Reset()
+			case "t":
+				inText = true
+			}
+		case xml.CharData:
+			if inShape && inText {
+				para.Write(el)
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if text := strings.TrimSpace(para.String()); text != "" && inShape {
+					shapeParas = append(shapeParas, text)
+				}
+				para.Reset()
+			case "sp":
+				inShape = false
+				switch placeholder {
+				case "title", "ctrTitle":
+					if title == "" {
+						title = strings.Join(shapeParas, " ")
+					}
+				case "sldNum", "dt", "ftr", "hdr", "sldImg":
+					// Boilerplate placeholders
+				default:
+					paragraphs = append(paragraphs, shapeParas...)
+				}
+			}
+		}
+	}
+
+	return title, paragraphs
+}
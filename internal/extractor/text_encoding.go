@@ -0,0 +1,176 @@
+package extractor
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// utf16SniffBytes is how much of a file without a byte order mark is
+// inspected for the zero bytes that give UTF-16 away
+const utf16SniffBytes = 1024
+
+// byteOrderMarks are checked longest first, so a UTF-32LE mark is not
+// taken for UTF-16LE
+var byteOrderMarks = []struct {
+	mark     string
+	name     string
+	decoding encoding.Encoding
+}{
+	{"\x00\x00\xFE\xFF", "utf-32be", utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)},
+	{"\xFF\xFE\x00\x00", "utf-32le", utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM)},
+	{"\xEF\xBB\xBF", "utf-8-bom", nil},
+	{"\xFE\xFF", "utf-16be", unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+	{"\xFF\xFE", "utf-16le", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+}
+
+// decodeText converts the bytes of a text file to UTF-8 with "\n" line
+// endings. The encoding comes from the byte order mark if there is one,
+// otherwise from the zero bytes of UTF-16 text, otherwise the text is UTF-8
+// unless invalid sequences outnumber its valid multi-byte characters, in
+// which case it is Latin-1 (Windows-1252 when it uses the 0x80-0x9F range,
+// e.g. for curly quotes). Stray invalid bytes in UTF-8 text become U+FFFD.
+// data may have been cut at a read limit; a character split by the cut is
+// dropped.
+//
+// Returns the text, the encoding name (utf-8, utf-8-bom, utf-16le,
+// utf-16be, utf-32le, utf-32be, iso-8859-1 or windows-1252) and the
+// line-ending style (see lineEndings).
+func decodeText(data []byte) (text, encodingName, lineEnding string) {
+	var decoding encoding.Encoding
+	encodingName = "utf-8"
+	for _, bom := range byteOrderMarks {
+		if bytes.HasPrefix(data, []byte(bom.mark)) {
+			data = data[len(bom.mark):]
+			encodingName, decoding = bom.name, bom.decoding
+			break
+		}
+	}
+	if encodingName == "utf-8" {
+		if order, ok := sniffUTF16(data); ok {
+			encodingName = "utf-16le"
+			if order == unicode.BigEndian {
+				encodingName = "utf-16be"
+			}
+			decoding = unicode.UTF16(order, unicode.IgnoreBOM)
+		}
+	}
+
+	switch {
+	case decoding != nil:
+		if strings.HasPrefix(encodingName, "utf-16") {
+			data = data[:len(data)&^1]
+		}
+		out, err := decoding.NewDecoder().Bytes(data)
+		if err != nil {
+			out = bytes.ToValidUTF8(data, []byte("?"))
+		}
+		text = strings.TrimSuffix(string(out), "\uFFFD") // a character cut by the read limit
+	case !invalidUTF8Dominates(trimPartialRune(data)):
+		text = string(bytes.ToValidUTF8(trimPartialRune(data), []byte("\uFFFD")))
+	default:
+		encodingName = "iso-8859-1"
+		if slices.ContainsFunc(data, func(b byte) bool { return b >= 0x80 && b <= 0x9F }) {
+			encodingName = "windows-1252"
+		}
+		// Windows-1252 only differs from Latin-1 in the 0x80-0x9F range
+		text = decodeWindows1252(data)
+	}
+
+	lineEnding = lineEndings(text)
+	if lineEnding != "lf" && lineEnding != "none" {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+	}
+	return text, encodingName, lineEnding
+}
+
+// invalidUTF8Dominates reports whether data has more invalid UTF-8
+// sequences than valid multi-byte characters, which marks it as a legacy
+// 8-bit encoding rather than UTF-8 with a few damaged bytes
+func invalidUTF8Dominates(data []byte) bool {
+	valid, invalid := 0, 0
+	for len(data) > 0 {
+		if data[0] < utf8.RuneSelf {
+			data = data[1:]
+			continue
+		}
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			invalid++
+		} else {
+			valid++
+		}
+		data = data[size:]
+	}
+	return invalid > valid
+}
+
+// sniffUTF16 recognises UTF-16 without a byte order mark by its zero
+// bytes: mostly-ASCII text has a zero in every other byte
+func sniffUTF16(data []byte) (unicode.Endianness, bool) {
+	sample := data[:min(len(data), utf16SniffBytes)&^1]
+	pairs := len(sample) / 2
+	if pairs < 2 {
+		return unicode.LittleEndian, false
+	}
+	evenZeros, oddZeros := 0, 0
+	for i := 0; i < len(sample); i += 2 {
+		if sample[i] == 0 {
+			evenZeros++
+		}
+		if sample[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	switch {
+	case oddZeros*10 >= pairs*4 && evenZeros*20 < pairs:
+		return unicode.LittleEndian, true
+	case evenZeros*10 >= pairs*4 && oddZeros*20 < pairs:
+		return unicode.BigEndian, true
+	}
+	return unicode.LittleEndian, false
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence left at the end of
+// data by a read limit
+func trimPartialRune(data []byte) []byte {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// lineEndings names the line-ending style of text: "lf", "crlf", "cr",
+// "mixed", or "none" for a single line
+func lineEndings(text string) string {
+	crlf := strings.Count(text, "\r\n")
+	lf := strings.Count(text, "\n") - crlf
+	cr := strings.Count(text, "\r") - crlf
+
+	styles := 0
+	style := "none"
+	for _, candidate := range []struct {
+		count int
+		name  string
+	}{{lf, "lf"}, {crlf, "crlf"}, {cr, "cr"}} {
+		if candidate.count > 0 {
+			styles++
+			style = candidate.name
+		}
+	}
+	if styles > 1 {
+		return "mixed"
+	}
+	return style
+}
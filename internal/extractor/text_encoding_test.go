@@ -0,0 +1,75 @@
+package extractor
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16Bytes encodes s as UTF-16 in the given byte order
+func utf16Bytes(s string, bigEndian bool) []byte {
+	var out []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			out = append(out, byte(u>>8), byte(u))
+		} else {
+			out = append(out, byte(u), byte(u>>8))
+		}
+	}
+	return out
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantText     string
+		wantEncoding string
+		wantEndings  string
+	}{
+		{"ascii", []byte("hello\nworld\n"), "hello\nworld\n", "utf-8", "lf"},
+		{"utf-8", []byte("naïve café"), "naïve café", "utf-8", "none"},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFtitle"), "title", "utf-8-bom", "none"},
+		{"crlf normalized", []byte("a\r\nb\r\n"), "a\nb\n", "utf-8", "crlf"},
+		{"old mac cr", []byte("a\rb"), "a\nb", "utf-8", "cr"},
+		{"mixed endings", []byte("a\r\nb\nc"), "a\nb\nc", "utf-8", "mixed"},
+		{"latin-1", []byte("caf\xE9 na\xEFve"), "café naïve", "iso-8859-1", "none"},
+		{"windows-1252 quotes", []byte("\x93quoted\x94 \x96 dash"), "“quoted” – dash", "windows-1252", "none"},
+		// One stray byte in otherwise valid UTF-8 is damage, not Latin-1
+		{"mostly utf-8", []byte("résumé, naïve, café \xFF end"), "résumé, naïve, café � end", "utf-8", "none"},
+		{"utf-8 cut mid-character", []byte("café")[:4], "caf", "utf-8", "none"},
+		{"utf-16le bom", append([]byte("\xFF\xFE"), utf16Bytes("hé\r\n", false)...), "hé\n", "utf-16le", "crlf"},
+		{"utf-16be bom", append([]byte("\xFE\xFF"), utf16Bytes("hé", true)...), "hé", "utf-16be", "none"},
+		{"utf-16le sniffed", utf16Bytes("plain text file", false), "plain text file", "utf-16le", "none"},
+		{"utf-16be sniffed", utf16Bytes("plain text file", true), "plain text file", "utf-16be", "none"},
+		{"utf-16 odd length", append(append([]byte("\xFF\xFE"), utf16Bytes("ab", false)...), 'c'), "ab", "utf-16le", "none"},
+		{"utf-32le bom", []byte("\xFF\xFE\x00\x00h\x00\x00\x00i\x00\x00\x00"), "hi", "utf-32le", "none"},
+		{"empty", nil, "", "utf-8", "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, encoding, endings := decodeText(tt.data)
+			if text != tt.wantText || encoding != tt.wantEncoding || endings != tt.wantEndings {
+				t.Errorf("decodeText(%q) = %q, %s, %s, want %q, %s, %s",
+					tt.data, text, encoding, endings, tt.wantText, tt.wantEncoding, tt.wantEndings)
+			}
+		})
+	}
+}
+
+func TestInvalidUTF8Dominates(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"plain ascii", false},
+		{"caf\xE9", true},
+		{"café \xE9", false}, // one valid, one invalid: a tie stays UTF-8
+		{"\xE9\xE8 café", true},
+		{"\xC3", true}, // truncated sequence
+	}
+	for _, tt := range tests {
+		if got := invalidUTF8Dominates([]byte(tt.data)); got != tt.want {
+			t.Errorf("invalidUTF8Dominates(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package extractor
 
 import (
 	"io"
-	"os"
+	"io/fs"
 	"slices"
 	"strings"
 )
@@ -23,24 +23,31 @@ func IsTextFile(ext string) bool {
 }
 
 // GenericTextExtractor is for files that could not be determined and are text like
-type GenericTextExtractor struct{}
+type GenericTextExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
 
-// Extract tries to extract content from file at specified path
-func (e GenericTextExtractor) Extract(path string) (*ExtractedContent, error) {
-	file, err := os.Open(path)
+// Extract tries to extract content from a text file
+func (e GenericTextExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := fsys.Open(name)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
+	return e.extract(file)
+}
 
-	// Read ONLY the first 1000 bytes
-	buf := make([]byte, 1000)
-	n, err := file.Read(buf)
-	if err != nil && err != io.EOF {
+// extract reads the start of a text file and decodes it to UTF-8 (see
+// decodeText); a character split by the read limit is dropped
+func (e GenericTextExtractor) extract(file io.Reader) (*ExtractedContent, error) {
+	// Read ONLY the first 1000 bytes (or the requested budget)
+	buf := make([]byte, previewLimit(e.Limit))
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return nil, err
 	}
 
-	text := string(buf[:n])
+	text, encodingName, lineEnding := decodeText(buf[:n])
 
 	lines := 0
 	for _, c := range text {
@@ -51,8 +58,12 @@ func (e GenericTextExtractor) Extract(path string) (*ExtractedContent, error) {
 
 	return &ExtractedContent{
 		Category: "text",
-		Preview:  text,
+		Preview:  truncatePreview(text, previewLimit(e.Limit)),
 		Lines:    lines,
-		Details:  map[string]any{"format": "text"},
+		Details: map[string]any{
+			"format":       "text",
+			"encoding":     encodingName,
+			"line_endings": lineEnding,
+		},
 	}, nil
 }
@@ -0,0 +1,490 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// XLSExtractor extracts sheet previews and summary metadata from a legacy
+// Excel 97-2003 (.xls) workbook
+type XLSExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+// BIFF8 record types ([MS-XLS] 2.3)
+const (
+	biffFormula    = 0x0006
+	biffEOF        = 0x000A
+	biffFilePass   = 0x002F
+	biffDateMode   = 0x0022
+	biffContinue   = 0x003C
+	biffBoundSheet = 0x0085
+	biffMulRK      = 0x00BD
+	biffSST        = 0x00FC
+	biffLabelSST   = 0x00FD
+	biffNumber     = 0x0203
+	biffLabel      = 0x0204
+	biffBoolErr    = 0x0205
+	biffString     = 0x0207
+	biffRK         = 0x027E
+	biffFormat     = 0x041E
+	biffXF         = 0x00E0
+	biffBOF        = 0x0809
+)
+
+// Built-in number formats that display dates or times ([MS-XLS] 2.5.165)
+var xlsBuiltinDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true,
+	45: true, 46: true, 47: true,
+}
+
+// biffRecord is one record of a BIFF stream, with its CONTINUE records
+// kept as separate chunks because strings restart their flags at each one
+type biffRecord struct {
+	typ    uint16
+	chunks [][]byte
+}
+
+// xlsSheet holds the cells read from one worksheet
+type xlsSheet struct {
+	name   string
+	offset uint32
+	rows   map[int]map[int]string
+}
+
+// Extract workbook content
+func (e XLSExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	streams, details, err := readOLEStreams(fsys, name, "Workbook", "Book")
+	if err != nil {
+		return nil, err
+	}
+	details["type"] = "xls"
+
+	workbook, ok := streams["Workbook"]
+	if !ok {
+		if _, old := streams["Book"]; old {
+			return nil, fmt.Errorf("excel 5.0/95 workbooks are not supported")
+		}
+		return nil, fmt.Errorf("workbook stream missing from .xls file")
+	}
+	return e.extract(workbook, details), nil
+}
+
+// extract reads the sheets and cells of a BIFF8 Workbook stream, adding
+// them to the details read from the OLE container
+func (e XLSExtractor) extract(workbook []byte, details map[string]any) *ExtractedContent {
+	var (
+		sst     []string
+		sheets  []*xlsSheet
+		current *xlsSheet
+		// A string formula's value arrives in the STRING record that follows it
+		pendingRow, pendingCol = -1, -1
+		// Number format of each XF record, in order, and the custom format strings
+		xfFormats []int
+		formats   = make(map[int]string)
+		date1904  bool
+	)
+	byOffset := make(map[uint32]*xlsSheet)
+
+	// number renders a numeric cell, as a date when its XF has a date format
+	number := func(v float64, xf int) string {
+		if xf < len(xfFormats) {
+			id := xfFormats[xf]
+			if xlsBuiltinDateFormats[id] || isDateFormat(formats[id]) {
+				return formatExcelDate(v, date1904)
+			}
+		}
+		return formatNumber(v)
+	}
+
+	set := func(row, col int, value string) {
+		if current == nil || value == "" {
+			return
+		}
+		if current.rows[row] == nil {
+			current.rows[row] = make(map[int]string)
+		}
+		current.rows[row][col] = value
+	}
+
+	for offset, rec := range biffRecords(workbook) {
+		data := rec.chunks[0]
+		switch rec.typ {
+		case biffFilePass:
+			details["encrypted"] = true
+			return &ExtractedContent{Category: "spreadsheet", Details: details}
+		case biffBOF:
+			current = byOffset[offset]
+		case biffEOF:
+			current = nil
+		case biffBoundSheet:
+			if len(data) < 8 {
+				continue
+			}
+			s := &xlsSheet{
+				offset: binary.LittleEndian.Uint32(data),
+				rows:   make(map[int]map[int]string),
+			}
+			r := &biffReader{chunks: [][]byte{data[6:]}}
+			s.name = r.unicodeString(int(r.byte()))
+			// Skip chart sheets and macro sheets (dt != 0)
+			if data[5] == 0 {
+				sheets = append(sheets, s)
+				byOffset[s.offset] = s
+			}
+		case biffDateMode:
+			date1904 = len(data) >= 2 && binary.LittleEndian.Uint16(data) == 1
+		case biffFormat:
+			if len(data) >= 5 {
+				r := &biffReader{chunks: rec.chunks}
+				id := int(r.uint16())
+				formats[id] = r.unicodeString(int(r.uint16()))
+			}
+		case biffXF:
+			if len(data) >= 4 {
+				xfFormats = append(xfFormats, int(binary.LittleEndian.Uint16(data[2:])))
+			}
+		case biffSST:
+			sst = readSST(rec.chunks)
+		case biffLabelSST:
+			if len(data) >= 10 {
+				if i := int(binary.LittleEndian.Uint32(data[6:])); i < len(sst) {
+					row, col := cellPos(data)
+					set(row, col, sst[i])
+				}
+			}
+		case biffLabel:
+			if len(data) >= 8 {
+				r := &biffReader{chunks: [][]byte{data[6:]}}
+				row, col := cellPos(data)
+				set(row, col, r.unicodeString(int(r.uint16())))
+			}
+		case biffNumber:
+			if len(data) >= 14 {
+				row, col := cellPos(data)
+				set(row, col, number(math.Float64frombits(binary.LittleEndian.Uint64(data[6:])), cellXF(data)))
+			}
+		case biffRK:
+			if len(data) >= 10 {
+				row, col := cellPos(data)
+				set(row, col, number(decodeRK(binary.LittleEndian.Uint32(data[6:])), cellXF(data)))
+			}
+		case biffMulRK:
+			if len(data) < 6 {
+				continue
+			}
+			row := int(binary.LittleEndian.Uint16(data))
+			col := int(binary.LittleEndian.Uint16(data[2:]))
+			for i := 4; i+6 <= len(data)-2; i += 6 {
+				xf := int(binary.LittleEndian.Uint16(data[i:]))
+				set(row, col, number(decodeRK(binary.LittleEndian.Uint32(data[i+2:])), xf))
+				col++
+			}
+		case biffBoolErr:
+			if len(data) >= 8 {
+				value := "#ERROR"
+				if data[7] == 0 {
+					value = strconv.FormatBool(data[6] != 0)
+				}
+				row, col := cellPos(data)
+				set(row, col, value)
+			}
+		case biffFormula:
+			if len(data) < 14 {
+				continue
+			}
+			row, col := cellPos(data)
+			result := data[6:14]
+			if binary.LittleEndian.Uint16(result[6:]) != 0xFFFF {
+				set(row, col, number(math.Float64frombits(binary.LittleEndian.Uint64(result)), cellXF(data)))
+				continue
+			}
+			switch result[0] {
+			case 0: // string, stored in the next STRING record
+				pendingRow, pendingCol = row, col
+			case 1:
+				set(row, col, strconv.FormatBool(result[2] != 0))
+			}
+		case biffString:
+			if pendingRow >= 0 && len(data) >= 3 {
+				r := &biffReader{chunks: rec.chunks}
+				set(pendingRow, pendingCol, r.unicodeString(int(r.uint16())))
+			}
+			pendingRow, pendingCol = -1, -1
+		}
+	}
+
+	budget := previewLimit(e.Limit)
+	var sb strings.Builder
+	var names []string
+	totalRows := 0
+	for _, s := range sheets {
+		names = append(names, s.name)
+		totalRows += len(s.rows)
+
+		rowNumbers := make([]int, 0, len(s.rows))
+		for n := range s.rows {
+			rowNumbers = append(rowNumbers, n)
+		}
+		sort.Ints(rowNumbers)
+
+		fmt.Fprintf(&sb, "Sheet: %s\n", s.name)
+		for i, n := range rowNumbers {
+			// Scans show six rows per sheet; a larger budget shows rows until it is filled
+			if e.Limit == 0 && i > 5 {
+				break
+			}
+			if sb.Len() >= budget {
+				break
+			}
+			sb.WriteString(strings.Join(rowValues(s.rows[n]), " | "))
+			sb.WriteString("\n")
+		}
+	}
+
+	details["sheet_names"] = names
+	details["sheet_count"] = len(sheets)
+	details["total_rows_estimated"] = totalRows
+
+	return &ExtractedContent{
+		Category: "spreadsheet",
+		Preview:  truncatePreview(sb.String(), budget),
+		Details:  details,
+	}
+}
+
+// biffRecords iterates over the records of a BIFF stream, yielding each
+// record's stream offset (which BOUNDSHEET records point at) together with
+// the record and any CONTINUE records that follow it
+func biffRecords(stream []byte) func(yield func(uint32, biffRecord) bool) {
+	return func(yield func(uint32, biffRecord) bool) {
+		pos := 0
+		for pos+4 <= len(stream) {
+			start := pos
+			rec := biffRecord{typ: binary.LittleEndian.Uint16(stream[pos:])}
+			size := int(binary.LittleEndian.Uint16(stream[pos+2:]))
+			pos += 4
+			if pos+size > len(stream) {
+				return
+			}
+			rec.chunks = append(rec.chunks, stream[pos:pos+size])
+			pos += size
+
+			for pos+4 <= len(stream) && binary.LittleEndian.Uint16(stream[pos:]) == biffContinue {
+				size := int(binary.LittleEndian.Uint16(stream[pos+2:]))
+				pos += 4
+				if pos+size > len(stream) {
+					return
+				}
+				rec.chunks = append(rec.chunks, stream[pos:pos+size])
+				pos += size
+			}
+
+			if !yield(uint32(start), rec) {
+				return
+			}
+		}
+	}
+}
+
+// readSST decodes the shared string table, which may span CONTINUE records
+func readSST(chunks [][]byte) []string {
+	r := &biffReader{chunks: chunks}
+	r.uint32() // total references
+	unique := int(r.uint32())
+
+	strs := make([]string, 0, min(unique, 1<<16))
+	for range unique {
+		if r.done() {
+			break
+		}
+		strs = append(strs, r.unicodeString(int(r.uint16())))
+	}
+	return strs
+}
+
+// biffReader reads little-endian values across record chunk boundaries
+type biffReader struct {
+	chunks [][]byte
+	chunk  int
+	pos    int
+}
+
+// done reports whether all chunks have been consumed
+func (r *biffReader) done() bool {
+	for r.chunk < len(r.chunks) && r.pos >= len(r.chunks[r.chunk]) {
+		r.chunk++
+		r.pos = 0
+	}
+	return r.chunk >= len(r.chunks)
+}
+
+// atBoundary reports whether the next byte starts a new CONTINUE chunk
+func (r *biffReader) atBoundary() bool {
+	return r.chunk < len(r.chunks) && r.pos >= len(r.chunks[r.chunk]) && r.chunk+1 < len(r.chunks)
+}
+
+func (r *biffReader) byte() byte {
+	if r.done() {
+		return 0
+	}
+	b := r.chunks[r.chunk][r.pos]
+	r.pos++
+	return b
+}
+
+func (r *biffReader) uint16() uint16 {
+	return uint16(r.byte()) | uint16(r.byte())<<8
+}
+
+func (r *biffReader) uint32() uint32 {
+	return uint32(r.uint16()) | uint32(r.uint16())<<16
+}
+
+func (r *biffReader) skip(n int) {
+	for range n {
+		r.byte()
+	}
+}
+
+// unicodeString reads the flags and cch characters of an XLUnicodeString.
+// When the characters continue into the next chunk, that chunk starts with
+// a fresh flags byte saying whether they are 8-bit or UTF-16.
+func (r *biffReader) unicodeString(cch int) string {
+	flags := r.byte()
+	wide := flags&0x01 != 0
+	var runs, extSize int
+	if flags&0x08 != 0 {
+		runs = int(r.uint16())
+	}
+	if flags&0x04 != 0 {
+		extSize = int(r.uint32())
+	}
+
+	units := make([]uint16, 0, cch)
+	for range cch {
+		if r.atBoundary() {
+			r.chunk++
+			r.pos = 0
+			wide = r.byte()&0x01 != 0
+		}
+		if r.done() {
+			break
+		}
+		if wide {
+			units = append(units, r.uint16())
+		} else {
+			// 8-bit strings hold the low byte of each UTF-16 code unit
+			units = append(units, uint16(r.byte()))
+		}
+	}
+
+	// Formatting runs and phonetic data follow the characters
+	r.skip(runs*4 + extSize)
+	return string(utf16.Decode(units))
+}
+
+// cellPos returns the row and column that start every cell record
+func cellPos(data []byte) (int, int) {
+	return int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:]))
+}
+
+// cellXF returns the XF (cell format) index that follows a cell's position
+func cellXF(data []byte) int {
+	return int(binary.LittleEndian.Uint16(data[4:]))
+}
+
+// isDateFormat reports whether a custom number format displays a date or
+// time, i.e. uses a y, m, d, h or s code outside quoted text, escapes and
+// bracketed sections such as colours and locales
+func isDateFormat(format string) bool {
+	inQuote, inBracket := false, false
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case inQuote:
+			inQuote = c != '"'
+		case inBracket:
+			inBracket = c != ']'
+		case c == '"':
+			inQuote = true
+		case c == '[':
+			// Elapsed time such as [h]:mm is still a time
+			if i+1 < len(format) && strings.ContainsRune("hHmMsS", rune(format[i+1])) {
+				return true
+			}
+			inBracket = true
+		case c == '\\' || c == '_' || c == '*':
+			i++ // the next character is literal or padding
+		default:
+			if strings.ContainsRune("yYmMdDhHsS", rune(c)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatExcelDate converts an Excel serial date to text, leaving out the
+// time when it is midnight and the date when the value is a time of day
+func formatExcelDate(v float64, date1904 bool) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if v < 0 || v > 2958465 { // beyond 9999-12-31
+		return formatNumber(v)
+	}
+	// The 1900 system counts a nonexistent 1900-02-29, so serials before
+	// it are one day later than the epoch suggests
+	if !date1904 && v >= 1 && v < 60 {
+		v++
+	}
+	t := epoch.Add(time.Duration(math.Round(v*86400)) * time.Second)
+	switch {
+	case v < 1 && !date1904:
+		return t.Format("15:04:05")
+	case t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0:
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// decodeRK expands Excel's compressed RK number representation
+func decodeRK(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// formatNumber renders a cell number without a trailing ".0"
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// rowValues lays out a sparse row, filling gaps with empty cells
+func rowValues(cells map[int]string) []string {
+	last := -1
+	for col := range cells {
+		last = max(last, col)
+	}
+	values := make([]string, last+1)
+	for col, v := range cells {
+		values[col] = v
+	}
+	return values
+}
@@ -0,0 +1,273 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// biffRec encodes one BIFF record with its type and length header
+func biffRec(typ uint16, data ...[]byte) []byte {
+	body := []byte{}
+	for _, d := range data {
+		body = append(body, d...)
+	}
+	out := binary.LittleEndian.AppendUint16(nil, typ)
+	out = binary.LittleEndian.AppendUint16(out, uint16(len(body)))
+	return append(out, body...)
+}
+
+// u16 and u32 encode little-endian integers for record bodies
+func u16(v int) []byte { return binary.LittleEndian.AppendUint16(nil, uint16(v)) }
+func u32(v int) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(v)) }
+
+// xlString encodes an 8-bit XLUnicodeString body: flags and characters
+func xlString(s string) []byte {
+	return append([]byte{0}, s...)
+}
+
+// cell encodes the row, column and XF index that start a cell record
+func cell(row, col, xf int) []byte {
+	return append(append(u16(row), u16(col)...), u16(xf)...)
+}
+
+// rkInt encodes an integer as an RK value
+func rkInt(v int) []byte {
+	return u32(v<<2 | 2)
+}
+
+// buildWorkbook lays out a Workbook stream with one worksheet that uses
+// every cell record type the extractor reads, and number formats for dates
+func buildWorkbook() []byte {
+	xf := func(format int) []byte {
+		body := append(u16(0), u16(format)...)
+		return biffRec(biffXF, body, make([]byte, 16))
+	}
+	sst := biffRec(biffSST, u32(3), u32(3),
+		u16(4), xlString("Name"),
+		u16(6), xlString("Joined"),
+		u16(5), xlString("Score"))
+
+	globals := [][]byte{
+		biffRec(biffBOF, u16(0x0600), u16(0x0005), make([]byte, 12)),
+		biffRec(biffFormat, u16(164), u16(10), xlString("dd/mm/yyyy")),
+		biffRec(biffFormat, u16(165), u16(4), xlString("0.00")),
+		xf(0), xf(14), xf(164), xf(165),
+		nil, // BOUNDSHEET, once the sheet offset is known
+		sst,
+		biffRec(biffEOF),
+	}
+	boundSheet := func(offset int) []byte {
+		return biffRec(biffBoundSheet, u32(offset), []byte{0, 0}, []byte{6}, xlString("People"))
+	}
+	// A chart sheet (dt = 2) is listed but not read
+	chart := biffRec(biffBoundSheet, u32(0), []byte{0, 2}, []byte{5}, xlString("Chart"))
+
+	size := len(boundSheet(0)) + len(chart)
+	for _, rec := range globals {
+		size += len(rec)
+	}
+	globals[7] = append(boundSheet(size), chart...)
+
+	formulaResult := func(result []byte) []byte {
+		return append(result, make([]byte, 6)...) // flags and chn
+	}
+	sheet := [][]byte{
+		biffRec(biffBOF, u16(0x0600), u16(0x0010), make([]byte, 12)),
+		biffRec(biffLabelSST, cell(0, 0, 0), u32(0)),
+		biffRec(biffLabelSST, cell(0, 1, 0), u32(1)),
+		biffRec(biffLabelSST, cell(0, 2, 0), u32(2)),
+		biffRec(biffLabel, cell(1, 0, 0), u16(3), xlString("Ada")),
+		biffRec(biffRK, cell(1, 1, 1), rkInt(45292)),
+		biffRec(biffNumber, cell(1, 2, 3), binary.LittleEndian.AppendUint64(nil, math.Float64bits(9.5))),
+		biffRec(biffMulRK, u16(2), u16(0),
+			u16(0), rkInt(7),
+			u16(2), u32(4529250<<2|3), // 45292.5 stored times 100
+			u16(3), rkInt(2),
+			u16(2)),
+		biffRec(biffBoolErr, cell(3, 0, 0), []byte{1, 0}),
+		biffRec(biffBoolErr, cell(3, 1, 0), []byte{7, 1}),
+		biffRec(biffFormula, cell(3, 2, 0), formulaResult([]byte{0, 0, 0, 0, 0, 0, 0xFF, 0xFF})),
+		biffRec(biffString, u16(2), xlString("ok")),
+		biffRec(biffFormula, cell(4, 0, 1), formulaResult(binary.LittleEndian.AppendUint64(nil, math.Float64bits(45293)))),
+		biffRec(biffFormula, cell(4, 1, 0), formulaResult([]byte{1, 0, 1, 0, 0, 0, 0xFF, 0xFF})),
+		biffRec(biffEOF),
+	}
+
+	var stream []byte
+	for _, rec := range append(globals, sheet...) {
+		stream = append(stream, rec...)
+	}
+	return stream
+}
+
+func TestXLSExtract(t *testing.T) {
+	content := XLSExtractor{}.extract(buildWorkbook(), map[string]any{})
+
+	want := strings.Join([]string{
+		"Sheet: People",
+		"Name | Joined | Score",
+		"Ada | 2024-01-01 | 9.5",
+		"7 | 2024-01-01 12:00:00 | 2",
+		"true | #ERROR | ok",
+		"2024-01-02 | true",
+		"",
+	}, "\n")
+	if content.Preview != want {
+		t.Errorf("preview =\n%s\nwant\n%s", content.Preview, want)
+	}
+	if got := content.Details["sheet_names"]; !reflect.DeepEqual(got, []string{"People"}) {
+		t.Errorf("sheet_names = %v, want [People]", got)
+	}
+	if content.Details["total_rows_estimated"] != 5 {
+		t.Errorf("total_rows_estimated = %v, want 5", content.Details["total_rows_estimated"])
+	}
+}
+
+func TestXLSExtractEncrypted(t *testing.T) {
+	stream := append(biffRec(biffBOF, u16(0x0600), u16(0x0005)), biffRec(biffFilePass, u16(1))...)
+	content := XLSExtractor{}.extract(stream, map[string]any{})
+	if content.Details["encrypted"] != true || content.Preview != "" {
+		t.Errorf("details = %v, preview = %q, want encrypted and no preview", content.Details, content.Preview)
+	}
+}
+
+func TestXLSExtractTruncated(t *testing.T) {
+	stream := buildWorkbook()
+	// Every prefix must parse without panicking
+	for n := range len(stream) {
+		XLSExtractor{}.extract(stream[:n], map[string]any{})
+	}
+
+	// Records whose bodies are shorter than their fixed fields are skipped
+	for _, typ := range []uint16{biffBoundSheet, biffLabelSST, biffLabel, biffNumber, biffRK, biffMulRK, biffBoolErr, biffFormula, biffString, biffFormat, biffXF, biffDateMode, biffSST} {
+		for size := range 14 {
+			stream := append(biffRec(biffBOF), biffRec(typ, make([]byte, size))...)
+			XLSExtractor{}.extract(stream, map[string]any{})
+		}
+	}
+}
+
+func TestBiffRecordsContinue(t *testing.T) {
+	stream := append(biffRec(biffSST, []byte{1, 2}), biffRec(biffContinue, []byte{3})...)
+	stream = append(stream, biffRec(biffEOF)...)
+
+	var types []uint16
+	var chunks [][][]byte
+	for _, rec := range biffRecords(stream) {
+		types = append(types, rec.typ)
+		chunks = append(chunks, rec.chunks)
+	}
+	if !reflect.DeepEqual(types, []uint16{biffSST, biffEOF}) {
+		t.Fatalf("types = %x, want SST and EOF", types)
+	}
+	if want := [][]byte{{1, 2}, {3}}; !reflect.DeepEqual(chunks[0], want) {
+		t.Errorf("SST chunks = %v, want %v", chunks[0], want)
+	}
+}
+
+func TestReadSSTAcrossContinue(t *testing.T) {
+	// "Hello" starts 8-bit in the SST record and continues as UTF-16 in the
+	// CONTINUE record, which repeats the flags byte
+	first := append(append(u32(2), u32(2)...), u16(5)...)
+	first = append(first, 0, 'H', 'e')
+	second := []byte{1, 'l', 0, 'l', 0, 'o', 0}
+	second = append(second, u16(1)...)
+	second = append(second, 1, 0xAC, 0x20) // "€" as UTF-16
+
+	got := readSST([][]byte{first, second})
+	if want := []string{"Hello", "€"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readSST = %q, want %q", got, want)
+	}
+
+	// A count larger than the data stops at the end
+	if got := readSST([][]byte{append(u32(1), u32(1000)...)}); len(got) != 0 {
+		t.Errorf("readSST of empty table = %q, want none", got)
+	}
+}
+
+func TestUnicodeStringSkipsRunsAndPhonetics(t *testing.T) {
+	data := []byte{0x0C}           // rich text and phonetic flags
+	data = append(data, u16(1)...) // one formatting run
+	data = append(data, u32(3)...) // three bytes of phonetic data
+	data = append(data, "ab"...)
+	data = append(data, 1, 2, 3, 4, 9, 9, 9, 'z')
+
+	r := &biffReader{chunks: [][]byte{data}}
+	if got := r.unicodeString(2); got != "ab" {
+		t.Errorf("unicodeString = %q, want ab", got)
+	}
+	if got := r.byte(); got != 'z' {
+		t.Errorf("next byte = %q, want z", got)
+	}
+}
+
+func TestDecodeRK(t *testing.T) {
+	tests := []struct {
+		rk   uint32
+		want float64
+	}{
+		{5<<2 | 2, 5},
+		{0xFFFFFFF6, -3}, // -3 << 2 | 2
+		{12345<<2 | 3, 123.45},
+		{uint32(math.Float64bits(1.5) >> 32), 1.5},
+		{uint32(math.Float64bits(250)>>32) | 1, 2.5},
+	}
+	for _, tt := range tests {
+		if got := decodeRK(tt.rk); got != tt.want {
+			t.Errorf("decodeRK(%#x) = %v, want %v", tt.rk, got, tt.want)
+		}
+	}
+}
+
+func TestIsDateFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{"dd/mm/yyyy", true},
+		{"mmm d, yyyy", true},
+		{"h:mm AM/PM", true},
+		{"[h]:mm:ss", true},
+		{"[$-409]d-mmm-yy", true},
+		{"General", false},
+		{"0.00", false},
+		{"#,##0 \"days\"", false},
+		{"[Red]0.00", false},
+		{"0.00E+00", false},
+		{"0\\d", false},
+		{"_(* #,##0_)", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isDateFormat(tt.format); got != tt.want {
+			t.Errorf("isDateFormat(%q) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestFormatExcelDate(t *testing.T) {
+	tests := []struct {
+		serial   float64
+		date1904 bool
+		want     string
+	}{
+		{45292, false, "2024-01-01"},
+		{45292.75, false, "2024-01-01 18:00:00"},
+		{1, false, "1900-01-01"},
+		{59, false, "1900-02-28"},
+		{61, false, "1900-03-01"},
+		{0.5, false, "12:00:00"},
+		{0, true, "1904-01-01"},
+		{43830, true, "2024-01-01"},
+		{-1, false, "-1"},
+		{3e6, false, "3000000"},
+	}
+	for _, tt := range tests {
+		if got := formatExcelDate(tt.serial, tt.date1904); got != tt.want {
+			t.Errorf("formatExcelDate(%v, %v) = %q, want %q", tt.serial, tt.date1904, got, tt.want)
+		}
+	}
+}
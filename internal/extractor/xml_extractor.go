@@ -0,0 +1,304 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// XMLExtractor reads XML documents as a tree: the root element, its
+// namespaces and how often each element occurs, plus the fields that
+// matter for well-known kinds (Maven POMs, Android manifests, SVG images,
+// RSS and Atom feeds). XHTML documents are handed to HTMLExtractor.
+type XMLExtractor struct {
+	Limit int // Maximum preview size in bytes (0 = DefaultPreviewLimit)
+}
+
+const (
+	maxXMLScan       = 32 * 1024 * 1024 // larger documents are only read this far
+	maxXMLElements   = 10               // element names reported by frequency
+	maxXMLNamespaces = 10
+	maxXMLListItems  = 20 // dependencies, permissions or feed titles kept
+)
+
+const androidNamespace = "http://schemas.android.com/apk/res/android"
+
+// xmlKindFields maps element paths to the detail they fill for each kind
+var xmlKindFields = map[string]map[string]string{
+	"maven-pom": {
+		"project/groupId":        "group_id",
+		"project/parent/groupId": "parent_group_id",
+		"project/artifactId":     "artifact_id",
+		"project/version":        "version",
+		"project/packaging":      "packaging",
+		"project/name":           "title",
+		"project/description":    "description",
+	},
+	"rss": {
+		"rss/channel/title":       "title",
+		"rss/channel/description": "description",
+		"RDF/channel/title":       "title",
+		"RDF/channel/description": "description",
+	},
+	"atom": {"feed/title": "title", "feed/subtitle": "description"},
+	"svg":  {"svg/title": "title"},
+}
+
+// xmlKindLists maps element paths to list details for each kind
+var xmlKindLists = map[string]map[string]string{
+	"maven-pom": {"project/dependencies/dependency/artifactId": "dependencies"},
+	"rss":       {"rss/channel/item/title": "items", "RDF/item/title": "items"},
+	"atom":      {"feed/entry/title": "entries"},
+}
+
+// xmlKindCounts maps element names to count details for each kind
+var xmlKindCounts = map[string]map[string]string{
+	"maven-pom":        {"dependency": "dependency_count"},
+	"android-manifest": {"activity": "activities", "service": "services"},
+	"rss":              {"item": "item_count"},
+	"atom":             {"entry": "entry_count"},
+}
+
+// xmlKindNames label each kind in previews
+var xmlKindNames = map[string]string{
+	"maven-pom":        "Maven POM",
+	"android-manifest": "Android manifest",
+	"svg":              "SVG image",
+	"rss":              "RSS feed",
+	"atom":             "Atom feed",
+}
+
+// Extract extracts the structure and text of an XML document
+func (e XMLExtractor) Extract(fsys fs.FS, name string) (*ExtractedContent, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxXMLScan))
+	if err != nil {
+		return nil, err
+	}
+	limit := previewLimit(e.Limit)
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	// Documents declared as ISO-8859-1, Windows-1252 and so on
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var (
+		root, kind string
+		stack      []string
+		namespaces []string
+		text       []string
+		textSize   int
+	)
+	elements := make(map[string]int)
+	details := map[string]any{"type": "xml"}
+	lists := make(map[string][]string)
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			for _, a := range t.Attr {
+				if (a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns")) &&
+					len(namespaces) < maxXMLNamespaces && !slices.Contains(namespaces, a.Value) {
+					namespaces = append(namespaces, a.Value)
+				}
+			}
+			if root == "" {
+				root = t.Name.Local
+				if strings.EqualFold(root, "html") {
+					// XHTML reads better as a web page
+					return HTMLExtractor{Limit: e.Limit}.Extract(fsys, name)
+				}
+				kind = xmlKind(root, t.Name.Space, namespaces)
+				if kind == "svg" {
+					addSVGSize(details, t.Attr)
+				}
+			}
+
+			elements[t.Name.Local]++
+			stack = append(stack, t.Name.Local)
+			if key, ok := xmlKindCounts[kind][t.Name.Local]; ok {
+				count, _ := details[key].(int)
+				details[key] = count + 1
+			}
+			if kind == "android-manifest" {
+				addManifestAttrs(details, lists, t)
+			}
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+
+		case xml.CharData:
+			value := collapseSpace(string(t))
+			if value == "" {
+				continue
+			}
+			path := strings.Join(stack, "/")
+			if key, ok := xmlKindFields[kind][path]; ok && details[key] == nil {
+				details[key] = value
+			}
+			if key, ok := xmlKindLists[kind][path]; ok && len(lists[key]) < maxXMLListItems {
+				lists[key] = append(lists[key], value)
+			}
+			if textSize < limit {
+				text = append(text, value)
+				textSize += len(value) + 1
+			}
+		}
+	}
+
+	if root == "" {
+		return nil, fmt.Errorf("not a well-formed XML document")
+	}
+
+	if parent, ok := details["parent_group_id"]; ok {
+		if _, ok := details["group_id"]; !ok {
+			details["group_id"] = parent
+		}
+		delete(details, "parent_group_id")
+	}
+	for key, values := range lists {
+		details[key] = values
+	}
+	total := 0
+	for _, count := range elements {
+		total += count
+	}
+	details["root"] = root
+	details["element_count"] = total
+	details["elements"] = topCounts(elements, maxXMLElements)
+	if len(namespaces) > 0 {
+		details["namespaces"] = namespaces
+	}
+
+	category := "document"
+	if kind != "" {
+		details["kind"] = kind
+	}
+	if kind == "svg" {
+		category = "image"
+		details["format"] = "SVG"
+	}
+
+	preview := xmlPreviewTitle(root, kind, details) + "\n\n" + strings.Join(text, "\n")
+	return &ExtractedContent{
+		Category: category,
+		Preview:  truncatePreview(preview, limit),
+		Lines:    bytes.Count(data, []byte("\n")) + 1,
+		Details:  details,
+	}, nil
+}
+
+// xmlKind recognises well-known documents by their root element and namespaces
+func xmlKind(root, space string, namespaces []string) string {
+	switch {
+	case root == "project" && strings.Contains(space, "maven.apache.org/POM"):
+		return "maven-pom"
+	case root == "manifest" && slices.Contains(namespaces, androidNamespace):
+		return "android-manifest"
+	case root == "svg":
+		return "svg"
+	case root == "rss", root == "RDF" && strings.Contains(strings.Join(namespaces, " "), "purl.org/rss"):
+		return "rss"
+	case root == "feed" && space == "http://www.w3.org/2005/Atom":
+		return "atom"
+	}
+	return ""
+}
+
+// addManifestAttrs records an Android manifest's package, app label and
+// requested permissions
+func addManifestAttrs(details map[string]any, lists map[string][]string, el xml.StartElement) {
+	for _, a := range el.Attr {
+		switch {
+		case el.Name.Local == "manifest" && a.Name.Local == "package":
+			details["package"] = a.Value
+		case el.Name.Local == "application" && a.Name.Space == androidNamespace && a.Name.Local == "label":
+			details["title"] = a.Value
+		case el.Name.Local == "uses-permission" && a.Name.Space == androidNamespace && a.Name.Local == "name" &&
+			len(lists["permissions"]) < maxXMLListItems:
+			lists["permissions"] = append(lists["permissions"], strings.TrimPrefix(a.Value, "android.permission."))
+		}
+	}
+}
+
+// addSVGSize records an SVG's size from its width/height attributes,
+// falling back to the viewBox
+func addSVGSize(details map[string]any, attrs []xml.Attr) {
+	var width, height, viewBox string
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "width":
+			width = a.Value
+		case "height":
+			height = a.Value
+		case "viewBox":
+			viewBox = a.Value
+		}
+	}
+	if fields := strings.Fields(strings.ReplaceAll(viewBox, ",", " ")); len(fields) == 4 && (width == "" || height == "") {
+		width, height = fields[2], fields[3]
+	}
+	w, errW := strconv.ParseFloat(strings.TrimSuffix(width, "px"), 64)
+	h, errH := strconv.ParseFloat(strings.TrimSuffix(height, "px"), 64)
+	if errW == nil && errH == nil {
+		details["width"], details["height"] = int(w), int(h)
+	}
+	if viewBox != "" {
+		details["view_box"] = viewBox
+	}
+}
+
+// xmlPreviewTitle is the first preview line, e.g. "Maven POM: com.acme:api:1.2.0"
+// or "RSS feed: Release notes (12 items)"
+func xmlPreviewTitle(root, kind string, details map[string]any) string {
+	label := fmt.Sprintf("XML document <%s>", root)
+	if name, ok := xmlKindNames[kind]; ok {
+		label = name
+	}
+
+	switch kind {
+	case "maven-pom":
+		var coords []string
+		for _, key := range []string{"group_id", "artifact_id", "version"} {
+			if value, ok := details[key].(string); ok {
+				coords = append(coords, value)
+			}
+		}
+		return fmt.Sprintf("%s: %s", label, strings.Join(coords, ":"))
+	case "android-manifest":
+		if pkg, ok := details["package"].(string); ok {
+			return fmt.Sprintf("%s: %s", label, pkg)
+		}
+	case "svg":
+		if width, ok := details["width"].(int); ok {
+			return fmt.Sprintf("%s %dx%d", label, width, details["height"])
+		}
+	case "rss", "atom":
+		count, _ := details["item_count"].(int)
+		if kind == "atom" {
+			count, _ = details["entry_count"].(int)
+		}
+		title, _ := details["title"].(string)
+		return fmt.Sprintf("%s: %s (%s)", label, title, plural(count, "item"))
+	}
+	return label
+}
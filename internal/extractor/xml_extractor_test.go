@@ -0,0 +1,101 @@
+package extractor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// extractXML runs XMLExtractor on a document held in memory
+func extractXML(t *testing.T, data string) *ExtractedContent {
+	t.Helper()
+	content, err := XMLExtractor{}.Extract(fstest.MapFS{"doc.xml": {Data: []byte(data)}}, "doc.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestXMLExtractKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		preview string
+		want    map[string]any // details checked, alongside the kind
+	}{
+		{"maven pom", `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent><groupId>com.acme</groupId></parent>
+  <artifactId>api</artifactId><version>1.2.0</version>
+  <dependencies>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId></dependency>
+    <dependency><artifactId>guava</artifactId></dependency>
+  </dependencies>
+</project>`, "Maven POM: com.acme:api:1.2.0", map[string]any{
+			"kind": "maven-pom", "group_id": "com.acme", "artifact_id": "api", "version": "1.2.0",
+			"dependencies": []string{"junit", "guava"}, "dependency_count": 2,
+		}},
+		{"android manifest", `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.acme.app">
+  <uses-permission android:name="android.permission.INTERNET"/>
+  <uses-permission android:name="android.permission.CAMERA"/>
+  <application android:label="Acme"><activity/><activity/><service/></application>
+</manifest>`, "Android manifest: com.acme.app", map[string]any{
+			"kind": "android-manifest", "package": "com.acme.app", "title": "Acme",
+			"permissions": []string{"INTERNET", "CAMERA"}, "activities": 2, "services": 1,
+		}},
+		{"svg", `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 16"><title>Logo</title></svg>`,
+			"SVG image 24x16", map[string]any{
+				"kind": "svg", "format": "SVG", "width": 24, "height": 16, "view_box": "0 0 24 16", "title": "Logo",
+			}},
+		{"rss", `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss><channel><title>Caf` + "\xE9" + ` news</title>
+  <item><title>Opening</title></item><item><title>Menu</title></item>
+</channel></rss>`, "RSS feed: Café news (2 items)", map[string]any{
+			"kind": "rss", "title": "Café news", "items": []string{"Opening", "Menu"}, "item_count": 2,
+		}},
+		{"atom", `<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title>
+  <entry><title>Hello</title></entry></feed>`, "Atom feed: Blog (1 item)", map[string]any{
+			"kind": "atom", "title": "Blog", "entries": []string{"Hello"}, "entry_count": 1,
+		}},
+		{"plain", `<config><db host="x">main</db></config>`, "XML document <config>", map[string]any{
+			"root": "config", "element_count": 2, "elements": map[string]int{"config": 1, "db": 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := extractXML(t, tt.data)
+			if title, _, _ := strings.Cut(content.Preview, "\n"); title != tt.preview {
+				t.Errorf("preview title = %q, want %q", title, tt.preview)
+			}
+			for key, want := range tt.want {
+				if got := content.Details[key]; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %v, want %v", key, got, want)
+				}
+			}
+			if _, ok := tt.want["kind"]; !ok && content.Details["kind"] != nil {
+				t.Errorf("kind = %v, want none", content.Details["kind"])
+			}
+		})
+	}
+}
+
+func TestXMLExtractSVGCategory(t *testing.T) {
+	content := extractXML(t, `<svg width="100px" height="50" viewBox="0 0 10 5"/>`)
+	if content.Category != "image" || content.Details["width"] != 100 || content.Details["height"] != 50 {
+		t.Errorf("category = %s, details = %v, want an image sized by its attributes", content.Category, content.Details)
+	}
+}
+
+func TestXMLExtractXHTML(t *testing.T) {
+	content := extractXML(t, `<?xml version="1.0"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Guide</title></head><body><p>Hello</p></body></html>`)
+	if content.Details["type"] != "html" || content.Details["title"] != "Guide" {
+		t.Errorf("details = %v, want XHTML handed to the HTML extractor", content.Details)
+	}
+}
+
+func TestXMLExtractInvalid(t *testing.T) {
+	if _, err := (XMLExtractor{}).Extract(fstest.MapFS{"doc.xml": {Data: []byte("just text")}}, "doc.xml"); err == nil {
+		t.Error("want an error for a document without elements")
+	}
+}
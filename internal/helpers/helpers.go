@@ -2,6 +2,7 @@
 package helpers
 
 import (
+	"fmt"
 	"path/filepath"
 	"regexp"
 	"slices"
@@ -13,7 +14,8 @@ import (
 // IsCodeFile checks if a file extension represents source code
 func IsCodeFile(ext string) bool {
 	// as far as I can add
-	codeExts := []string{".go", ".dart", ".js", ".ts", ".py", ".java", ".rb", ".rs", ".c", ".cpp", ".cs", ".php", ".swift", ".kt"}
+	codeExts := []string{".go", ".dart", ".js", ".jsx", ".mjs", ".ts", ".tsx", ".py", ".java", ".rb", ".rs",
+		".c", ".h", ".cpp", ".cc", ".hpp", ".cs", ".php", ".swift", ".kt"}
 	return slices.Contains(codeExts, ext)
 }
 
@@ -27,26 +29,28 @@ func IsConfigFile(ext, name string) bool {
 // IsImageFile checks if a file extension represents an image
 func IsImageFile(ext string) bool {
 	return ext == ".jpg" || ext == ".jpeg" || ext == ".png" || ext == ".gif" ||
-		ext == ".bmp" || ext == ".svg" || ext == ".webp" || ext == ".heic"
+		ext == ".bmp" || ext == ".svg" || ext == ".webp" || ext == ".heic" ||
+		ext == ".heif" || ext == ".tif" || ext == ".tiff"
 }
 
 // IsVideoFile checks if a file extension represents a video
 func IsVideoFile(ext string) bool {
 	return ext == ".mp4" || ext == ".avi" || ext == ".mov" || ext == ".mkv" ||
-		ext == ".webm" || ext == ".flv" || ext == ".wmv"
+		ext == ".webm" || ext == ".flv" || ext == ".wmv" || ext == ".m4v"
 }
 
 // IsAudioFile checks if a file extension represents audio
 func IsAudioFile(ext string) bool {
 	return ext == ".mp3" || ext == ".wav" || ext == ".flac" || ext == ".aac" ||
-		ext == ".ogg" || ext == ".m4a" || ext == ".wma"
+		ext == ".ogg" || ext == ".m4a" || ext == ".wma" || ext == ".opus" || ext == ".oga"
 }
 
 // IsProjectMarkerFile checks if a filename indicates a software project
 // (package managers, build files, etc.)
 func IsProjectMarkerFile(name string) bool {
 	markers := []string{"package.json", "pubspec.yaml", "go.mod", "Cargo.toml",
-		"requirements.txt", "pom.xml", "build.gradle", "Gemfile", "composer.json"}
+		"requirements.txt", "pyproject.toml", "Pipfile", "pom.xml", "build.gradle",
+		"build.gradle.kts", "Gemfile", "composer.json"}
 	lowerName := strings.ToLower(name)
 	for _, marker := range markers {
 		if lowerName == strings.ToLower(marker) {
@@ -76,11 +80,21 @@ func ExtractYear(filename string) string {
 // Returns: List of topic words (minimum 4 characters, excluding stop words)
 func ExtractTopicsFromFilename(filename string) []string {
 	// Remove extension and split by common separators
-	name := strings.TrimSuffix(filename, filepath.Ext(filename))
-	name = strings.ToLower(name)
+	return ExtractTopicsFromText(strings.TrimSuffix(filename, filepath.Ext(filename)))
+}
+
+// ExtractTopicsFromText extracts meaningful words from free text such as a
+// document title or table-of-contents entry, using the same rules as
+// ExtractTopicsFromFilename.
+//
+// Example: "Distributed Systems: Principles" → ["distributed", "systems", "principles"]
+//
+// Returns: List of topic words (minimum 4 characters, excluding stop words)
+func ExtractTopicsFromText(text string) []string {
+	name := strings.ToLower(text)
 
-	// Replace separators with spaces
-	name = regexp.MustCompile(`[_\-.]`).ReplaceAllString(name, " ")
+	// Replace separators (and punctuation) with spaces
+	name = regexp.MustCompile(`[_\-.:;,!?()"'/]`).ReplaceAllString(name, " ")
 
 	// Remove numbers and years
 	name = regexp.MustCompile(`\b\d+\b`).ReplaceAllString(name, "")
@@ -133,3 +147,17 @@ func ShouldPrioritizeDoc(filename string) bool {
 	}
 	return false
 }
+
+// FormatBytes converts byte count to human-readable format (KB, MB, GB, etc.)
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
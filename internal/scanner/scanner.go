@@ -4,8 +4,10 @@ package scanner
 import (
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // FileType represents the type of file system entry.
@@ -20,16 +22,36 @@ const (
 
 // FileInfo contains metadata about a single file.
 type FileInfo struct {
-	Name    string   // Base filename (e.g., "main.go")
-	Path    string   // Full path to file
-	Type    FileType // File or Directory
-	FileExt string   // File extension (e.g., ".go")
-	Size    int64    // Size in bytes
+	Name    string    // Base filename (e.g., "main.go")
+	Path    string    // Full path to file (path within the FS for ScanFS)
+	Type    FileType  // File or Directory
+	FileExt string    // File extension (e.g., ".go")
+	Size    int64     // Size in bytes
+	ModTime time.Time // Last modification time
+}
+
+// visibleDotDirs are hidden directories that are scanned anyway, because
+// they hold project configuration such as CI workflows
+var visibleDotDirs = map[string]bool{
+	".github":       true,
+	".devcontainer": true,
+}
+
+// visibleDotFiles are hidden configuration files that are scanned anyway
+var visibleDotFiles = map[string]bool{
+	".gitlab-ci.yml":          true,
+	".devcontainer.json":      true,
+	".eslintrc.json":          true,
+	".prettierrc.json":        true,
+	".babelrc.json":           true,
+	".golangci.yml":           true,
+	".golangci.yaml":          true,
+	".pre-commit-config.yaml": true,
 }
 
 // ScanResult contains the complete scan of a directory.
 type ScanResult struct {
-	Path           string     // Root directory path
+	Path           string     // Root directory path ("." for ScanFS)
 	Files          []FileInfo // All files found
 	Subdirectories []string   // Paths to subdirectories
 }
@@ -38,53 +60,92 @@ type ScanResult struct {
 // information about all files and subdirectories.
 //
 // Hidden files (starting with '.') are automatically excluded to
-// avoid scanning system files, git directories, etc.
+// avoid scanning system files, git directories, etc. The exceptions are
+// project configuration: the .github and .devcontainer directories and
+// CI and tooling configs such as .gitlab-ci.yml and .golangci.yml.
 //
 // Parameters:
 //   - root: Path to directory to scan
 //
 // Returns:
-//   - *ScanResult: Complete directory structure
+//   - *ScanResult: Complete directory structure, with full OS paths
 //   - error: Any error encountered during scanning
 func ScanDirectory(root string) (*ScanResult, error) {
+	summary, err := ScanFS(os.DirFS(root))
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Path = root
+	for i := range summary.Files {
+		summary.Files[i].Path = filepath.Join(root, filepath.FromSlash(summary.Files[i].Path))
+	}
+	for i, sub := range summary.Subdirectories {
+		summary.Subdirectories[i] = filepath.Join(root, filepath.FromSlash(sub))
+	}
+	return summary, nil
+}
+
+// ScanFS recursively walks a file system, such as os.DirFS, a zip.Reader,
+// an embed.FS or an fstest.MapFS, and collects information about all files
+// and subdirectories with the same rules as ScanDirectory.
+//
+// Parameters:
+//   - fsys: File system to scan from its root
+//
+// Returns:
+//   - *ScanResult: Complete structure, with slash-separated paths within fsys
+//   - error: Any error encountered during scanning
+func ScanFS(fsys fs.FS) (*ScanResult, error) {
 	summary := &ScanResult{
-		Path: root,
+		Path: ".",
 	}
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // Skip entries that can't be read
 		}
 
+		// The root itself is named "."
+		if path == "." {
+			return nil
+		}
+
 		name := d.Name()
 
-		// skip hidden files
+		// skip hidden files, except project configuration
 		if strings.HasPrefix(name, ".") {
 			if d.IsDir() {
-				return filepath.SkipDir // Don't recurse into hidden dirs
+				if visibleDotDirs[name] {
+					summary.Subdirectories = append(summary.Subdirectories, path)
+					return nil
+				}
+				return fs.SkipDir // Don't recurse into hidden dirs
+			}
+			if !visibleDotFiles[name] {
+				return nil
 			}
-			return nil
 		}
 
-		// Track subdirectories (but don't add root itself)
-		if d.IsDir() && path != root {
+		// Track subdirectories
+		if d.IsDir() {
 			summary.Subdirectories = append(summary.Subdirectories, path)
 			return nil
 		}
 
 		// Add regular files to the result
-		if !d.IsDir() {
-			info, _ := d.Info()
-			fileExt := strings.ToLower(filepath.Ext(name))
-
-			summary.Files = append(summary.Files, FileInfo{
-				Name:    name,
-				Path:    path,
-				Type:    File,
-				FileExt: fileExt,
-				Size:    info.Size(),
-			})
+		info, err := d.Info()
+		if err != nil {
+			return nil
 		}
+		summary.Files = append(summary.Files, FileInfo{
+			Name:    name,
+			Path:    path,
+			Type:    File,
+			FileExt: strings.ToLower(filepath.Ext(name)),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
 
 		return nil
 	})
@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":                         {Data: []byte("package main\n")},
+		"README.MD":                       {Data: []byte("# Demo\n")},
+		"docs/guide.txt":                  {Data: []byte("guide")},
+		".env":                            {Data: []byte("SECRET=1")},
+		".git/config":                     {Data: []byte("[core]")},
+		".github/workflows/ci.yml":        {Data: []byte("on: push")},
+		".golangci.yml":                   {Data: []byte("linters: {}")},
+		".devcontainer/devcontainer.json": {Data: []byte("{}")},
+	}
+
+	result, err := ScanFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Path != "." {
+		t.Errorf("Path = %q, want .", result.Path)
+	}
+
+	exts := map[string]string{}
+	for _, f := range result.Files {
+		exts[f.Path] = f.FileExt
+		if f.Type != File {
+			t.Errorf("%s: Type = %v, want File", f.Path, f.Type)
+		}
+	}
+	wantExts := map[string]string{
+		".devcontainer/devcontainer.json": ".json",
+		".github/workflows/ci.yml":        ".yml",
+		".golangci.yml":                   ".yml",
+		"README.MD":                       ".md",
+		"docs/guide.txt":                  ".txt",
+		"main.go":                         ".go",
+	}
+	if !reflect.DeepEqual(exts, wantExts) {
+		t.Errorf("files = %v\nwant %v", exts, wantExts)
+	}
+
+	wantDirs := []string{".devcontainer", ".github", ".github/workflows", "docs"}
+	if !reflect.DeepEqual(result.Subdirectories, wantDirs) {
+		t.Errorf("Subdirectories = %v, want %v", result.Subdirectories, wantDirs)
+	}
+}
+
+func TestScanFSEmpty(t *testing.T) {
+	result, err := ScanFS(fstest.MapFS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 0 || len(result.Subdirectories) != 0 {
+		t.Errorf("ScanFS(empty) = %+v, want nothing", result)
+	}
+}
@@ -3,6 +3,10 @@ package scout
 import (
 	"encoding/json"
 	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,25 +20,38 @@ type DomainType string
 
 // during analysis, we try to group the different preview/details into groups for better insights on what scout is looking at
 const (
-	DomainSoftwareProject DomainType = "software"  // Code projects
-	DomainDocuments       DomainType = "documents" // General documents
-	DomainMedia           DomainType = "media"     // Photos, videos, audio
-	DomainStudyMaterials  DomainType = "study"     // Educational content
-	DomainFinancial       DomainType = "financial" // Financial records
-	DomainCreative        DomainType = "creative"  // Design assets
-	DomainMixed           DomainType = "mixed"     // No clear category
-	DomainEmpty           DomainType = "empty"     // No files
+	DomainSoftwareProject DomainType = "software"       // Code projects
+	DomainDocuments       DomainType = "documents"      // General documents
+	DomainMedia           DomainType = "media"          // Photos, videos, audio
+	DomainStudyMaterials  DomainType = "study"          // Educational content
+	DomainFinancial       DomainType = "financial"      // Financial records
+	DomainCreative        DomainType = "creative"       // Design assets
+	DomainDataScience     DomainType = "data"           // Notebooks and datasets
+	DomainWebsite         DomainType = "website"        // Static sites and HTML documentation
+	DomainInfrastructure  DomainType = "infrastructure" // Deployment manifests, containers and pipelines
+	DomainMixed           DomainType = "mixed"          // No clear category
+	DomainEmpty           DomainType = "empty"          // No files
 )
 
 // ContentInsight contains intelligent analysis of a directory's contents
 type ContentInsight struct {
-	Domain          DomainType     // Primary category detected
-	Topics          []string       // Key themes/technologies found
-	DateRange       string         // Time span of content (if applicable)
-	KeyFiles        []string       // Most important files to look at
-	FilesByCategory map[string]int // Distribution of file types
-	Recommendations []string       // Suggested actions for user
-	Confidence      float64        // How confident we are (0.0-1.0)
+	Domain          DomainType      // Primary category detected
+	Topics          []string        // Key themes/technologies found
+	DateRange       string          // Time span of content (if applicable)
+	KeyFiles        []string        // Most important files to look at
+	FilesByCategory map[string]int  // Distribution of file types
+	LinesByLanguage []LanguageLines // Line counts per programming language, most code first
+	Recommendations []string        // Suggested actions for user
+	Confidence      float64         // How confident we are (0.0-1.0)
+}
+
+// LanguageLines holds cloc-style line counts for one programming language
+type LanguageLines struct {
+	Language string `json:"language"` // Display name (Go, TypeScript, C++)
+	Files    int    `json:"files"`    // Source files in the language
+	Blank    int    `json:"blank"`    // Empty lines
+	Comment  int    `json:"comment"`  // Lines holding only comments
+	Code     int    `json:"code"`     // Lines with code on them
 }
 
 // AnalyzeDirectory performs intelligent analysis on directory contents
@@ -55,6 +72,7 @@ func AnalyzeDirectory(summary *DirectorySummary) *ContentInsight {
 
 	categories := categorizeFiles(summary.Files)
 	insight.FilesByCategory = categories
+	insight.LinesByLanguage = countLinesByLanguage(summary.Files)
 
 	// Detect domain based on file distribution and content
 	insight.Domain = detectDomain(categories, summary.Files)
@@ -72,6 +90,12 @@ func AnalyzeDirectory(summary *DirectorySummary) *ContentInsight {
 		extractFinancialInsights(insight, summary.Files)
 	case DomainCreative:
 		extractCreativeInsights(insight)
+	case DomainDataScience:
+		extractDataScienceInsights(insight, summary.Files)
+	case DomainWebsite:
+		extractWebsiteInsights(insight, summary.Files)
+	case DomainInfrastructure:
+		extractInfrastructureInsights(insight, summary.Files)
 	default:
 		extractMixedInsights(insight, summary.Files)
 	}
@@ -80,57 +104,117 @@ func AnalyzeDirectory(summary *DirectorySummary) *ContentInsight {
 }
 
 // categorizeFiles groups files into broad categories for analysis
-//
-// Categories:
-//   - code, config: Software development files
-//   - pdf, word, text, spreadsheet, presentation: Documents
-//   - image, video, audio: Media files
-//   - archive: Compressed files
-//   - other: Everything else
+// (see FileCategory for the list of categories)
 func categorizeFiles(files []FileSummary) map[string]int {
 	categories := make(map[string]int)
 
 	for _, file := range files {
-		ext := strings.ToLower(file.Extension)
-		name := strings.ToLower(file.Name)
+		categories[FileCategory(file)]++
+	}
 
-		switch {
-		// software
-		case helpers.IsCodeFile(ext):
-			categories["code"]++
-		case helpers.IsConfigFile(ext, name):
-			categories["config"]++
-			// Documents
-		case ext == ".pdf":
-			categories["pdf"]++
-		case ext == ".docx" || ext == ".doc":
-			categories["word"]++
-		case ext == ".xlsx" || ext == ".xls" || ext == ".csv":
-			categories["spreadsheet"]++
-		case ext == ".pptx" || ext == ".ppt":
-			categories["presentation"]++
-		case ext == ".txt" || ext == ".md":
-			categories["text"]++
-
-		// Media
-		case helpers.IsImageFile(ext):
-			categories["image"]++
-		case helpers.IsVideoFile(ext):
-			categories["video"]++
-		case helpers.IsAudioFile(ext):
-			categories["audio"]++
-
-		// Archives
-		case ext == ".zip" || ext == ".rar" || ext == ".7z" || ext == ".tar" || ext == ".gz":
-			categories["archive"]++
-
-		default:
-			categories["other"]++
+	return categories
+
+}
+
+// languageNames are the display names of the languages the code
+// extractor counts lines for
+var languageNames = map[string]string{
+	"go": "Go", "python": "Python", "javascript": "JavaScript", "typescript": "TypeScript",
+	"java": "Java", "rust": "Rust", "c": "C", "cpp": "C++", "csharp": "C#", "kotlin": "Kotlin",
+	"swift": "Swift", "dart": "Dart", "ruby": "Ruby", "php": "PHP", "shell": "Shell",
+}
+
+// countLinesByLanguage totals the blank, comment and code lines the code
+// extractor counted, per language, ordered by code lines (descending)
+func countLinesByLanguage(files []FileSummary) []LanguageLines {
+	totals := make(map[string]*LanguageLines)
+	for _, file := range files {
+		details, _ := file.Metadata["details"].(map[string]any)
+		counts, ok := details["line_counts"].(map[string]int)
+		if !ok {
+			continue
+		}
+		language, _ := details["language"].(string)
+		name, ok := languageNames[language]
+		if !ok {
+			continue
+		}
+		total := totals[name]
+		if total == nil {
+			total = &LanguageLines{Language: name}
+			totals[name] = total
 		}
+		total.Files++
+		total.Blank += counts["blank"]
+		total.Comment += counts["comment"]
+		total.Code += counts["code"]
 	}
 
-	return categories
+	result := make([]LanguageLines, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Code != result[j].Code {
+			return result[i].Code > result[j].Code
+		}
+		return result[i].Language < result[j].Language
+	})
+	return result
+}
+
+// FileCategory returns the broad analysis category of a single file
+//
+// Categories:
+//   - code, config: Software development files
+//   - notebook: Jupyter notebooks
+//   - pdf, word, text, ebook, spreadsheet, presentation, html: Documents
+//   - image, video, audio: Media files
+//   - archive: Compressed files
+//   - other: Everything else
+func FileCategory(file FileSummary) string {
+	ext := strings.ToLower(file.Extension)
+	name := strings.ToLower(file.Name)
+
+	switch {
+	// software (scripts without an extension are code by their shebang)
+	case helpers.IsCodeFile(ext) || file.Type == "code":
+		return "code"
+	case ext == ".ipynb":
+		return "notebook"
+	case helpers.IsConfigFile(ext, name):
+		return "config"
+		// Documents
+	case ext == ".pdf":
+		return "pdf"
+	case ext == ".docx" || ext == ".doc" || ext == ".odt":
+		return "word"
+	case ext == ".xlsx" || ext == ".xls" || ext == ".csv" || ext == ".tsv" || ext == ".ods":
+		return "spreadsheet"
+	case ext == ".pptx" || ext == ".ppt" || ext == ".odp":
+		return "presentation"
+	case ext == ".txt" || ext == ".md":
+		return "text"
+	case ext == ".epub":
+		return "ebook"
+	case ext == ".html" || ext == ".htm" || ext == ".xhtml":
+		return "html"
+
+	// Media
+	case helpers.IsImageFile(ext):
+		return "image"
+	case helpers.IsVideoFile(ext):
+		return "video"
+	case helpers.IsAudioFile(ext):
+		return "audio"
+
+	// Archives
+	case ext == ".zip" || ext == ".rar" || ext == ".7z" || ext == ".tar" || ext == ".gz" || ext == ".tgz":
+		return "archive"
 
+	default:
+		return "other"
+	}
 }
 
 // detectDomain uses heuristics to determine the primary purpose of a directory
@@ -154,9 +238,11 @@ func detectDomain(categories map[string]int, files []FileSummary) DomainType {
 
 	// Calculate percentages of different type of domains available (% dist)
 	codePercent := float64(categories["code"]+categories["config"]) / float64(total)
-	docPercent := float64(categories["pdf"]+categories["word"]+categories["text"]) / float64(total)
+	docPercent := float64(categories["pdf"]+categories["word"]+categories["text"]+categories["ebook"]+categories["presentation"]+categories["html"]) / float64(total)
 	mediaPercent := float64(categories["image"]+categories["video"]+categories["audio"]) / float64(total)
 	spreadsheetPercent := float64(categories["spreadsheet"]) / float64(total)
+	notebookPercent := float64(categories["notebook"]) / float64(total)
+	htmlPercent := float64(categories["html"]) / float64(total)
 
 	// Check for software project markers (mostly a strong indication that the directory is a software)
 	hasProjectMarkers := false
@@ -167,11 +253,53 @@ func detectDomain(categories map[string]int, files []FileSummary) DomainType {
 		}
 	}
 
+	// A folder that is mostly archives is a project when they package one,
+	// as with vendor deliveries shipped as a single zip
+	if categories["archive"]*2 > total && len(archivedMarkers(files)) > 0 {
+		hasProjectMarkers = true
+	}
+
 	// Domain detection logic
-	if hasProjectMarkers || codePercent > 0.3 {
+	// Notebooks outnumbering source files, usually next to the data they
+	// read, make a data science workspace even with a requirements.txt
+	if categories["notebook"] > categories["code"] && (notebookPercent > 0.3 || notebookPercent+spreadsheetPercent > 0.5) {
+		return DomainDataScience
+	}
+
+	if hasProjectMarkers {
+		return DomainSoftwareProject
+	}
+
+	// Kubernetes manifests, Compose files and pipelines count as config, so
+	// they would read as code; outnumbering the source files, they make an
+	// infrastructure repository
+	infraFiles := 0
+	for _, file := range files {
+		if infrastructureTool(file) != "" {
+			infraFiles++
+		}
+	}
+	if infraFiles > categories["code"] && float64(infraFiles)/float64(total) > 0.3 {
+		return DomainInfrastructure
+	}
+
+	if codePercent > 0.3 {
 		return DomainSoftwareProject
 	}
 
+	// HTML pages outnumbering source files make a static website or exported
+	// documentation; an index.html at the root marks one even among many images
+	hasRootIndex := false
+	for _, file := range files {
+		if strings.EqualFold(filepath.ToSlash(file.Path), "index.html") {
+			hasRootIndex = true
+			break
+		}
+	}
+	if categories["html"] > categories["code"] && (htmlPercent > 0.3 || (hasRootIndex && htmlPercent > 0.1)) {
+		return DomainWebsite
+	}
+
 	if docPercent > 0.5 {
 		// Check if study materials
 		if hasStudyKeywords(files) {
@@ -244,7 +372,8 @@ func hasStudyKeywords(files []FileSummary) bool {
 	return matchCount >= 3
 }
 
-// hasFinancialKeywords checks if filenames contain financial terms
+// hasFinancialKeywords checks if filenames or spreadsheet column headers
+// contain financial terms
 //
 // Requires at least 2 files with financial keywords to return true
 func hasFinancialKeywords(files []FileSummary) bool {
@@ -253,7 +382,11 @@ func hasFinancialKeywords(files []FileSummary) bool {
 
 	matchCount := 0
 	for _, file := range files {
-		lowerName := strings.ToLower(file.Name)
+		lowerName := financialText(file)
+		if vatRe.MatchString(lowerName) {
+			matchCount++
+			continue
+		}
 		for _, keyword := range keywords {
 			if strings.Contains(lowerName, keyword) {
 				matchCount++
@@ -292,7 +425,7 @@ func extractDocumentInsights(insight *ContentInsight, files []FileSummary) {
 	years := make(map[string]bool)
 
 	for _, file := range files {
-		if file.Extension == ".pdf" || file.Extension == ".docx" {
+		if isReadableDocument(file.Extension) {
 
 			// extract year if present
 			if year := helpers.ExtractYear(file.Name); year != "" {
@@ -305,6 +438,11 @@ func extractDocumentInsights(insight *ContentInsight, files []FileSummary) {
 				topics[topic]++
 			}
 
+			// e-books carry a real title and table of contents
+			for _, topic := range documentTopics(file) {
+				topics[topic]++
+			}
+
 			// Prioritize files for key files list
 			if helpers.ShouldPrioritizeDoc(file.Name) {
 				insight.KeyFiles = append(insight.KeyFiles, file.Name)
@@ -316,24 +454,21 @@ func extractDocumentInsights(insight *ContentInsight, files []FileSummary) {
 		insight.Topics = append(insight.Topics, topic)
 	}
 
-	// Set date range if years found
-	if len(years) > 0 {
-		minYear, maxYear := "", ""
-		for year := range years {
-			if minYear == "" || year < minYear {
-				minYear = year
-			}
-			if maxYear == "" || year > maxYear {
-				maxYear = year
-			}
-		}
-		if minYear == maxYear {
-			insight.DateRange = minYear
-		} else {
-			insight.DateRange = fmt.Sprintf("%s-%s", minYear, maxYear)
+	// Titles and tables of contents can yield many words; keep the most frequent
+	sort.Slice(insight.Topics, func(i, j int) bool {
+		a, b := insight.Topics[i], insight.Topics[j]
+		if topics[a] != topics[b] {
+			return topics[a] > topics[b]
 		}
+		return a < b
+	})
+	if len(insight.Topics) > maxDocumentTopics {
+		insight.Topics = insight.Topics[:maxDocumentTopics]
 	}
 
+	// Set date range if years found
+	insight.DateRange = yearRange(years)
+
 	// Generate recommendations
 	if len(insight.KeyFiles) == 0 {
 		// Find most recent or important looking files
@@ -349,6 +484,54 @@ func extractDocumentInsights(insight *ContentInsight, files []FileSummary) {
 	}
 }
 
+// isReadableDocument reports whether an extension is a prose document
+// (PDF, word processor, presentation or e-book) worth reading first
+func isReadableDocument(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".pdf", ".docx", ".doc", ".odt", ".pptx", ".odp", ".epub":
+		return true
+	}
+	return false
+}
+
+// maxDocumentTopics bounds how many topics are reported for document folders
+const maxDocumentTopics = 15
+
+// genericTOCWords are table-of-contents words that say nothing about the subject
+var genericTOCWords = map[string]bool{
+	"chapter": true, "contents": true, "introduction": true, "preface": true,
+	"index": true, "appendix": true, "acknowledgements": true, "acknowledgments": true,
+	"foreword": true, "copyright": true, "title": true, "page": true, "part": true,
+	"cover": true, "about": true, "author": true, "notes": true, "bibliography": true,
+}
+
+// documentTopics extracts topics from the title and table of contents an
+// extractor found inside a document (e.g. EPUB package metadata)
+func documentTopics(file FileSummary) []string {
+	details, ok := file.Metadata["details"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var texts []string
+	if title, ok := details["title"].(string); ok {
+		texts = append(texts, title)
+	}
+	if toc, ok := details["toc"].([]string); ok {
+		texts = append(texts, toc...)
+	}
+
+	var topics []string
+	for _, text := range texts {
+		for _, topic := range helpers.ExtractTopicsFromText(text) {
+			if !genericTOCWords[topic] {
+				topics = append(topics, topic)
+			}
+		}
+	}
+	return topics
+}
+
 // extractSoftwareInsights analyzes software project directories
 // Detects tech stack, finds entry points, and locates README
 func extractSoftwareInsights(insight *ContentInsight, files []FileSummary) {
@@ -374,6 +557,42 @@ func extractSoftwareInsights(insight *ContentInsight, files []FileSummary) {
 		}
 	}
 
+	// Archives that package a project are worth opening
+	var packaged []string
+	for _, file := range files {
+		if len(archivedMarkers([]FileSummary{file})) > 0 {
+			insight.KeyFiles = append(insight.KeyFiles, file.Name)
+			packaged = append(packaged, file.Name)
+		}
+	}
+
+	// Outlined Go files name their main packages, which are the entry
+	// points wherever they live (cmd/server/server.go), and their tests
+	var goMains []FileSummary
+	goTests, goTestFiles := 0, 0
+	for _, file := range files {
+		details, _ := file.Metadata["details"].(map[string]any)
+		if language, _ := details["language"].(string); language != "go" {
+			continue
+		}
+		if hasMain, _ := details["has_main"].(bool); hasMain {
+			goMains = append(goMains, file)
+			if !slices.Contains(insight.KeyFiles, file.Name) {
+				insight.KeyFiles = append(insight.KeyFiles, file.Name)
+			}
+		}
+		if count, _ := details["test_count"].(int); count > 0 {
+			goTests += count
+			goTestFiles++
+		}
+	}
+
+	// The manifest lists the dependencies and scripts
+	manifest := rootManifest(files)
+	if manifest != nil {
+		insight.KeyFiles = append(insight.KeyFiles, manifest.Name)
+	}
+
 	if len(insight.KeyFiles) == 0 {
 		insight.KeyFiles = []string{"Look in the src/ or lib/ directory"}
 	}
@@ -383,6 +602,59 @@ func extractSoftwareInsights(insight *ContentInsight, files []FileSummary) {
 		"Check the main entry point to understand flow",
 		"Review package/dependency files for tech stack",
 	}
+	if len(goMains) > 0 {
+		dir := filepath.ToSlash(filepath.Dir(goMains[0].Path))
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Run the program with go run ./%s", strings.TrimPrefix(dir, ".")))
+	}
+	if goTests > 0 {
+		testFiles := fmt.Sprintf("%d files", goTestFiles)
+		if goTestFiles == 1 {
+			testFiles = "1 file"
+		}
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Run go test ./... to exercise the %d tests in %s 🧪", goTests, testFiles))
+	}
+	if manifest != nil {
+		details, _ := manifest.Metadata["details"].(map[string]any)
+		ecosystem, _ := details["ecosystem"].(string)
+		scripts, _ := details["scripts"].(map[string]string)
+		if runner, ok := scriptRunners[ecosystem]; ok && len(scripts) > 0 {
+			var names []string
+			for name := range scripts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			insight.Recommendations = append(insight.Recommendations,
+				fmt.Sprintf("%s defines scripts for %s, run them with %s <name> ⚙️", manifest.Name,
+					strings.Join(names[:min(len(names), 5)], ", "), runner))
+		}
+	}
+	for _, name := range packaged {
+		// Zip files and tarballs can be scanned in place; 7z needs unpacking
+		tip := "Run sc %s to explore the project inside 📦"
+		if strings.ToLower(path.Ext(name)) == ".7z" {
+			tip = "Unpack %s to explore the project inside 📦"
+		}
+		insight.Recommendations = append(insight.Recommendations, fmt.Sprintf(tip, name))
+	}
+}
+
+// archivedMarkers returns the project marker files (go.mod, package.json,
+// ...) found inside the given archives, as base names
+func archivedMarkers(files []FileSummary) []string {
+	var markers []string
+	for _, file := range files {
+		details, ok := file.Metadata["details"].(map[string]any)
+		if !ok || file.Type != "archive" {
+			continue
+		}
+		paths, _ := details["project_markers"].([]string)
+		for _, p := range paths {
+			markers = append(markers, path.Base(p))
+		}
+	}
+	return markers
 }
 
 // extractMediaInsights analyzes media file directories
@@ -391,36 +663,153 @@ func extractMediaInsights(insight *ContentInsight, files []FileSummary) {
 	videoCount := 0
 	audioCount := 0
 
+	// EXIF metadata tells camera shots from screenshots and dates the folder
+	cameras := make(map[string]int)
+	cameraShots := 0
+	photoYears := make(map[string]bool)
+
+	// Container tags tell titled videos from raw footage, and a music
+	// library from podcast recordings
+	rawClips := 0
+	videoYears := make(map[string]bool)
+	artists := make(map[string]int)
+	musicTracks, podcastEpisodes := 0, 0
+	audioYears := make(map[string]bool)
+
 	for _, file := range files {
 		ext := strings.ToLower(file.Extension)
+		details, _ := file.Metadata["details"].(map[string]any)
 		if helpers.IsImageFile(ext) {
 			imageCount++
+			if camera, ok := details["camera"].(string); ok {
+				cameras[camera]++
+				cameraShots++
+			}
+			if taken, ok := details["taken"].(string); ok && len(taken) >= 4 {
+				photoYears[taken[:4]] = true
+			}
 		} else if helpers.IsVideoFile(ext) {
 			videoCount++
+			if _, titled := details["title"]; !titled {
+				rawClips++
+			}
+			if created, ok := details["created"].(string); ok && len(created) >= 4 {
+				videoYears[created[:4]] = true
+			}
 		} else if helpers.IsAudioFile(ext) {
 			audioCount++
+			genre, _ := details["genre"].(string)
+			duration, _ := details["duration_seconds"].(int)
+			artist, _ := details["artist"].(string)
+			album, _ := details["album"].(string)
+			switch {
+			case isSpokenGenre(genre):
+				podcastEpisodes++
+			case artist != "" || album != "":
+				musicTracks++
+				if artist != "" {
+					artists[artist]++
+				}
+			case duration >= podcastMinSeconds:
+				// Untagged long recordings: episodes, lectures or meetings
+				podcastEpisodes++
+			}
+			if year, ok := details["year"].(string); ok && len(year) == 4 {
+				audioYears[year] = true
+			}
 		}
 	}
 
 	if imageCount > videoCount && imageCount > audioCount {
-		insight.Topics = []string{"photos", "images"}
-		insight.Recommendations = []string{"Browse through and enjoy the memories! 📸"}
+		if cameraShots == 0 && len(photoYears) == 0 {
+			insight.Topics = []string{"screenshots", "images"}
+			insight.Recommendations = []string{"No camera metadata found, these look like screenshots or exported graphics 🖼️"}
+		} else {
+			insight.Topics = append([]string{"photos", "images"}, topByCount(cameras)...)
+			insight.Recommendations = []string{"Browse through and enjoy the memories! 📸"}
+		}
+		insight.DateRange = yearRange(photoYears)
 	} else if videoCount > 0 {
-		insight.Topics = []string{"videos"}
-		insight.Recommendations = []string{"Grab some popcorn and enjoy! 🍿"}
+		if rawClips*2 > videoCount {
+			insight.Topics = []string{"videos", "raw footage"}
+			insight.Recommendations = []string{"Untitled camera clips, sort them by shoot date before editing 🎬"}
+		} else {
+			insight.Topics = []string{"videos"}
+			insight.Recommendations = []string{"Grab some popcorn and enjoy! 🍿"}
+		}
+		insight.DateRange = yearRange(videoYears)
 	} else if audioCount > 0 {
-		insight.Topics = []string{"music", "audio"}
-		insight.Recommendations = []string{"Put on your headphones and vibe! 🎵"}
+		if podcastEpisodes > musicTracks {
+			insight.Topics = []string{"podcasts", "audio"}
+			insight.Recommendations = []string{"Queue up an episode and listen in 🎙️"}
+		} else {
+			insight.Topics = append([]string{"music", "audio"}, topByCount(artists)...)
+			insight.Recommendations = []string{"Put on your headphones and vibe! 🎵"}
+			insight.DateRange = yearRange(audioYears)
+		}
 	}
 }
 
+// podcastMinSeconds is the length from which a recording without artist or
+// album tags is treated as spoken word (an episode, lecture or meeting)
+// rather than a song; tagged long tracks (live sets, DJ mixes, classical
+// movements) stay music
+const podcastMinSeconds = 20 * 60
+
+// isSpokenGenre reports whether an audio genre tag marks spoken word
+func isSpokenGenre(genre string) bool {
+	genre = strings.ToLower(genre)
+	return strings.Contains(genre, "podcast") || strings.Contains(genre, "speech") ||
+		strings.Contains(genre, "audiobook") || strings.Contains(genre, "spoken")
+}
+
+// maxMediaTopics bounds how many camera models, artists or libraries are listed as topics
+const maxMediaTopics = 3
+
+// topByCount returns the most frequent names (camera models, artists, libraries),
+// most frequent first
+func topByCount(counts map[string]int) []string {
+	var names []string
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a < b
+	})
+	if len(names) > maxMediaTopics {
+		names = names[:maxMediaTopics]
+	}
+	return names
+}
+
+// yearRange formats a set of years as "2021" or "2019-2023" ("" when empty)
+func yearRange(years map[string]bool) string {
+	minYear, maxYear := "", ""
+	for year := range years {
+		if minYear == "" || year < minYear {
+			minYear = year
+		}
+		if maxYear == "" || year > maxYear {
+			maxYear = year
+		}
+	}
+	if minYear == maxYear {
+		return minYear
+	}
+	return fmt.Sprintf("%s-%s", minYear, maxYear)
+}
+
 // extractFinancialInsights analyzes financial document directories
 func extractFinancialInsights(insight *ContentInsight, files []FileSummary) {
 	topics := make(map[string]bool)
 
 	for _, file := range files {
-		name := strings.ToLower(file.Name)
-		if strings.Contains(name, "tax") {
+		name := financialText(file)
+		if strings.Contains(name, "tax") || vatRe.MatchString(name) {
 			topics["taxes"] = true
 		}
 		if strings.Contains(name, "assessment") {
@@ -448,6 +837,389 @@ func extractFinancialInsights(insight *ContentInsight, files []FileSummary) {
 	}
 }
 
+// vatRe matches "VAT" as a whole word; as a substring it appears in words
+// like "private" and "renovation"
+var vatRe = regexp.MustCompile(`\bvat\b`)
+
+// financialText returns the lowercased file name followed by any spreadsheet
+// column headers, which name financial content ("Invoice No", "VAT") even
+// when the file name does not
+func financialText(file FileSummary) string {
+	text := file.Name
+	if details, ok := file.Metadata["details"].(map[string]any); ok {
+		if headers, ok := details["headers"].([]string); ok {
+			text += " " + strings.Join(headers, " ")
+		}
+	}
+	return strings.ToLower(text)
+}
+
+// maxHighlightedFiles bounds how many notebooks or pages are highlighted
+const maxHighlightedFiles = 3
+
+// extractDataScienceInsights analyzes notebook folders: the languages and
+// libraries the notebooks use, which ones to open first and which ones
+// saved a failing run
+func extractDataScienceInsights(insight *ContentInsight, files []FileSummary) {
+	languages := make(map[string]int)
+	libraries := make(map[string]int)
+	var notebooks, failing []FileSummary
+	datasets := 0
+	requirements := ""
+
+	for _, file := range files {
+		details, _ := file.Metadata["details"].(map[string]any)
+		switch {
+		case file.Type == "notebook":
+			notebooks = append(notebooks, file)
+			if language, ok := details["language"].(string); ok {
+				languages[notebookLanguageName(language)]++
+			}
+			if imports, ok := details["imports"].([]string); ok {
+				for _, library := range imports {
+					libraries[library]++
+				}
+			}
+			if failed, _ := details["has_errors"].(bool); failed {
+				failing = append(failing, file)
+			}
+		case FileCategory(file) == "spreadsheet" || strings.EqualFold(file.Extension, ".parquet"):
+			datasets++
+		case strings.EqualFold(file.Name, "requirements.txt") || strings.EqualFold(file.Name, "environment.yml"):
+			requirements = file.Name
+		case strings.EqualFold(file.Name, "readme.md"):
+			insight.KeyFiles = append(insight.KeyFiles, file.Name)
+		}
+	}
+
+	insight.Topics = append(topByCount(languages), topByCount(libraries)...)
+
+	// Numbered notebooks (01_eda, 02_model) are meant to be read in order;
+	// otherwise the ones with the most cells carry the analysis
+	sort.SliceStable(notebooks, func(i, j int) bool {
+		a, b := notebooks[i], notebooks[j]
+		if numberedA, numberedB := startsWithDigit(a.Name), startsWithDigit(b.Name); numberedA != numberedB {
+			return numberedA
+		} else if numberedA {
+			return a.Name < b.Name
+		}
+		return notebookCells(a) > notebookCells(b)
+	})
+	for _, file := range notebooks[:min(len(notebooks), maxHighlightedFiles)] {
+		insight.KeyFiles = append(insight.KeyFiles, file.Name)
+	}
+
+	if len(notebooks) > 0 {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Start with %s and run the notebooks top to bottom 🧪", notebooks[0].Name))
+	}
+	for _, file := range failing[:min(len(failing), 2)] {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("%s was saved with errors in its output, re-run it before trusting the results", file.Name))
+	}
+	if requirements != "" {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Install the dependencies from %s first", requirements))
+	} else if len(libraries) > 0 {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Install the libraries the notebooks import (%s)", strings.Join(topByCount(libraries), ", ")))
+	}
+	if datasets > 0 {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Check the %d data files next to the notebooks 📊", datasets))
+	}
+}
+
+// docGenerators are site generators whose output is documentation
+var docGenerators = []string{"sphinx", "docutils", "mkdocs", "javadoc", "doxygen", "docusaurus",
+	"pdoc", "rustdoc", "jsdoc", "typedoc", "gitbook", "vuepress", "docfx"}
+
+// extractWebsiteInsights analyzes static sites and exported HTML
+// documentation: the generator that built them, the entry page and the
+// third-party scripts they load
+func extractWebsiteInsights(insight *ContentInsight, files []FileSummary) {
+	generators := make(map[string]int)
+	scriptHosts := make(map[string]bool)
+	var pages []FileSummary
+
+	for _, file := range files {
+		if FileCategory(file) != "html" {
+			continue
+		}
+		pages = append(pages, file)
+		details, _ := file.Metadata["details"].(map[string]any)
+		if generator, ok := details["generator"].(string); ok {
+			generators[generatorName(generator)]++
+		}
+		scripts, _ := details["scripts"].([]string)
+		for _, src := range scripts {
+			if host := scriptHost(src); host != "" {
+				scriptHosts[host] = true
+			}
+		}
+	}
+
+	documentation := false
+	for generator := range generators {
+		for _, docs := range docGenerators {
+			if strings.EqualFold(generator, docs) {
+				documentation = true
+			}
+		}
+	}
+	if documentation {
+		insight.Topics = append([]string{"documentation"}, topByCount(generators)...)
+	} else {
+		insight.Topics = append([]string{"website"}, topByCount(generators)...)
+	}
+
+	// The entry page first, then the shallowest pages
+	sort.SliceStable(pages, func(i, j int) bool {
+		a, b := pages[i], pages[j]
+		if rootA, rootB := strings.EqualFold(filepath.ToSlash(a.Path), "index.html"), strings.EqualFold(filepath.ToSlash(b.Path), "index.html"); rootA != rootB {
+			return rootA
+		}
+		return strings.Count(a.Path, string(filepath.Separator)) < strings.Count(b.Path, string(filepath.Separator))
+	})
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		if len(insight.KeyFiles) == maxHighlightedFiles {
+			break
+		}
+		if !seen[page.Name] {
+			seen[page.Name] = true
+			insight.KeyFiles = append(insight.KeyFiles, page.Name)
+		}
+	}
+
+	entry := "index.html"
+	if len(pages) > 0 {
+		entry = pages[0].Name
+	}
+	if documentation {
+		insight.Recommendations = []string{
+			fmt.Sprintf("Open %s in a browser to read the documentation 📚", entry),
+			"These pages are generated, edit the documentation sources rather than the HTML",
+		}
+	} else {
+		insight.Recommendations = []string{fmt.Sprintf("Open %s in a browser to preview the site 🌐", entry)}
+	}
+	if len(scriptHosts) > 0 {
+		var hosts []string
+		for host := range scriptHosts {
+			hosts = append(hosts, host)
+		}
+		sort.Strings(hosts)
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Pages load scripts from %s, review them before publishing", strings.Join(hosts[:min(len(hosts), 3)], ", ")))
+	}
+}
+
+// generatorName drops the version from a generator meta tag
+// ("Hugo 0.121.1" → "Hugo", "MkDocs-1.5.3, mkdocs-material" → "MkDocs")
+func generatorName(generator string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(generator), " ")
+	name, _, _ = strings.Cut(name, "-")
+	name, _, _ = strings.Cut(name, "/")
+	return strings.TrimRight(name, ",:")
+}
+
+// scriptHost returns the host of a script loaded from another site
+// ("" for scripts served by the site itself)
+func scriptHost(src string) string {
+	rest, ok := strings.CutPrefix(src, "https://")
+	if !ok {
+		rest, ok = strings.CutPrefix(src, "http://")
+	}
+	if !ok {
+		rest, ok = strings.CutPrefix(src, "//")
+	}
+	if !ok {
+		return ""
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	return host
+}
+
+// notebookLanguageName capitalizes kernel languages for display
+func notebookLanguageName(language string) string {
+	if language == "" {
+		return language
+	}
+	return strings.ToUpper(language[:1]) + language[1:]
+}
+
+// notebookCells counts a notebook's code and markdown cells
+func notebookCells(file FileSummary) int {
+	details, _ := file.Metadata["details"].(map[string]any)
+	code, _ := details["code_cells"].(int)
+	markdown, _ := details["markdown_cells"].(int)
+	return code + markdown
+}
+
+// startsWithDigit reports whether a file name is numbered, like "01_eda.ipynb"
+func startsWithDigit(name string) bool {
+	return name != "" && name[0] >= '0' && name[0] <= '9'
+}
+
+// infrastructureTools name the tool behind each infrastructure schema
+// family reported by the config extractor
+var infrastructureTools = map[string]string{
+	"kubernetes":     "Kubernetes",
+	"kustomize":      "Kustomize",
+	"helm-chart":     "Helm",
+	"helm-values":    "Helm",
+	"helm-template":  "Helm",
+	"docker-compose": "Docker Compose",
+	"cloudformation": "CloudFormation",
+	"ansible":        "Ansible",
+	"serverless":     "Serverless Framework",
+	"github-actions": "GitHub Actions",
+	"gitlab-ci":      "GitLab CI",
+}
+
+// infrastructureTool returns the tool a file configures ("Kubernetes",
+// "Terraform", ...), or "" for files that are not infrastructure
+func infrastructureTool(file FileSummary) string {
+	details, _ := file.Metadata["details"].(map[string]any)
+	if schema, ok := details["schema"].(string); ok {
+		if tool, ok := infrastructureTools[schema]; ok {
+			return tool
+		}
+	}
+	name := strings.ToLower(file.Name)
+	switch ext := strings.ToLower(file.Extension); {
+	case name == "dockerfile" || strings.HasPrefix(name, "dockerfile.") || ext == ".dockerfile":
+		return "Docker"
+	case ext == ".tf" || ext == ".tfvars":
+		return "Terraform"
+	}
+	return ""
+}
+
+// infrastructureEntryPoints rank the files to open first: the chart, the
+// overlay and the Compose file tie the rest together
+var infrastructureEntryPoints = map[string]int{"helm-chart": 3, "kustomize": 2, "docker-compose": 1}
+
+// extractInfrastructureInsights analyzes infrastructure repositories: the
+// tools and resource kinds they use, the entry points to start from and the
+// risks worth checking before deploying (committed secrets, unpinned images,
+// files that do not parse)
+func extractInfrastructureInsights(insight *ContentInsight, files []FileSummary) {
+	tools := make(map[string]int)
+	kinds := make(map[string]int)
+	var infra, broken []FileSummary
+	var unpinned []string
+	entries := make(map[string]FileSummary)
+	manifests := 0
+
+	for _, file := range files {
+		details, _ := file.Metadata["details"].(map[string]any)
+		if _, ok := details["parse_error"]; ok {
+			broken = append(broken, file)
+		}
+		tool := infrastructureTool(file)
+		if tool == "" {
+			continue
+		}
+		tools[tool]++
+		infra = append(infra, file)
+
+		schema, _ := details["schema"].(string)
+		if _, ok := infrastructureEntryPoints[schema]; ok {
+			if _, seen := entries[schema]; !seen {
+				entries[schema] = file
+			}
+		}
+		if schema == "kubernetes" {
+			manifests++
+		}
+		if counts, ok := details["kinds"].(map[string]int); ok && schema != "cloudformation" {
+			for kind, n := range counts {
+				kinds[kind] += n
+			}
+		}
+		images, _ := details["images"].([]string)
+		for _, image := range images {
+			if tag := image[strings.LastIndex(image, "/")+1:]; (!strings.Contains(tag, ":") || strings.HasSuffix(tag, ":latest")) &&
+				!strings.Contains(image, "@") && len(unpinned) < 3 {
+				unpinned = append(unpinned, image)
+			}
+		}
+	}
+
+	insight.Topics = append(topByCount(tools), topByCount(kinds)...)
+
+	// Entry points first, then the files declaring the most resources
+	sort.SliceStable(infra, func(i, j int) bool {
+		a, b := infra[i], infra[j]
+		rankA, rankB := infrastructureEntryPoints[configSchema(a)], infrastructureEntryPoints[configSchema(b)]
+		if rankA != rankB {
+			return rankA > rankB
+		}
+		return resourceCount(a) > resourceCount(b)
+	})
+	seen := make(map[string]bool)
+	for _, file := range infra {
+		if len(insight.KeyFiles) == maxHighlightedFiles {
+			break
+		}
+		if !seen[file.Name] {
+			seen[file.Name] = true
+			insight.KeyFiles = append(insight.KeyFiles, file.Name)
+		}
+	}
+
+	if chart, ok := entries["helm-chart"]; ok {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Render the chart with helm template %s to review the manifests it produces ⎈", filepath.Dir(chart.Path)))
+	}
+	if overlay, ok := entries["kustomize"]; ok {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Preview the overlay with kubectl kustomize %s", filepath.Dir(overlay.Path)))
+	} else if manifests > 0 {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Validate the %d Kubernetes manifests with kubectl apply --dry-run=server before applying them 🚢", manifests))
+	}
+	if compose, ok := entries["docker-compose"]; ok {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Start the stack locally with docker compose -f %s up 🐳", compose.Path))
+	}
+	if tools["Terraform"] > 0 {
+		insight.Recommendations = append(insight.Recommendations,
+			"Run terraform init and terraform plan to see what would change before applying")
+	}
+	if secrets := kinds["Secret"]; secrets == 1 {
+		insight.Recommendations = append(insight.Recommendations,
+			"A Secret resource is committed in a plain manifest, make sure it holds no real credentials 🔐")
+	} else if secrets > 1 {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("%d Secret resources are committed in plain manifests, make sure they hold no real credentials 🔐", secrets))
+	}
+	if len(unpinned) > 0 {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("Pin the image tags of %s instead of relying on latest", strings.Join(unpinned, ", ")))
+	}
+	for _, file := range broken[:min(len(broken), 2)] {
+		insight.Recommendations = append(insight.Recommendations,
+			fmt.Sprintf("%s does not parse, fix it before deploying", file.Path))
+	}
+}
+
+// configSchema returns the schema family the config extractor detected
+func configSchema(file FileSummary) string {
+	details, _ := file.Metadata["details"].(map[string]any)
+	schema, _ := details["schema"].(string)
+	return schema
+}
+
+// resourceCount returns how many resources a manifest or template declares
+func resourceCount(file FileSummary) int {
+	details, _ := file.Metadata["details"].(map[string]any)
+	count, _ := details["resource_count"].(int)
+	return count
+}
+
 // extractCreativeInsights provides generic insights for creative work
 func extractCreativeInsights(insight *ContentInsight) {
 	insight.Topics = []string{"creative work", "design assets"}
@@ -520,7 +1292,7 @@ func findImportantDocs(files []FileSummary, limit int) []string {
 	for _, file := range files {
 		// Filter: Only look at Documents
 		ext := strings.ToLower(file.Extension)
-		if ext != ".pdf" && ext != ".docx" && ext != ".doc" {
+		if !isReadableDocument(ext) {
 			continue
 		}
 
@@ -552,7 +1324,7 @@ func findImportantDocs(files []FileSummary, limit int) []string {
 		scored = append(scored, scoredFile{name: file.Name, score: score})
 	}
 
-	// If no PDF/Docx found at all, try to return ANY large file
+	// If no documents found at all, try to return ANY large file
 	// This prevents the "Mixed Domain" hallucination I experienced when the folder has no PDFs
 	if len(scored) == 0 && len(files) > 0 {
 		// Just grab the largest files regardless of extension
@@ -583,6 +1355,11 @@ func findImportantDocs(files []FileSummary, limit int) []string {
 func detectTechStack(files []FileSummary) []string {
 	stacks := make(map[string]bool)
 
+	// Manifests packaged inside archives count like top-level ones
+	for _, marker := range archivedMarkers(files) {
+		files = append(files, FileSummary{Name: marker, Extension: path.Ext(marker)})
+	}
+
 	for _, file := range files {
 		name := strings.ToLower(file.Name)
 		ext := strings.ToLower(file.Extension)
@@ -596,15 +1373,21 @@ func detectTechStack(files []FileSummary) []string {
 		if name == "go.mod" {
 			stacks["Go"] = true
 		}
-		if name == "requirements.txt" || name == "pipfile" {
+		if name == "requirements.txt" || name == "pipfile" || name == "pyproject.toml" {
 			stacks["Python"] = true
 		}
 		if name == "cargo.toml" {
 			stacks["Rust"] = true
 		}
-		if name == "pom.xml" || name == "build.gradle" {
+		if name == "pom.xml" || name == "build.gradle" || name == "build.gradle.kts" {
 			stacks["Java"] = true
 		}
+		if name == "gemfile" {
+			stacks["Ruby"] = true
+		}
+		if name == "composer.json" {
+			stacks["PHP"] = true
+		}
 		if name == "cmakelists.txt" || name == "makefile" {
 			stacks["C/C++ (Make/CMake)"] = true
 		}
@@ -620,9 +1403,45 @@ func detectTechStack(files []FileSummary) []string {
 	for stack := range stacks {
 		result = append(result, stack)
 	}
+	sort.Strings(result)
+
+	// Frameworks read from the manifests name the stack more precisely
+	// than the languages alone ("Go, Gin, GORM")
+	for _, file := range files {
+		details, _ := file.Metadata["details"].(map[string]any)
+		frameworks, _ := details["frameworks"].([]string)
+		for _, framework := range frameworks {
+			if !stacks[framework] {
+				stacks[framework] = true
+				result = append(result, framework)
+			}
+		}
+	}
 	return result
 }
 
+// scriptRunners are the commands that run a manifest's scripts, by ecosystem
+var scriptRunners = map[string]string{"npm": "npm run", "composer": "composer"}
+
+// rootManifest returns the shallowest package manifest (not a lockfile),
+// or nil when the project has none
+func rootManifest(files []FileSummary) *FileSummary {
+	var root *FileSummary
+	for i, file := range files {
+		details, _ := file.Metadata["details"].(map[string]any)
+		if kind, _ := details["type"].(string); kind != "manifest" {
+			continue
+		}
+		if lockfile, _ := details["lockfile"].(bool); lockfile {
+			continue
+		}
+		if root == nil || strings.Count(file.Path, string(filepath.Separator)) < strings.Count(root.Path, string(filepath.Separator)) {
+			root = &files[i]
+		}
+	}
+	return root
+}
+
 // GeneratePrompt creates a Llama-3 formatted prompt for AI analysis
 //
 // The prompt includes:
@@ -652,6 +1471,7 @@ func GeneratePrompt(insight *ContentInsight, summary *DirectorySummary) string {
 📁 This folder contains:
   - [total_files] files total
   - [total_files_by_category]
+  - [lines_of_code per language, if any]
 
 🎯 Likely Purpose:
   [Hypothesis based strictly on file previews]
@@ -686,7 +1506,7 @@ func GeneratePrompt(insight *ContentInsight, summary *DirectorySummary) string {
 				keyFilesCtx = append(keyFilesCtx, KeyFileContext{
 					Name:     f.Name,
 					Type:     f.Extension,
-					Size:     formatBytes(f.Size),
+					Size:     helpers.FormatBytes(f.Size),
 					Metadata: f.Metadata,
 				})
 				break
@@ -696,6 +1516,7 @@ func GeneratePrompt(insight *ContentInsight, summary *DirectorySummary) string {
 
 	contextData := map[string]any{
 		"stats":           insight.FilesByCategory,
+		"lines_of_code":   insight.LinesByLanguage,
 		"total_files":     summary.FileCount,
 		"domain_detected": insight.Domain,
 		"key_files_data":  keyFilesCtx, // Only the top relevant files with content
@@ -710,17 +1531,3 @@ func GeneratePrompt(insight *ContentInsight, summary *DirectorySummary) string {
 	return fmt.Sprintf("<|begin_of_text|><|start_header_id|>system<|end_header_id|>\n\n%s<|eot_id|><|start_header_id|>user<|end_header_id|>\n\n%s<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n",
 		systemPrompt, userPrompt)
 }
-
-// formatBytes converts byte count to human-readable format (KB, MB, GB, etc.)
-func formatBytes(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
-	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
-}
@@ -0,0 +1,54 @@
+package scout
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractDocumentInsightsTopics(t *testing.T) {
+	toc := []string{
+		"Chapter 1: Graphs", "Chapter 2: Trees", "Heaps", "Queues", "Stacks", "Tries",
+		"Sorting", "Hashing", "Strings", "Matrices", "Geometry", "Probability",
+		"Recursion", "Caching", "Parsing", "Compilers", "Networks",
+	}
+	book := FileSummary{
+		Name:      "graphs-and-trees.epub",
+		Extension: ".epub",
+		Metadata:  map[string]any{"details": map[string]any{"title": "Graphs and Trees", "toc": toc}},
+	}
+	notes := FileSummary{Name: "graphs-notes.pdf", Extension: ".pdf"}
+
+	var insight ContentInsight
+	extractDocumentInsights(&insight, []FileSummary{book, notes})
+
+	// Most frequent first, ties by name, at most maxDocumentTopics
+	want := []string{
+		"graphs", "trees", "caching", "compilers", "geometry", "hashing", "heaps",
+		"matrices", "networks", "notes", "parsing", "probability", "queues", "recursion", "sorting",
+	}
+	if !reflect.DeepEqual(insight.Topics, want) {
+		t.Errorf("Topics = %v\nwant %v", insight.Topics, want)
+	}
+}
+
+func TestExtractWebsiteInsightsEntryPage(t *testing.T) {
+	page := func(path string) FileSummary {
+		path = filepath.FromSlash(path)
+		return FileSummary{Name: filepath.Base(path), Path: path, Extension: ".html"}
+	}
+	files := []FileSummary{
+		page("docs/guide/deep.html"),
+		page("docs/intro.html"),
+		page("index.html"),
+	}
+
+	var insight ContentInsight
+	extractWebsiteInsights(&insight, files)
+
+	// The root index first, then the shallowest pages
+	want := []string{"index.html", "intro.html", "deep.html"}
+	if !reflect.DeepEqual(insight.KeyFiles, want) {
+		t.Errorf("KeyFiles = %v, want %v", insight.KeyFiles, want)
+	}
+}
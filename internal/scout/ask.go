@@ -0,0 +1,199 @@
+package scout
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/DeleMike/scout/internal/helpers"
+)
+
+// Turn is a single question/answer exchange of an ask session.
+// Previous turns are replayed to the model so follow-up questions keep context.
+type Turn struct {
+	Question string // What the user asked
+	Answer   string // What the model replied
+}
+
+// maxHistoryTurns bounds how many previous turns are replayed in the prompt
+const maxHistoryTurns = 6
+
+// askPreviewBudget caps how much of each retrieved file's preview goes into the prompt
+const askPreviewBudget = 1500
+
+// askStopWords are ignored when matching questions against file contents
+var askStopWords = map[string]bool{
+	"the": true, "and": true, "are": true, "for": true, "from": true, "how": true,
+	"is": true, "was": true, "what": true, "where": true, "which": true, "who": true,
+	"why": true, "with": true, "this": true, "that": true, "does": true, "handled": true,
+	"file": true, "files": true, "there": true, "any": true, "can": true, "into": true,
+}
+
+// RetrieveFiles ranks scanned files against a free-text query using the file
+// path, extracted preview and details, and returns the best matches.
+//
+// Scoring per query term:
+//   - path contains term: +5
+//   - each occurrence in the preview: +1 (capped at 5)
+//   - details mention term: +2
+//
+// Parameters:
+//   - summary: Directory scan with extracted metadata
+//   - query: Question (and optionally prior context) to match
+//   - limit: Maximum number of files to return
+//
+// Returns: Matching files, best first (files with no match are omitted)
+func RetrieveFiles(summary *DirectorySummary, query string, limit int) []FileSummary {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	type scoredFile struct {
+		file  FileSummary
+		score int
+	}
+	var scored []scoredFile
+
+	for _, file := range summary.Files {
+		path := strings.ToLower(file.Path)
+		preview, _ := file.Metadata["preview"].(string)
+		preview = strings.ToLower(preview)
+		details := ""
+		if d, ok := file.Metadata["details"]; ok {
+			raw, _ := json.Marshal(d)
+			details = strings.ToLower(string(raw))
+		}
+
+		score := 0
+		for _, term := range terms {
+			if strings.Contains(path, term) {
+				score += 5
+			}
+			score += min(strings.Count(preview, term), 5)
+			if strings.Contains(details, term) {
+				score += 2
+			}
+		}
+
+		if score > 0 {
+			scored = append(scored, scoredFile{file: file, score: score})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	var result []FileSummary
+	for i := 0; i < len(scored) && i < limit; i++ {
+		result = append(result, scored[i].file)
+	}
+	return result
+}
+
+// FilesByPath returns the scanned files for the given relative paths,
+// in the order given, skipping duplicates and unknown paths
+func FilesByPath(summary *DirectorySummary, paths []string) []FileSummary {
+	byPath := make(map[string]FileSummary, len(summary.Files))
+	for _, file := range summary.Files {
+		byPath[file.Path] = file
+	}
+
+	seen := make(map[string]bool)
+	var result []FileSummary
+	for _, path := range paths {
+		file, ok := byPath[path]
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+		result = append(result, file)
+	}
+	return result
+}
+
+// queryTerms lowercases and splits a query into meaningful search terms
+func queryTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range words {
+		if len(word) < 3 || askStopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// GenerateAskPrompt creates a Llama-3 formatted multi-turn prompt that answers
+// a question about the scanned directory from retrieved file previews.
+//
+// The prompt includes:
+//   - System instructions to answer only from the supplied files and cite them
+//   - Previous turns of the session (most recent maxHistoryTurns)
+//   - The directory overview and retrieved file context for the new question
+//
+// Returns: Complete prompt ready for Llama inference
+func GenerateAskPrompt(question string, files []FileSummary, insight *ContentInsight, history []Turn) string {
+	systemPrompt := `You are Scout, an assistant that answers questions about a folder on the user's machine.
+
+### RULES:
+- Answer ONLY from the directory overview and file excerpts provided.
+- If the excerpts do not contain the answer, say so and suggest where to look.
+- Cite the files you used by their path, e.g. (see internal/auth/login.go).
+- Keep answers short and direct. Do not use Markdown headers.`
+
+	type fileContext struct {
+		Path    string `json:"path"`
+		Size    string `json:"size"`
+		Excerpt string `json:"excerpt"`
+		Details any    `json:"details,omitempty"`
+	}
+
+	var filesCtx []fileContext
+	for _, f := range files {
+		preview, _ := f.Metadata["preview"].(string)
+		if len(preview) > askPreviewBudget {
+			preview = strings.ToValidUTF8(preview[:askPreviewBudget], "") + "..."
+		}
+		filesCtx = append(filesCtx, fileContext{
+			Path:    f.Path,
+			Size:    helpers.FormatBytes(f.Size),
+			Excerpt: preview,
+			Details: f.Metadata["details"],
+		})
+	}
+
+	contextData := map[string]any{
+		"domain_detected": insight.Domain,
+		"topics":          insight.Topics,
+		"stats":           insight.FilesByCategory,
+		"lines_of_code":   insight.LinesByLanguage,
+		"relevant_files":  filesCtx,
+	}
+	contextJSON, _ := json.MarshalIndent(contextData, "", "  ")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<|begin_of_text|><|start_header_id|>system<|end_header_id|>\n\n%s<|eot_id|>", systemPrompt)
+
+	if len(history) > maxHistoryTurns {
+		history = history[len(history)-maxHistoryTurns:]
+	}
+	for _, turn := range history {
+		fmt.Fprintf(&b, "<|start_header_id|>user<|end_header_id|>\n\n%s<|eot_id|>", turn.Question)
+		fmt.Fprintf(&b, "<|start_header_id|>assistant<|end_header_id|>\n\n%s<|eot_id|>", turn.Answer)
+	}
+
+	fmt.Fprintf(&b, "<|start_header_id|>user<|end_header_id|>\n\nDirectory Data:\n%s\n\nQuestion: %s<|eot_id|>", string(contextJSON), question)
+	b.WriteString("<|start_header_id|>assistant<|end_header_id|>\n\n")
+
+	return b.String()
+}
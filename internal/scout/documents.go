@@ -0,0 +1,129 @@
+package scout
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DeleMike/scout/internal/embedding"
+	"github.com/DeleMike/scout/internal/extractor"
+	"github.com/DeleMike/scout/internal/search"
+)
+
+// IndexTextLimit is the extraction budget (in bytes) per file when the
+// search indexes are built, large enough for the full text of most files
+const IndexTextLimit = 1024 * 1024
+
+// unindexedTypes are the categories whose preview already is all the text
+// there is: descriptions of binary content rather than text from the file
+var unindexedTypes = map[string]bool{
+	"archive": true, "binary": true, "image": true, "audio": true, "video": true,
+}
+
+// LoadText re-extracts every scanned text file with the IndexTextLimit
+// budget and stores the result in FileSummary.Text, so the search indexes
+// cover whole files rather than the short scan previews. Only the first
+// call does any work.
+//
+// Parameters:
+//   - summary: Scan results of a directory, an archive or a file system
+//     passed to RunFS
+func LoadText(summary *DirectorySummary) {
+	if summary.textLoaded {
+		return
+	}
+	summary.textLoaded = true
+
+	fsys, closer, err := summary.openFS()
+	if err != nil {
+		fmt.Printf("⚠️  Could not reopen %s, indexing file previews only: %v\n", summary.Directory, err)
+		return
+	}
+	defer closer.Close()
+	for i := range summary.Files {
+		file := &summary.Files[i]
+		if unindexedTypes[file.Type] || file.Type == "unknown" {
+			continue
+		}
+		content, err := extractor.DetectExtractor(file.Name, IndexTextLimit).Extract(fsys, filepath.ToSlash(file.Path))
+		if err != nil {
+			continue
+		}
+		file.Text = content.Preview
+	}
+}
+
+// openFS returns the file system the summary was scanned from: the one
+// given to RunFS if it is still open, otherwise the directory or archive
+// at Directory
+func (summary *DirectorySummary) openFS() (fs.FS, io.Closer, error) {
+	if summary.fsys != nil {
+		return summary.fsys, io.NopCloser(nil), nil
+	}
+	info, err := os.Stat(summary.Directory)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(summary.Directory), io.NopCloser(nil), nil
+	}
+	return extractor.OpenArchive(summary.Directory)
+}
+
+// SemanticDocuments converts scanned files into documents for the embedding
+// index. The path and title are included so they influence the vectors too.
+// Call LoadText first to embed whole files rather than their previews.
+func SemanticDocuments(summary *DirectorySummary) []embedding.Document {
+	docs := make([]embedding.Document, 0, len(summary.Files))
+	for _, file := range summary.Files {
+		docs = append(docs, embedding.Document{
+			Path:    file.Path,
+			ModTime: file.ModTime,
+			Size:    file.Size,
+			Text:    indexText(file),
+		})
+	}
+	return docs
+}
+
+// SearchDocuments converts scanned files into documents for the full-text
+// index, carrying the attributes that search filters apply to. Call
+// LoadText first to index whole files rather than their previews.
+func SearchDocuments(summary *DirectorySummary) []search.Document {
+	docs := make([]search.Document, 0, len(summary.Files))
+	for _, file := range summary.Files {
+		docs = append(docs, search.Document{
+			Path:      file.Path,
+			Category:  FileCategory(file),
+			Type:      file.Type,
+			Extension: file.Extension,
+			ModTime:   file.ModTime,
+			Text:      indexText(file),
+		})
+	}
+	return docs
+}
+
+// indexText is the searchable text of a file: its path, title (if the
+// extractor found one) and full text (see LoadText), or its preview when
+// the full text was not loaded
+func indexText(file FileSummary) string {
+	var text strings.Builder
+	text.WriteString(file.Path)
+	text.WriteString("\n")
+	if details, ok := file.Metadata["details"].(map[string]any); ok {
+		if title, ok := details["title"].(string); ok && title != "" {
+			text.WriteString(title)
+			text.WriteString("\n")
+		}
+	}
+	body := file.Text
+	if body == "" {
+		body, _ = file.Metadata["preview"].(string)
+	}
+	text.WriteString(body)
+	return text.String()
+}
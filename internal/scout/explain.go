@@ -0,0 +1,178 @@
+package scout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DeleMike/scout/internal/extractor"
+	"github.com/DeleMike/scout/internal/helpers"
+)
+
+// ExplainPreviewLimit is the preview budget (in bytes) used when a single
+// file is explained, much larger than the per-file budget of a directory scan
+const ExplainPreviewLimit = 12000
+
+// maxRelatedFiles bounds how many related files are listed for a file
+const maxRelatedFiles = 10
+
+// ExtractFile runs the appropriate extractor on a single file using the
+// larger ExplainPreviewLimit budget.
+//
+// Parameters:
+//   - path: Path to the file
+//
+// Returns:
+//   - *FileSummary: File metadata with extracted content
+//   - error: If the file cannot be read or extracted
+func ExtractFile(path string) (*FileSummary, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("'%s' is a directory; use sc to analyze folders", path)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	content, err := extractor.ExtractFile(extractor.DetectExtractor(path, ExplainPreviewLimit), path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSummary{
+		Name:      info.Name(),
+		Path:      path,
+		Type:      content.Category,
+		Extension: ext,
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+		Metadata:  metadataFrom(content),
+	}, nil
+}
+
+// RelatedFiles picks sibling files that look related to file: those sharing
+// its name stem (e.g. parser.go and parser_test.go) and those whose name is
+// mentioned in its extracted content.
+//
+// Parameters:
+//   - file: File being explained
+//   - siblings: Names of the other files in the same directory
+//
+// Returns: Up to maxRelatedFiles names, sorted
+func RelatedFiles(file *FileSummary, siblings []string) []string {
+	stem := strings.ToLower(strings.TrimSuffix(file.Name, filepath.Ext(file.Name)))
+	preview, _ := file.Metadata["preview"].(string)
+	preview = strings.ToLower(preview)
+
+	var related []string
+	for _, name := range siblings {
+		if name == file.Name || strings.HasPrefix(name, ".") {
+			continue
+		}
+		lower := strings.ToLower(name)
+		siblingStem := strings.TrimSuffix(lower, filepath.Ext(lower))
+
+		sharesStem := len(stem) >= 3 && (strings.HasPrefix(siblingStem, stem) || strings.HasPrefix(stem, siblingStem))
+		mentioned := len(siblingStem) >= 3 && strings.Contains(preview, siblingStem)
+		if sharesStem || mentioned {
+			related = append(related, name)
+		}
+	}
+
+	sort.Strings(related)
+	if len(related) > maxRelatedFiles {
+		related = related[:maxRelatedFiles]
+	}
+	return related
+}
+
+// GenerateExplainPrompt creates a Llama-3 formatted prompt asking for a
+// focused summary of one file: its purpose, key sections or functions,
+// and how it relates to nearby files.
+//
+// Returns: Complete prompt ready for Llama inference
+func GenerateExplainPrompt(file *FileSummary, related []string) string {
+	systemPrompt := `You are Scout, an assistant that explains what a single file is for.
+
+### ⛔ NEGATIVE CONSTRAINTS (CRITICAL):
+- DO NOT output internal reasoning or chain-of-thought.
+- DO NOT use Markdown headers (like ## or ###). Use the emojis as headers.
+- OUTPUT ONLY the final result starting with the 📄 emoji.
+
+### REQUIRED OUTPUT FORMAT:
+
+📄 Purpose:
+  [One or two sentences on what this file is and why it exists]
+
+🧩 Key Sections:
+  - [Important function, class, section, sheet or heading and what it does]
+
+🔗 Related Files:
+  - [Nearby file and how it relates, only if supported by the data]
+
+### RULES:
+- BE TRUTHFUL. Base everything on the content provided.
+- Keep it concise.`
+
+	contextData := map[string]any{
+		"name":          file.Name,
+		"extension":     file.Extension,
+		"content_type":  file.Type,
+		"size":          helpers.FormatBytes(file.Size),
+		"lines":         file.Metadata["lines"],
+		"details":       file.Metadata["details"],
+		"content":       file.Metadata["preview"],
+		"related_files": related,
+	}
+	contextJSON, _ := json.MarshalIndent(contextData, "", "  ")
+
+	userPrompt := fmt.Sprintf("Explain this file:\n%s", string(contextJSON))
+
+	// Llama 3 Prompt Format
+	return fmt.Sprintf("<|begin_of_text|><|start_header_id|>system<|end_header_id|>\n\n%s<|eot_id|><|start_header_id|>user<|end_header_id|>\n\n%s<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n",
+		systemPrompt, userPrompt)
+}
+
+// DescribeFile renders a model-free description of a file from its
+// extracted metadata, in the same layout as the explain prompt asks for.
+func DescribeFile(file *FileSummary, related []string) string {
+	var b strings.Builder
+
+	b.WriteString("📄 Purpose:\n")
+	fmt.Fprintf(&b, "  %s (%s, %s", file.Name, file.Type, helpers.FormatBytes(file.Size))
+	if lines, ok := file.Metadata["lines"].(int); ok && lines > 0 {
+		fmt.Fprintf(&b, ", %d lines", lines)
+	}
+	b.WriteString(")\n")
+	if desc := describeFile(file); desc != "" {
+		fmt.Fprintf(&b, "  %s\n", desc)
+	}
+
+	b.WriteString("\n🧩 Key Sections:\n")
+	details, _ := file.Metadata["details"].(map[string]any)
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  - %s: %v\n", key, details[key])
+	}
+	if len(keys) == 0 {
+		b.WriteString("  - No structured details were extracted\n")
+	}
+
+	b.WriteString("\n🔗 Related Files:\n")
+	for _, name := range related {
+		fmt.Fprintf(&b, "  - %s\n", name)
+	}
+	if len(related) == 0 {
+		b.WriteString("  - None found nearby\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
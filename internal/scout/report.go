@@ -0,0 +1,183 @@
+package scout
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DeleMike/scout/internal/helpers"
+)
+
+// domainPurposes describes each domain in plain words for the heuristic report
+var domainPurposes = map[DomainType]string{
+	DomainSoftwareProject: "A software project",
+	DomainDocuments:       "A collection of general documents",
+	DomainMedia:           "A media library (photos, videos or audio)",
+	DomainStudyMaterials:  "Study materials such as lecture notes, exams and assignments",
+	DomainFinancial:       "Financial records such as invoices, statements and tax documents",
+	DomainCreative:        "Creative work and design assets",
+	DomainDataScience:     "A data science workspace of notebooks and datasets",
+	DomainWebsite:         "A static website or exported HTML documentation",
+	DomainInfrastructure:  "Infrastructure as code: deployment manifests, containers and CI pipelines",
+	DomainMixed:           "A mix of unrelated files with no single clear purpose",
+	DomainEmpty:           "An empty folder",
+}
+
+// GenerateReport renders a summary in the same 📁/🎯/🔍/👀 layout the model
+// is asked to produce, using only the heuristic analysis and extracted metadata.
+//
+// It is used when no local model is available (or --no-ai is passed), so
+// Scout stays useful on machines that cannot load a GGUF model.
+//
+// Parameters:
+//   - insight: Heuristic analysis from AnalyzeDirectory
+//   - summary: Directory scan with extracted file metadata
+//
+// Returns: Plain-text report (colorize with summarize.FormatForTerminal)
+func GenerateReport(insight *ContentInsight, summary *DirectorySummary) string {
+	var b strings.Builder
+
+	b.WriteString("📁 This folder contains:\n")
+	fmt.Fprintf(&b, "  - %d files total\n", summary.FileCount)
+	for _, c := range sortedCategories(insight.FilesByCategory) {
+		fmt.Fprintf(&b, "  - %d %s files\n", insight.FilesByCategory[c], c)
+	}
+	if len(summary.Subdirectories) > 0 {
+		fmt.Fprintf(&b, "  - %d subdirectories\n", len(summary.Subdirectories))
+	}
+	if lines := describeLines(insight.LinesByLanguage); lines != "" {
+		fmt.Fprintf(&b, "  - %s\n", lines)
+	}
+
+	b.WriteString("\n🎯 Likely Purpose:\n")
+	purpose, ok := domainPurposes[insight.Domain]
+	if !ok {
+		purpose = domainPurposes[DomainMixed]
+	}
+	if len(insight.Topics) > 0 && insight.Domain != DomainMixed {
+		purpose = fmt.Sprintf("%s (%s)", purpose, strings.Join(insight.Topics, ", "))
+	}
+	fmt.Fprintf(&b, "  %s.\n", purpose)
+	fmt.Fprintf(&b, "  Detected with %.0f%% confidence from the file type distribution.\n", insight.Confidence*100)
+
+	b.WriteString("\n🔍 Highlights:\n")
+	highlights := 0
+	for _, name := range insight.KeyFiles {
+		file := findFile(summary, name)
+		if file == nil {
+			continue
+		}
+		if desc := describeFile(file); desc != "" {
+			fmt.Fprintf(&b, "  - Key file: %s — %s\n", file.Name, desc)
+		} else {
+			fmt.Fprintf(&b, "  - Key file: %s (%s)\n", file.Name, helpers.FormatBytes(file.Size))
+		}
+		highlights++
+	}
+	if insight.DateRange != "" {
+		fmt.Fprintf(&b, "  - Content spans %s\n", insight.DateRange)
+		highlights++
+	}
+	if len(insight.Topics) > 0 {
+		fmt.Fprintf(&b, "  - Topics: %s\n", strings.Join(insight.Topics, ", "))
+		highlights++
+	}
+	if highlights == 0 {
+		b.WriteString("  - Nothing stood out from the file names and previews\n")
+	}
+
+	b.WriteString("\n👀 Suggestions:\n")
+	for _, rec := range insight.Recommendations {
+		fmt.Fprintf(&b, "  - %s\n", rec)
+	}
+	if len(insight.Recommendations) == 0 {
+		b.WriteString("  - Browse the largest files first\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// maxReportedLanguages bounds how many languages the line summary names
+const maxReportedLanguages = 4
+
+// describeLines summarizes lines of code per language, e.g.
+// "12k lines of Go, 3k of TypeScript"
+func describeLines(languages []LanguageLines) string {
+	var parts []string
+	for _, language := range languages {
+		if len(parts) == maxReportedLanguages || language.Code == 0 {
+			break
+		}
+		if len(parts) == 0 {
+			parts = append(parts, fmt.Sprintf("%s lines of %s", shortCount(language.Code), language.Language))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s of %s", shortCount(language.Code), language.Language))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortCount abbreviates large counts: 950, 12k, 1.4M
+func shortCount(n int) string {
+	switch {
+	case n >= 999_500:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1_000_000), ".0") + "M"
+	case n >= 10_000:
+		return fmt.Sprintf("%dk", (n+500)/1000)
+	case n >= 1000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000), ".0") + "k"
+	}
+	return fmt.Sprint(n)
+}
+
+// sortedCategories orders category names by file count (descending), then name
+func sortedCategories(categories map[string]int) []string {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if categories[names[i]] != categories[names[j]] {
+			return categories[names[i]] > categories[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// findFile looks up a file by name in the scan results
+func findFile(summary *DirectorySummary, name string) *FileSummary {
+	for i := range summary.Files {
+		if summary.Files[i].Name == name {
+			return &summary.Files[i]
+		}
+	}
+	return nil
+}
+
+// describeFile builds a one-line description from extracted metadata,
+// preferring a document title or a source file's doc comment over the
+// first line of the preview
+func describeFile(file *FileSummary) string {
+	if details, ok := file.Metadata["details"].(map[string]any); ok {
+		if title, ok := details["title"].(string); ok && strings.TrimSpace(title) != "" {
+			return fmt.Sprintf("%q", strings.TrimSpace(title))
+		}
+		if doc, ok := details["doc"].(string); ok && doc != "" {
+			return doc
+		}
+	}
+
+	preview, _ := file.Metadata["preview"].(string)
+	for line := range strings.SplitSeq(preview, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > 80 {
+			line = strings.ToValidUTF8(line[:80], "") + "..."
+		}
+		return line
+	}
+	return ""
+}
@@ -3,6 +3,10 @@ package scout
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/DeleMike/scout/internal/extractor"
 	"github.com/DeleMike/scout/internal/scanner"
@@ -11,18 +15,24 @@ import (
 // FileSummary represents structured metadata for a single file
 type FileSummary struct {
 	Name      string         `json:"name"`               // Filename
+	Path      string         `json:"path"`               // Path relative to the scanned root
 	Type      string         `json:"type"`               // Category (code, document, etc.)
 	Extension string         `json:"extension"`          // File extension
 	Size      int64          `json:"size_bytes"`         // Size in bytes
+	ModTime   time.Time      `json:"modified"`           // Last modification time
 	Metadata  map[string]any `json:"metadata,omitempty"` // Extracted content details
+	Text      string         `json:"-"`                  // Full extracted text for the search indexes, set by LoadText
 }
 
 // DirectorySummary is the complete analysis result for a directory
 type DirectorySummary struct {
 	Directory      string        `json:"directory"`      // Root path
 	FileCount      int           `json:"file_count"`     // Total files
-	Subdirectories []string      `json:"subdirectories"` // Subdirectory paths
+	Subdirectories []string      `json:"subdirectories"` // Subdirectory paths relative to the root
 	Files          []FileSummary `json:"files"`          // List of files in Directory
+
+	fsys       fs.FS // File system the scan read, for LoadText (nil once it is closed)
+	textLoaded bool  // Set once LoadText has run
 }
 
 // Run is the main entry point for directory analysis.
@@ -31,16 +41,50 @@ type DirectorySummary struct {
 //  2. Extract content from each file
 //  3. Analyze patterns and generate insights
 //
+// A zip file or tarball is analyzed as if it were the directory it unpacks to.
+//
 // Parameters:
-//   - root: Path to directory to analyze
+//   - root: Path to directory (or archive) to analyze
 //
 // Returns:
 //   - *DirectorySummary: Structured file metadata
 //   - *ContentInsight: AI-ready analysis and recommendations
 //   - error: Any error encountered during processing
 func Run(root string) (*DirectorySummary, *ContentInsight, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return RunFS(os.DirFS(root), root)
+	}
+
+	fsys, closer, err := extractor.OpenArchive(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closer.Close()
+	summary, insight, err := RunFS(fsys, root)
+	if summary != nil {
+		summary.fsys = nil // closed on return; LoadText reopens the archive
+	}
+	return summary, insight, err
+}
+
+// RunFS runs the same pipeline as Run over any file system: an archive,
+// an embedded FS or an in-memory fstest.MapFS.
+//
+// Parameters:
+//   - fsys: File system to analyze from its root
+//   - root: Name reported as the summary's directory
+//
+// Returns:
+//   - *DirectorySummary: Structured file metadata
+//   - *ContentInsight: AI-ready analysis and recommendations
+//   - error: Any error encountered during processing
+func RunFS(fsys fs.FS, root string) (*DirectorySummary, *ContentInsight, error) {
 	// Scan directory structure
-	dir, err := scanner.ScanDirectory(root)
+	dir, err := scanner.ScanFS(fsys)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -49,6 +93,7 @@ func Run(root string) (*DirectorySummary, *ContentInsight, error) {
 		Directory:      root,
 		Subdirectories: dir.Subdirectories,
 		FileCount:      len(dir.Files),
+		fsys:           fsys,
 	}
 
 	for _, file := range dir.Files {
@@ -57,25 +102,23 @@ func Run(root string) (*DirectorySummary, *ContentInsight, error) {
 		}
 
 		// Get appropriate extractor for this file type
-		extractor := extractor.DetectCategory(file.FileExt)
-		content, err := extractor.Extract(file.Path)
+		extractor := extractor.DetectExtractor(file.Name, 0)
+		content, err := extractor.Extract(fsys, file.Path)
 
 		fileSummary := FileSummary{
 			Name:      file.Name,
+			Path:      filepath.FromSlash(file.Path),
 			Type:      "unknown",
 			Extension: file.FileExt,
 			Size:      file.Size,
+			ModTime:   file.ModTime,
 		}
 
 		if err != nil {
 			fmt.Printf("[%s] extraction error: %v\n", file.Name, err)
 		} else {
 			fileSummary.Type = content.Category
-			fileSummary.Metadata = map[string]any{
-				"preview": content.Preview,
-				"lines":   content.Lines,
-				"details": content.Details,
-			}
+			fileSummary.Metadata = metadataFrom(content)
 		}
 
 		summary.Files = append(summary.Files, fileSummary)
@@ -88,3 +131,12 @@ func Run(root string) (*DirectorySummary, *ContentInsight, error) {
 	return summary, insight, nil
 
 }
+
+// metadataFrom converts extractor output into FileSummary.Metadata
+func metadataFrom(content *extractor.ExtractedContent) map[string]any {
+	return map[string]any{
+		"preview": content.Preview,
+		"lines":   content.Lines,
+		"details": content.Details,
+	}
+}
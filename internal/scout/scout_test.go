@@ -0,0 +1,91 @@
+package scout
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DeleMike/scout/internal/search"
+)
+
+func TestRunFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":      {Data: []byte("module example.com/demo\n\ngo 1.22\n")},
+		"main.go":     {Data: []byte("package main\n\n// main prints a greeting\nfunc main() {\n\tprintln(\"hi\")\n}\n")},
+		"README.md":   {Data: []byte("# Demo\n\nA tiny project.\n")},
+		"cmd/tool.go": {Data: []byte("package main\n")},
+		".git/HEAD":   {Data: []byte("ref: refs/heads/main\n")},
+	}
+
+	summary, insight, err := RunFS(fsys, "demo.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Directory != "demo.zip" || summary.FileCount != 4 {
+		t.Errorf("summary = %s with %d files, want demo.zip with 4", summary.Directory, summary.FileCount)
+	}
+	if !reflect.DeepEqual(summary.Subdirectories, []string{"cmd"}) {
+		t.Errorf("Subdirectories = %v, want [cmd]", summary.Subdirectories)
+	}
+
+	types := map[string]string{}
+	for _, f := range summary.Files {
+		types[f.Path] = f.Type
+	}
+	wantTypes := map[string]string{
+		"README.md":                       "markdown",
+		filepath.FromSlash("cmd/tool.go"): "code",
+		"go.mod":                          "config",
+		"main.go":                         "code",
+	}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("file types = %v, want %v", types, wantTypes)
+	}
+
+	if insight.Domain != DomainSoftwareProject {
+		t.Errorf("Domain = %s, want %s", insight.Domain, DomainSoftwareProject)
+	}
+	wantLines := []LanguageLines{{Language: "Go", Files: 2, Blank: 1, Comment: 1, Code: 5}}
+	if !reflect.DeepEqual(insight.LinesByLanguage, wantLines) {
+		t.Errorf("LinesByLanguage = %+v, want %+v", insight.LinesByLanguage, wantLines)
+	}
+}
+
+func TestLoadTextFromArchive(t *testing.T) {
+	// The search term sits past the scan preview, so only the full text has it
+	notes := strings.Repeat("Nothing to see here yet.\n", 200) + "The zeppelin lands at dawn.\n"
+	path := filepath.Join(t.TempDir(), "notes.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(file)
+	f, _ := w.Create("docs/notes.txt")
+	f.Write([]byte(notes))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	summary, _, err := Run(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := search.ParseQuery("zeppelin")
+	if hits := search.Build(SearchDocuments(summary)).Search(query, search.Filter{}, 10); len(hits) != 0 {
+		t.Fatalf("preview search found %v; the term should be past the preview", hits)
+	}
+
+	LoadText(summary)
+	hits := search.Build(SearchDocuments(summary)).Search(query, search.Filter{}, 10)
+	if len(hits) != 1 || hits[0].Path != filepath.FromSlash("docs/notes.txt") {
+		t.Errorf("hits = %v, want docs/notes.txt", hits)
+	}
+	if docs := SemanticDocuments(summary); len(docs) != 1 || !strings.Contains(docs[0].Text, "zeppelin") {
+		t.Errorf("semantic documents do not hold the full text")
+	}
+}
@@ -0,0 +1,235 @@
+// Package search provides full-text search over the content Scout extracts
+// from files, using an inverted index with BM25 ranking and phrase queries.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BM25 tuning parameters (the usual defaults)
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Document is one file's extracted text plus the attributes filters apply to
+type Document struct {
+	Path      string    // Path relative to the scanned root
+	Category  string    // Broad category (pdf, word, spreadsheet, code, ...)
+	Type      string    // Extractor content type (document, code, ...)
+	Extension string    // File extension including the dot
+	ModTime   time.Time // Last modification time
+	Text      string    // Extracted text to search
+}
+
+// posting records where a term occurs in one document
+type posting struct {
+	doc       int   // Index into Index.docs
+	positions []int // Token positions of the term within the document
+}
+
+// Index is an in-memory inverted index over a set of documents
+type Index struct {
+	docs      []Document
+	lengths   []int // Token count of each document
+	avgLength float64
+	postings  map[string][]posting
+}
+
+// Filter restricts which documents a search may return.
+// Zero-valued fields do not filter.
+type Filter struct {
+	Category  string    // Matches Document.Category or Document.Type
+	Extension string    // e.g. ".docx" (the dot is optional)
+	After     time.Time // Modified on or after
+	Before    time.Time // Modified before
+}
+
+// Hit is a ranked search result
+type Hit struct {
+	Path       string   // Matching file
+	Score      float64  // BM25 score (higher is better)
+	Snippet    string   // Text around the first match
+	Highlights [][2]int // Byte ranges of matched terms within Snippet
+}
+
+// Build creates an index over docs.
+//
+// Parameters:
+//   - docs: Documents to index
+//
+// Returns: Index ready for Search
+func Build(docs []Document) *Index {
+	idx := &Index{
+		docs:     docs,
+		lengths:  make([]int, len(docs)),
+		postings: make(map[string][]posting),
+	}
+
+	total := 0
+	for i, doc := range docs {
+		tokens := tokenize(doc.Text)
+		idx.lengths[i] = len(tokens)
+		total += len(tokens)
+
+		positions := make(map[string][]int)
+		for pos, tok := range tokens {
+			positions[tok.term] = append(positions[tok.term], pos)
+		}
+		for term, pos := range positions {
+			idx.postings[term] = append(idx.postings[term], posting{doc: i, positions: pos})
+		}
+	}
+
+	if len(docs) > 0 {
+		idx.avgLength = float64(total) / float64(len(docs))
+	}
+
+	return idx
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search ranks documents against a query with BM25.
+//
+// Every term and phrase in the query must match (AND semantics); phrase
+// words must appear consecutively. Terms inside phrases also count
+// towards the score.
+//
+// Parameters:
+//   - query: Parsed query (see ParseQuery)
+//   - filter: Category, extension and date restrictions
+//   - limit: Maximum number of hits
+//
+// Returns: Hits, best first
+func (idx *Index) Search(query Query, filter Filter, limit int) []Hit {
+	terms := query.allTerms()
+	if len(terms) == 0 {
+		return nil
+	}
+
+	// Candidates must contain every term
+	candidates := make(map[int]map[string]posting)
+	for i, term := range terms {
+		matched := make(map[int]map[string]posting)
+		for _, p := range idx.postings[term] {
+			if i > 0 {
+				if _, ok := candidates[p.doc]; !ok {
+					continue
+				}
+				matched[p.doc] = candidates[p.doc]
+			} else {
+				matched[p.doc] = make(map[string]posting)
+			}
+			matched[p.doc][term] = p
+		}
+		candidates = matched
+	}
+
+	var hits []Hit
+	for doc, docPostings := range candidates {
+		if !filter.matches(idx.docs[doc]) {
+			continue
+		}
+		if !containsPhrases(docPostings, query.Phrases) {
+			continue
+		}
+
+		score := 0.0
+		for _, term := range terms {
+			score += idx.bm25(term, docPostings[term], doc)
+		}
+
+		snippet, highlights := makeSnippet(idx.docs[doc].Text, terms)
+		hits = append(hits, Hit{
+			Path:       idx.docs[doc].Path,
+			Score:      score,
+			Snippet:    snippet,
+			Highlights: highlights,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Path < hits[j].Path
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// bm25 scores one term for one document
+func (idx *Index) bm25(term string, p posting, doc int) float64 {
+	n := float64(len(idx.docs))
+	df := float64(len(idx.postings[term]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	tf := float64(len(p.positions))
+	norm := 1 - bm25B + bm25B*float64(idx.lengths[doc])/idx.avgLength
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+}
+
+// containsPhrases checks that each phrase occurs as consecutive tokens
+func containsPhrases(docPostings map[string]posting, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		found := false
+		for _, start := range docPostings[phrase[0]].positions {
+			match := true
+			for offset, word := range phrase[1:] {
+				if !hasPosition(docPostings[word].positions, start+offset+1) {
+					match = false
+					break
+				}
+			}
+			if match {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPosition reports whether the sorted positions contain pos
+func hasPosition(positions []int, pos int) bool {
+	i := sort.SearchInts(positions, pos)
+	return i < len(positions) && positions[i] == pos
+}
+
+// matches reports whether a document passes the filter
+func (f Filter) matches(doc Document) bool {
+	if f.Category != "" &&
+		!strings.EqualFold(f.Category, doc.Category) &&
+		!strings.EqualFold(f.Category, doc.Type) {
+		return false
+	}
+	if f.Extension != "" {
+		ext := strings.ToLower(f.Extension)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if ext != strings.ToLower(doc.Extension) {
+			return false
+		}
+	}
+	if !f.After.IsZero() && doc.ModTime.Before(f.After) {
+		return false
+	}
+	if !f.Before.IsZero() && !doc.ModTime.Before(f.Before) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sampleDocs is a small corpus for ranking and phrase tests
+func sampleDocs() []Document {
+	return []Document{
+		{Path: "invoice.pdf", Category: "pdf", Extension: ".pdf", ModTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Text: "Invoice 1042. Payment terms: net 30 days. Invoice total includes VAT."},
+		{Path: "contract.docx", Category: "word", Extension: ".docx", ModTime: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+			Text: "The supplier sends an invoice every month. Payment is due 30 days net of any credit."},
+		{Path: "notes.md", Category: "text", Extension: ".md", ModTime: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			Text: "Meeting notes: discuss the roadmap, hiring and the office move."},
+	}
+}
+
+// hitPaths lists the paths of hits in rank order
+func hitPaths(hits []Hit) []string {
+	var paths []string
+	for _, h := range hits {
+		paths = append(paths, h.Path)
+	}
+	return paths
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Query
+	}{
+		{`invoice "net 30" vat`, Query{Terms: []string{"invoice", "vat"}, Phrases: [][]string{{"net", "30"}}}},
+		{`"Payment"`, Query{Terms: []string{"payment"}}},
+		{`  `, Query{}},
+		{`"unclosed phrase`, Query{Phrases: [][]string{{"unclosed", "phrase"}}}},
+		{`Café-Bar`, Query{Terms: []string{"café", "bar"}}},
+	}
+	for _, tt := range tests {
+		if got := ParseQuery(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQuery(%q) = %#v, want %#v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSearchBM25Ranking(t *testing.T) {
+	idx := Build(sampleDocs())
+	if idx.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", idx.Len())
+	}
+
+	// invoice.pdf mentions "invoice" twice in a short text
+	hits := idx.Search(ParseQuery("invoice"), Filter{}, 0)
+	if got, want := hitPaths(hits), []string{"invoice.pdf", "contract.docx"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("hits = %v, want %v", got, want)
+	}
+	if hits[0].Score <= hits[1].Score {
+		t.Errorf("scores not descending: %v, %v", hits[0].Score, hits[1].Score)
+	}
+
+	// A term found in one document weighs more than one found in two
+	rare := idx.Search(ParseQuery("roadmap"), Filter{}, 0)
+	if len(rare) != 1 || rare[0].Path != "notes.md" {
+		t.Fatalf("roadmap hits = %v, want [notes.md]", hitPaths(rare))
+	}
+	if rare[0].Score <= hits[1].Score {
+		t.Errorf("rare term score %v should beat common term score %v", rare[0].Score, hits[1].Score)
+	}
+}
+
+func TestSearchAllTermsRequired(t *testing.T) {
+	idx := Build(sampleDocs())
+	if hits := idx.Search(ParseQuery("invoice roadmap"), Filter{}, 0); len(hits) != 0 {
+		t.Errorf("hits = %v, want none", hitPaths(hits))
+	}
+	if hits := idx.Search(ParseQuery("missing"), Filter{}, 0); len(hits) != 0 {
+		t.Errorf("hits = %v, want none", hitPaths(hits))
+	}
+	if hits := idx.Search(ParseQuery(""), Filter{}, 0); hits != nil {
+		t.Errorf("empty query hits = %v, want nil", hitPaths(hits))
+	}
+}
+
+func TestSearchPhrase(t *testing.T) {
+	idx := Build(sampleDocs())
+
+	// Both documents contain "net" and "30", only one has them in sequence
+	hits := idx.Search(ParseQuery(`"net 30"`), Filter{}, 0)
+	if got, want := hitPaths(hits), []string{"invoice.pdf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`"net 30" hits = %v, want %v`, got, want)
+	}
+
+	hits = idx.Search(ParseQuery(`"30 days net"`), Filter{}, 0)
+	if got, want := hitPaths(hits), []string{"contract.docx"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`"30 days net" hits = %v, want %v`, got, want)
+	}
+
+	// Punctuation between words does not break a phrase
+	hits = idx.Search(ParseQuery(`"payment terms net"`), Filter{}, 0)
+	if got, want := hitPaths(hits), []string{"invoice.pdf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`"payment terms net" hits = %v, want %v`, got, want)
+	}
+
+	if hits := idx.Search(ParseQuery(`"30 net"`), Filter{}, 0); len(hits) != 0 {
+		t.Errorf(`"30 net" hits = %v, want none`, hitPaths(hits))
+	}
+}
+
+func TestSearchFilterAndLimit(t *testing.T) {
+	idx := Build(sampleDocs())
+	query := ParseQuery("payment")
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"category", Filter{Category: "WORD"}, []string{"contract.docx"}},
+		{"extension without dot", Filter{Extension: "pdf"}, []string{"invoice.pdf"}},
+		{"after", Filter{After: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, []string{"invoice.pdf"}},
+		{"before is exclusive", Filter{Before: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, []string{"contract.docx"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hitPaths(idx.Search(query, tt.filter, 0)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if hits := idx.Search(query, Filter{}, 1); len(hits) != 1 {
+		t.Errorf("limit 1 returned %d hits", len(hits))
+	}
+}
+
+func TestSnippetHighlights(t *testing.T) {
+	idx := Build(sampleDocs())
+	hits := idx.Search(ParseQuery("vat"), Filter{}, 0)
+	if len(hits) != 1 {
+		t.Fatalf("hits = %v, want 1", hitPaths(hits))
+	}
+	for _, h := range hits[0].Highlights {
+		if got := hits[0].Snippet[h[0]:h[1]]; got != "VAT" {
+			t.Errorf("highlight %v = %q, want %q", h, got, "VAT")
+		}
+	}
+	if len(hits[0].Highlights) != 1 {
+		t.Errorf("highlights = %v, want one", hits[0].Highlights)
+	}
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// snippetRadius is how many bytes of context are shown on each side of a match
+const snippetRadius = 80
+
+// Query is a parsed search query
+type Query struct {
+	Terms   []string   // Individual words
+	Phrases [][]string // Quoted phrases, as word sequences
+}
+
+// token is a normalized word and its byte range in the original text
+type token struct {
+	term       string
+	start, end int
+}
+
+// ParseQuery splits raw input into terms and "quoted phrases".
+//
+// Example: `invoice "net 30" vat` → Terms [invoice vat], Phrases [[net 30]]
+func ParseQuery(raw string) Query {
+	var q Query
+
+	parts := strings.Split(raw, `"`)
+	for i, part := range parts {
+		words := terms(part)
+		if len(words) == 0 {
+			continue
+		}
+		// Odd-numbered parts sit between quotes
+		if i%2 == 1 && len(words) > 1 {
+			q.Phrases = append(q.Phrases, words)
+			continue
+		}
+		q.Terms = append(q.Terms, words...)
+	}
+
+	return q
+}
+
+// Empty reports whether the query has nothing to search for
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// allTerms returns every distinct word of the query, including phrase words
+func (q Query) allTerms() []string {
+	seen := make(map[string]bool)
+	var all []string
+	add := func(words []string) {
+		for _, w := range words {
+			if !seen[w] {
+				seen[w] = true
+				all = append(all, w)
+			}
+		}
+	}
+	add(q.Terms)
+	for _, phrase := range q.Phrases {
+		add(phrase)
+	}
+	return all
+}
+
+// terms normalizes text into lowercase words
+func terms(text string) []string {
+	var words []string
+	for _, tok := range tokenize(text) {
+		words = append(words, tok.term)
+	}
+	return words
+}
+
+// tokenize splits text into lowercase letter/digit runs with byte offsets
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, token{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// makeSnippet cuts a window of text around the first matching term and
+// returns the byte ranges of all matching terms inside it
+func makeSnippet(text string, queryTerms []string) (string, [][2]int) {
+	wanted := make(map[string]bool, len(queryTerms))
+	for _, t := range queryTerms {
+		wanted[t] = true
+	}
+
+	tokens := tokenize(text)
+	first := -1
+	for _, tok := range tokens {
+		if wanted[tok.term] {
+			first = tok.start
+			break
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	start := max(first-snippetRadius, 0)
+	end := min(first+snippetRadius, len(text))
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	var highlights [][2]int
+	for _, tok := range tokens {
+		if tok.start >= start && tok.end <= end && wanted[tok.term] {
+			highlights = append(highlights, [2]int{tok.start - start, tok.end - start})
+		}
+	}
+
+	// Flatten whitespace without changing byte offsets
+	snippet := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, text[start:end])
+
+	return snippet, highlights
+}
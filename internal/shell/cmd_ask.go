@@ -0,0 +1,125 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DeleMike/scout/internal/scout"
+	"github.com/DeleMike/scout/internal/summarize"
+)
+
+// askContextFiles is how many retrieved files are given to the model per question
+const askContextFiles = 6
+
+// HandleAsk encapsulates the logic for the "ask" command
+//
+// Usage:
+//   - ask [--dir <path>] <question>: Answer a question about a directory
+//   - ask --reset: Forget the conversation so far
+//
+// The directory is scanned once per session and rescanned when --dir
+// points somewhere else, which also starts a new conversation.
+func HandleAsk(session *Session, args []string, defaultWriter io.Writer) error {
+	var writer io.Writer = defaultWriter
+
+	cleanArgs, fileWriter, err := setupRedirection(args)
+	if err != nil {
+		return err
+	}
+	if fileWriter != nil {
+		writer = fileWriter
+		defer fileWriter.Close()
+	}
+
+	cleanArgs, reset := extractFlag(cleanArgs, "--reset")
+	cleanArgs, rawPath := extractOption(cleanArgs, "--dir")
+
+	if reset {
+		session.History = nil
+		fmt.Fprintln(writer, "🧹 Conversation cleared.")
+		if len(cleanArgs) < 2 {
+			return nil
+		}
+	}
+
+	if len(cleanArgs) < 2 {
+		return fmt.Errorf("usage: ask [--dir <path>] <question>")
+	}
+	question := strings.Trim(strings.Join(cleanArgs[1:], " "), "\"'")
+
+	if err := session.load(rawPath); err != nil {
+		return err
+	}
+	if session.index == nil {
+		session.buildIndex()
+	}
+
+	// Carry the previous question into retrieval so follow-ups like
+	// "which of those are from 2023?" still find the same files
+	query := question
+	if n := len(session.History); n > 0 {
+		query = session.History[n-1].Question + " " + question
+	}
+	files := session.retrieve(query)
+
+	fmt.Println("🤖 Thinking...")
+	prompt := scout.GenerateAskPrompt(question, files, session.Insight, session.History)
+	answer, err := summarize.Generate(prompt)
+	if errors.Is(err, summarize.ErrModelUnavailable) {
+		fmt.Fprintf(writer, "⚠️  %v; showing the most relevant files instead\n", err)
+		writeSources(writer, files)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("summarizer error: %v", err)
+	}
+
+	session.History = append(session.History, scout.Turn{Question: question, Answer: answer})
+
+	fmt.Fprintf(writer, "\n💬 %s\n", answer)
+	writeSources(writer, files)
+
+	return nil
+}
+
+// retrieve picks the files to give the model: semantic matches first (when
+// an index is available), topped up with keyword matches
+func (session *Session) retrieve(query string) []scout.FileSummary {
+	var paths []string
+
+	if session.index != nil {
+		// Ask for extra chunks since several may come from the same file
+		results, err := session.index.Search(session.embedder, query, askContextFiles*3)
+		if err != nil {
+			fmt.Printf("⚠️  Semantic search failed: %v\n", err)
+		}
+		for _, r := range results {
+			paths = append(paths, r.Path)
+		}
+	}
+
+	for _, f := range scout.RetrieveFiles(session.Summary, query, askContextFiles) {
+		paths = append(paths, f.Path)
+	}
+
+	files := scout.FilesByPath(session.Summary, paths)
+	if len(files) > askContextFiles {
+		files = files[:askContextFiles]
+	}
+	return files
+}
+
+// writeSources lists the files that were given to the model as context
+func writeSources(writer io.Writer, files []scout.FileSummary) {
+	if len(files) == 0 {
+		fmt.Fprintln(writer, "\n📚 Sources: no matching files found")
+		return
+	}
+
+	fmt.Fprintln(writer, "\n📚 Sources:")
+	for _, f := range files {
+		fmt.Fprintf(writer, "  - %s\n", f.Path)
+	}
+}
@@ -0,0 +1,161 @@
+package shell
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DeleMike/scout/internal/cache"
+	"github.com/DeleMike/scout/internal/scout"
+	"github.com/DeleMike/scout/internal/summarize"
+)
+
+// HandleExplain encapsulates the logic for the "explain" command
+//
+// Usage:
+//   - explain <file>: Summarize a single file with the local model
+//   - explain --refresh <file>: Ignore any cached explanation
+//   - explain --no-ai <file>: Describe the file from extracted metadata only
+//
+// Explanations are cached per file (keyed by path, size and modification
+// time) in the cache directory of the scanned root: the session's directory,
+// or the working directory before anything was scanned. Files outside that
+// root use their own folder's cache directory.
+func HandleExplain(session *Session, args []string, defaultWriter io.Writer) error {
+	var writer io.Writer = defaultWriter
+
+	cleanArgs, fileWriter, err := setupRedirection(args)
+	if err != nil {
+		return err
+	}
+	if fileWriter != nil {
+		writer = fileWriter
+		defer fileWriter.Close()
+	}
+	useColor := (fileWriter == nil)
+
+	cleanArgs, refresh := extractFlag(cleanArgs, "--refresh")
+	cleanArgs, noAI := extractFlag(cleanArgs, "--no-ai")
+
+	if len(cleanArgs) < 2 {
+		return fmt.Errorf("usage: explain [--refresh] [--no-ai] <file>")
+	}
+
+	targetFile, err := filepath.Abs(strings.Trim(strings.Join(cleanArgs[1:], " "), "\"'"))
+	if err != nil {
+		return fmt.Errorf("error resolving path: %v", err)
+	}
+	if _, err := os.Stat(targetFile); os.IsNotExist(err) {
+		return fmt.Errorf("file '%s' does not exist", targetFile)
+	}
+
+	fmt.Printf("🔎 Reading: %s\n", targetFile)
+	file, err := scout.ExtractFile(targetFile)
+	if err != nil {
+		return err
+	}
+	related := scout.RelatedFiles(file, siblingNames(targetFile))
+
+	cachePath := explainCachePath(explainRoot(session, targetFile), file)
+
+	var explanation string
+	if cached, err := os.ReadFile(cachePath); err == nil && !refresh && !noAI {
+		fmt.Println("⚡ Using cached explanation (pass --refresh to regenerate)")
+		explanation = string(cached)
+	} else {
+		if !noAI {
+			if err := summarize.Available(); err != nil {
+				fmt.Printf("⚠️  %v; describing file from metadata instead\n", err)
+				noAI = true
+			}
+		}
+
+		if noAI {
+			explanation = scout.DescribeFile(file, related)
+		} else {
+			fmt.Println("🤖 Generating explanation...")
+			explanation, err = summarize.Generate(scout.GenerateExplainPrompt(file, related))
+			if errors.Is(err, summarize.ErrModelUnavailable) {
+				fmt.Printf("⚠️  %v; describing file from metadata instead\n", err)
+				explanation = scout.DescribeFile(file, related)
+			} else if err != nil {
+				return fmt.Errorf("summarizer error: %v", err)
+			} else if cachePath != "" {
+				if err := os.WriteFile(cachePath, []byte(explanation), 0644); err != nil {
+					fmt.Printf("⚠️  Could not cache explanation: %v\n", err)
+				}
+			}
+		}
+	}
+
+	if useColor {
+		explanation = summarize.FormatForTerminal(explanation)
+	}
+
+	fmt.Fprintf(writer, "\n%s\n", strings.Repeat("=", 80))
+	fmt.Fprintln(writer, explanation)
+	fmt.Fprintf(writer, "%s\n", strings.Repeat("=", 80))
+
+	return nil
+}
+
+// siblingNames lists the other entries in a file's directory
+func siblingNames(path string) []string {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, ent := range entries {
+		if !ent.IsDir() {
+			names = append(names, ent.Name())
+		}
+	}
+	return names
+}
+
+// explainRoot returns the directory whose cache holds the explanation of
+// path: the session's scanned directory (or the working directory, which a
+// first scan defaults to) when it contains path, otherwise path's folder
+func explainRoot(session *Session, path string) string {
+	root := session.Root
+	if root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return filepath.Dir(path)
+		}
+		root = wd
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Dir(path)
+	}
+	return root
+}
+
+// explainCachePath returns where the explanation of file is cached within
+// the cache directory of root, or "" when no cache directory is available.
+// The key changes whenever the file is modified, so stale explanations are
+// never reused.
+func explainCachePath(root string, file *scout.FileSummary) string {
+	dir, err := cache.Dir(root)
+	if err != nil {
+		return ""
+	}
+
+	dir = filepath.Join(dir, "explain")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ""
+	}
+
+	key := fmt.Sprintf("%s|%d|%d", file.Path, file.Size, file.ModTime.UnixNano())
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".txt")
+}
@@ -1,6 +1,7 @@
 package shell
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +13,12 @@ import (
 )
 
 // HandleScout encapsulates the logic for the "sc" command
+//
+// Flags:
+//   - --no-ai: Skip the local model and render the heuristic report
+//
+// When the model or llama runtime is missing, the heuristic report is
+// used automatically instead of failing.
 func HandleScout(args []string, defaultWriter io.Writer) error {
 	var writer io.Writer = defaultWriter
 	var targetFile *os.File
@@ -28,6 +35,8 @@ func HandleScout(args []string, defaultWriter io.Writer) error {
 		defer targetFile.Close()
 	}
 
+	cleanArgs, noAI := extractFlag(cleanArgs, "--no-ai")
+
 	rawPath := "."
 	if len(cleanArgs) > 1 {
 		rawPath = strings.Trim(cleanArgs[1], "\"'")
@@ -59,21 +68,33 @@ func HandleScout(args []string, defaultWriter io.Writer) error {
 		insight.Confidence*100,
 		insight.Domain)
 
-	// Run AI Summarization
-	if targetFile != nil {
-		fmt.Println("🤖 Generating AI insights...")
+	useColor := (targetFile == nil)
+
+	if !noAI {
+		if err := summarize.Available(); err != nil {
+			fmt.Printf("⚠️  %v; falling back to heuristic report\n", err)
+			noAI = true
+		}
+	}
+
+	var report string
+	if noAI {
+		report = heuristicReport(insight, summary, useColor)
 	} else {
+		// Run AI Summarization
 		fmt.Println("🤖 Generating AI insights...")
-	}
-	useColor := (targetFile == nil)
-	fullPrompt := scout.GeneratePrompt(insight, summary)
-	aiResponse, err := summarize.Summarize(fullPrompt, useColor)
-	if err != nil {
-		return fmt.Errorf("summarizer error: %v", err)
+		fullPrompt := scout.GeneratePrompt(insight, summary)
+		report, err = summarize.Summarize(fullPrompt, useColor)
+		if errors.Is(err, summarize.ErrModelUnavailable) {
+			fmt.Printf("⚠️  %v; falling back to heuristic report\n", err)
+			report = heuristicReport(insight, summary, useColor)
+		} else if err != nil {
+			return fmt.Errorf("summarizer error: %v", err)
+		}
 	}
 
 	fmt.Fprintf(writer, "\n%s\n", strings.Repeat("=", 80))
-	fmt.Fprintln(writer, aiResponse)
+	fmt.Fprintln(writer, report)
 	fmt.Fprintf(writer, "%s\n", strings.Repeat("=", 80))
 
 	if targetFile != nil {
@@ -83,6 +104,45 @@ func HandleScout(args []string, defaultWriter io.Writer) error {
 	return nil
 }
 
+// heuristicReport renders the model-free report, colorized for terminals
+func heuristicReport(insight *scout.ContentInsight, summary *scout.DirectorySummary, useColor bool) string {
+	fmt.Println("📋 Generating heuristic report (no AI)...")
+	report := scout.GenerateReport(insight, summary)
+	if useColor {
+		return summarize.FormatForTerminal(report)
+	}
+	return report
+}
+
+// extractFlag removes a boolean flag from args and reports whether it was present
+func extractFlag(args []string, flag string) ([]string, bool) {
+	found := false
+	cleanArgs := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		cleanArgs = append(cleanArgs, arg)
+	}
+	return cleanArgs, found
+}
+
+// extractOption removes a "--name value" pair from args and returns the value
+func extractOption(args []string, option string) ([]string, string) {
+	value := ""
+	cleanArgs := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] == option && i+1 < len(args) {
+			value = strings.Trim(args[i+1], "\"'")
+			i++
+			continue
+		}
+		cleanArgs = append(cleanArgs, args[i])
+	}
+	return cleanArgs, value
+}
+
 // Helper function to extract ">> filename" from args
 func setupRedirection(args []string) (cleanArgs []string, file *os.File, err error) {
 	redirectIndex := -1
@@ -0,0 +1,111 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DeleMike/scout/internal/search"
+)
+
+// searchDateLayout is the accepted format for --after and --before
+const searchDateLayout = "2006-01-02"
+
+// HandleSearch encapsulates the logic for the "search" command
+//
+// Usage:
+//
+//	search [--dir <path>] [--category <name>] [--ext <.ext>]
+//	       [--after YYYY-MM-DD] [--before YYYY-MM-DD] [--limit N] <query>
+//
+// The query may contain "quoted phrases". Results are ranked with BM25 over
+// the text Scout extracted (including PDFs, DOCX and spreadsheets), and
+// the index is reused for the session until another directory is scanned.
+func HandleSearch(session *Session, args []string, defaultWriter io.Writer) error {
+	var writer io.Writer = defaultWriter
+
+	cleanArgs, fileWriter, err := setupRedirection(args)
+	if err != nil {
+		return err
+	}
+	if fileWriter != nil {
+		writer = fileWriter
+		defer fileWriter.Close()
+	}
+	useColor := (fileWriter == nil)
+
+	var filter search.Filter
+	var rawPath, after, before, rawLimit string
+	cleanArgs, rawPath = extractOption(cleanArgs, "--dir")
+	cleanArgs, filter.Category = extractOption(cleanArgs, "--category")
+	cleanArgs, filter.Extension = extractOption(cleanArgs, "--ext")
+	cleanArgs, after = extractOption(cleanArgs, "--after")
+	cleanArgs, before = extractOption(cleanArgs, "--before")
+	cleanArgs, rawLimit = extractOption(cleanArgs, "--limit")
+
+	if after != "" {
+		if filter.After, err = time.Parse(searchDateLayout, after); err != nil {
+			return fmt.Errorf("invalid --after date %q (want YYYY-MM-DD)", after)
+		}
+	}
+	if before != "" {
+		if filter.Before, err = time.Parse(searchDateLayout, before); err != nil {
+			return fmt.Errorf("invalid --before date %q (want YYYY-MM-DD)", before)
+		}
+	}
+
+	limit := 10
+	if rawLimit != "" {
+		if limit, err = strconv.Atoi(rawLimit); err != nil || limit <= 0 {
+			return fmt.Errorf("invalid --limit %q", rawLimit)
+		}
+	}
+
+	if len(cleanArgs) < 2 {
+		return fmt.Errorf("usage: search [--dir <path>] [--category <name>] [--ext <.ext>] [--after YYYY-MM-DD] [--before YYYY-MM-DD] <query>")
+	}
+	rawQuery := strings.Join(cleanArgs[1:], " ")
+	query := search.ParseQuery(rawQuery)
+	if query.Empty() {
+		return fmt.Errorf("nothing to search for in %q", rawQuery)
+	}
+
+	if err := session.load(rawPath); err != nil {
+		return err
+	}
+	index := session.searchIndex()
+
+	hits := index.Search(query, filter, limit)
+	fmt.Fprintf(writer, "🔍 %d matches for %s (%d files indexed)\n", len(hits), rawQuery, index.Len())
+
+	for i, hit := range hits {
+		fmt.Fprintf(writer, "\n%d. %s  (score %.2f)\n", i+1, hit.Path, hit.Score)
+		fmt.Fprintf(writer, "   ...%s...\n", highlight(hit.Snippet, hit.Highlights, useColor))
+	}
+
+	return nil
+}
+
+// highlight marks the matched byte ranges of a snippet, in bold yellow on
+// terminals and with **markers** when writing to a file
+func highlight(snippet string, ranges [][2]int, useColor bool) string {
+	open, closing := "**", "**"
+	if useColor {
+		open, closing = "\033[1m\033[33m", "\033[0m"
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		b.WriteString(snippet[last:r[0]])
+		b.WriteString(open)
+		b.WriteString(snippet[r[0]:r[1]])
+		b.WriteString(closing)
+		last = r[1]
+	}
+	b.WriteString(snippet[last:])
+
+	return b.String()
+}
@@ -14,21 +14,26 @@ import (
 //   - pwd: Print working directory
 //   - ls: List directory contents
 //   - sc: Run Scout directory analysis
+//   - ask: Answer questions about a scanned directory
+//   - search: Full-text search over extracted file contents
+//   - explain: Summarize a single file
 //
 // Parameters:
 //   - args: Command and its arguments (args[0] is the command name)
 //
 // Returns:
 //   - bool: true if command was recognized and handled, false otherwise.
-func (s *Shell) runBuiltin(args []string) bool {
+//   - error: The command's error, already printed to the user
+func (s *Shell) runBuiltin(args []string) (bool, error) {
 	switch args[0] {
 	case "exit":
 		fmt.Print("Bye, scout!")
+		s.session.Close()
 		os.Exit(0)
 	case "pwd":
 		wd, _ := os.Getwd()
 		fmt.Println(wd)
-		return true
+		return true, nil
 	case "ls":
 		entries, _ := os.ReadDir(".")
 		for _, ent := range entries {
@@ -37,13 +42,31 @@ func (s *Shell) runBuiltin(args []string) bool {
 			}
 			fmt.Println(ent.Name())
 		}
-		return true
+		return true, nil
 	case "scout", "sc":
 		err := HandleScout(args, os.Stdout)
 		if err != nil {
 			fmt.Printf("❌ %v\n", err)
 		}
-		return true
+		return true, err
+	case "ask":
+		err := HandleAsk(s.session, args, os.Stdout)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+		return true, err
+	case "search":
+		err := HandleSearch(s.session, args, os.Stdout)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+		return true, err
+	case "explain":
+		err := HandleExplain(s.session, args, os.Stdout)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+		return true, err
 	}
-	return false
+	return false, nil
 }
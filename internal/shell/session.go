@@ -0,0 +1,124 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DeleMike/scout/internal/embedding"
+	"github.com/DeleMike/scout/internal/scout"
+	"github.com/DeleMike/scout/internal/search"
+	"github.com/DeleMike/scout/internal/summarize"
+)
+
+// Session keeps the most recently scanned directory for the interactive
+// commands (ask, search, explain), along with state derived from it: the ask
+// conversation and the semantic and full-text indexes.
+type Session struct {
+	Root    string                  // Absolute path of the scanned directory
+	Summary *scout.DirectorySummary // Scan results used for retrieval
+	Insight *scout.ContentInsight   // Heuristic analysis of the directory
+	History []scout.Turn            // Previous questions and answers
+
+	embedder   *summarize.Embedder // Loaded embedding model, nil when unavailable
+	index      *embedding.Index    // Semantic index of Root, nil when unavailable
+	noEmbedder bool                // Set once loading the embedding model failed
+	textIndex  *search.Index       // Full-text index of Root, built on first search
+}
+
+// load scans rawPath unless it is already the session's directory.
+// An empty rawPath reuses the session's directory (or "." on first use).
+// Switching directories starts a fresh conversation and drops the indexes.
+func (session *Session) load(rawPath string) error {
+	if rawPath == "" {
+		rawPath = "."
+		if session.Root != "" {
+			rawPath = session.Root
+		}
+	}
+
+	targetDir, err := filepath.Abs(rawPath)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %v", err)
+	}
+
+	if session.Summary != nil && session.Root == targetDir {
+		return nil
+	}
+
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return fmt.Errorf("directory '%s' does not exist", targetDir)
+	}
+
+	fmt.Printf("🔎 Scouting: %s\n", targetDir)
+	summary, insight, err := scout.Run(targetDir)
+	if err != nil {
+		return err
+	}
+
+	session.Root = targetDir
+	session.Summary = summary
+	session.Insight = insight
+	session.History = nil
+	session.index = nil
+	session.textIndex = nil
+
+	return nil
+}
+
+// buildIndex loads the embedding model (once per session) and incrementally
+// updates the on-disk semantic index for the current directory.
+// Without an embedding model, retrieval falls back to keyword matching.
+func (session *Session) buildIndex() {
+	if session.embedder == nil && !session.noEmbedder {
+		embedder, err := summarize.NewEmbedder()
+		if err != nil {
+			session.noEmbedder = true
+			fmt.Printf("ℹ️  Semantic search disabled (%v); using keyword matching\n", err)
+			return
+		}
+		session.embedder = embedder
+	}
+	if session.embedder == nil {
+		return
+	}
+
+	index, err := embedding.Load(session.Root)
+	if err != nil {
+		fmt.Printf("⚠️  Could not load semantic index: %v\n", err)
+		return
+	}
+
+	fmt.Println("🧭 Updating semantic index...")
+	scout.LoadText(session.Summary)
+	stats, err := index.Update(scout.SemanticDocuments(session.Summary), session.embedder)
+	if err != nil {
+		fmt.Printf("⚠️  Semantic indexing failed: %v\n", err)
+		return
+	}
+	if err := index.Save(); err != nil {
+		fmt.Printf("⚠️  Could not save semantic index: %v\n", err)
+	}
+
+	fmt.Printf("🧭 Indexed: %d new, %d changed, %d removed, %d unchanged\n",
+		stats.Added, stats.Updated, stats.Removed, stats.Unchanged)
+	session.index = index
+}
+
+// searchIndex returns the full-text index of the session's directory,
+// building it from the scan results on first use
+func (session *Session) searchIndex() *search.Index {
+	if session.textIndex == nil {
+		scout.LoadText(session.Summary)
+		session.textIndex = search.Build(scout.SearchDocuments(session.Summary))
+	}
+	return session.textIndex
+}
+
+// Close frees the embedding model, if one was loaded
+func (session *Session) Close() {
+	if session.embedder != nil {
+		session.embedder.Close()
+		session.embedder = nil
+	}
+}
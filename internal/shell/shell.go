@@ -11,7 +11,8 @@ import (
 // Shell represents an interactive command-line interface
 // that processes user input in a REPL loop.
 type Shell struct {
-	prompt string // Command prompt displayed to user
+	prompt  string   // Command prompt displayed to user
+	session *Session // Scanned directory shared by ask, search and explain
 }
 
 // New creates and initializes a new Shell instance
@@ -21,16 +22,42 @@ type Shell struct {
 //   - *Shell: Configured shell ready to accept commands
 func New() *Shell {
 	return &Shell{
-		prompt: "scout> ",
+		prompt:  "scout> ",
+		session: &Session{},
 	}
 }
 
+// Run executes a single command without entering the REPL (headless mode).
+//
+// Built-in commands run as usual; anything else is treated as a path
+// to analyze, so "scout ." behaves like "sc .".
+//
+// Parameters:
+//   - args: Command-line arguments (without the program name)
+//
+// Returns:
+//   - error: The command's error, already printed, so the caller can exit
+//     with a failure status
+func (shell *Shell) Run(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	defer shell.session.Close()
+
+	if handled, err := shell.runBuiltin(args); handled {
+		return err
+	}
+
+	_, err := shell.runBuiltin(append([]string{"sc"}, args...))
+	return err
+}
+
 // Start begins the REPL (Read-Eval-Print Loop) for the shell.
 // It continuously reads user input, parses commands, and executes them
 // until the user exits.
 //
 // The shell supports:
-//   - Built-in commands (pwd, ls, sc, exit)
+//   - Built-in commands (pwd, ls, sc, ask, search, explain, exit)
 //   - External commands (git, curl, etc.)
 //
 // The loop continues indefinitely until explicitly terminated.
@@ -52,7 +79,7 @@ func (shell *Shell) Start() {
 		}
 
 		// check for builtin command
-		if handled := shell.runBuiltin(args); handled {
+		if handled, _ := shell.runBuiltin(args); handled {
 			continue
 		}
 
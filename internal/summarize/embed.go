@@ -0,0 +1,142 @@
+package summarize
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// defaultEmbedModelPath is where Scout looks for a GGUF embedding model
+const defaultEmbedModelPath = ".scout/model/nomic-embed-text-v1.5.Q4_K_M.gguf"
+
+// embedContextSize is the token window of the embedding context; longer
+// texts are truncated, so callers should chunk their input first
+const embedContextSize = 2048
+
+// Embedder turns text into normalized embedding vectors using a local GGUF
+// embedding model loaded through the same llama runtime as Summarize.
+//
+// An Embedder holds the model in memory; call Close when done.
+type Embedder struct {
+	model     llama.Model
+	lctx      llama.Context
+	vocab     llama.Vocab
+	dims      int32
+	modelPath string
+}
+
+// EmbedModelPath returns the embedding model location, honouring SCOUT_EMBED_MODEL
+func EmbedModelPath() string {
+	if path := os.Getenv("SCOUT_EMBED_MODEL"); path != "" {
+		return path
+	}
+	return defaultEmbedModelPath
+}
+
+// NewEmbedder loads the embedding model and prepares a context for it.
+//
+// Returns:
+//   - *Embedder: Ready-to-use embedder
+//   - error: Wraps ErrModelUnavailable when the runtime or model is missing
+func NewEmbedder() (*Embedder, error) {
+	libPath, _, err := runtimePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	modelPath := EmbedModelPath()
+	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: embedding model not found at %s", ErrModelUnavailable, modelPath)
+	}
+
+	if err := loadRuntime(libPath); err != nil {
+		return nil, err
+	}
+
+	model := llama.ModelLoadFromFile(modelPath, llama.ModelDefaultParams())
+	if model == 0 {
+		return nil, fmt.Errorf("%w: could not load embedding model %s", ErrModelUnavailable, modelPath)
+	}
+
+	ctxParams := llama.ContextDefaultParams()
+	ctxParams.NCtx = embedContextSize
+	ctxParams.NBatch = embedContextSize
+	ctxParams.NUbatch = embedContextSize
+	ctxParams.Embeddings = 1
+	ctxParams.PoolingType = llama.PoolingTypeMean
+
+	lctx := llama.InitFromModel(model, ctxParams)
+	if lctx == 0 {
+		llama.ModelFree(model)
+		return nil, fmt.Errorf("could not create an embedding context for %s", modelPath)
+	}
+
+	return &Embedder{
+		model:     model,
+		lctx:      lctx,
+		vocab:     llama.ModelGetVocab(model),
+		dims:      llama.ModelNEmbd(model),
+		modelPath: modelPath,
+	}, nil
+}
+
+// Model returns the path of the loaded embedding model, so indexes built
+// with a different model can be detected and rebuilt
+func (e *Embedder) Model() string {
+	return e.modelPath
+}
+
+// Embed computes the L2-normalized embedding of text.
+//
+// Parameters:
+//   - text: Input text (truncated to the context window)
+//
+// Returns:
+//   - []float32: Unit-length embedding vector
+//   - error: Any error during decoding
+func (e *Embedder) Embed(text string) ([]float32, error) {
+	tokens := llama.Tokenize(e.vocab, text, true, false)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("nothing to embed")
+	}
+	if len(tokens) > embedContextSize {
+		tokens = tokens[:embedContextSize]
+	}
+
+	// Each text is its own sequence; drop whatever the previous call left behind
+	llama.MemoryClear(llama.GetMemory(e.lctx), true)
+
+	batch := llama.BatchGetOne(tokens)
+	if llama.Decode(e.lctx, batch) != 0 {
+		return nil, fmt.Errorf("llama decode failed while embedding")
+	}
+
+	raw := llama.GetEmbeddingsSeq(e.lctx, 0, e.dims)
+	if raw == nil {
+		return nil, fmt.Errorf("model returned no embeddings (is it an embedding model?)")
+	}
+
+	// Copy out of llama-owned memory and normalize so cosine similarity is a dot product
+	vector := make([]float32, len(raw))
+	var norm float64
+	for i, v := range raw {
+		vector[i] = v
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+
+	return vector, nil
+}
+
+// Close frees the embedding context and model
+func (e *Embedder) Close() {
+	llama.Free(e.lctx)
+	llama.ModelFree(e.model)
+}
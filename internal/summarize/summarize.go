@@ -2,6 +2,7 @@
 package summarize
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
@@ -10,6 +11,41 @@ import (
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
+// defaultModelPath is where `make setup` expects the GGUF model to live
+const defaultModelPath = ".scout/model/llama-3.2-3b-instruct-q4_k_m.gguf"
+
+// ErrModelUnavailable is returned when the llama runtime library or the
+// GGUF model cannot be found, so callers can fall back to heuristics.
+var ErrModelUnavailable = errors.New("local model unavailable")
+
+// Available reports whether the llama runtime and model are present.
+//
+// Returns:
+//   - error: nil when inference can run, otherwise wraps ErrModelUnavailable
+func Available() error {
+	_, _, err := runtimePaths()
+	return err
+}
+
+// runtimePaths resolves the llama library directory and the model file.
+// SCOUT_MODEL (set by the scout.sh wrapper) overrides the default model path.
+func runtimePaths() (libPath, modelPath string, err error) {
+	libPath = os.Getenv("YZMA_LIB")
+	if libPath == "" {
+		return "", "", fmt.Errorf("%w: YZMA_LIB environment variable not set", ErrModelUnavailable)
+	}
+
+	modelPath = os.Getenv("SCOUT_MODEL")
+	if modelPath == "" {
+		modelPath = defaultModelPath
+	}
+	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		return "", "", fmt.Errorf("%w: model file not found at %s", ErrModelUnavailable, modelPath)
+	}
+
+	return libPath, modelPath, nil
+}
+
 // Summarize runs local Llama inference to generate natural language
 // insights from the structured prompt.
 //
@@ -26,21 +62,59 @@ import (
 //   - string: Formatted AI response
 //   - error: Any error during model loading or inference
 func Summarize(prompt string, enableColor bool) (string, error) {
-	libPath := os.Getenv("YZMA_LIB")
-	if libPath == "" {
-		return "", fmt.Errorf("YZMA_LIB environment variable not set")
+	output, err := Generate(prompt)
+	if err != nil {
+		return "", err
 	}
 
-	modelPath := ".scout/model/llama-3.2-3b-instruct-q4_k_m.gguf"
-	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("model file not found at %s", modelPath)
+	if enableColor {
+		return FormatForTerminal(output), nil
 	}
 
-	llama.Load(libPath)
+	return output, nil
+}
+
+// runtimeLoaded records whether the llama shared library is already loaded,
+// so interactive commands that call the model repeatedly only load it once
+var runtimeLoaded bool
+
+// loadRuntime loads and initializes the llama shared library once per process
+func loadRuntime(libPath string) error {
+	if runtimeLoaded {
+		return nil
+	}
+	if err := llama.Load(libPath); err != nil {
+		return fmt.Errorf("%w: %v", ErrModelUnavailable, err)
+	}
 	llama.Init()
 	llama.LogSet(llama.LogSilent())
+	runtimeLoaded = true
+	return nil
+}
+
+// Generate runs local Llama inference on an already formatted prompt and
+// returns the raw completion text, without terminal formatting.
+//
+// Parameters:
+//   - prompt: Complete Llama-3 formatted prompt
+//
+// Returns:
+//   - string: Trimmed model response
+//   - error: Any error during model loading or inference
+func Generate(prompt string) (string, error) {
+	libPath, modelPath, err := runtimePaths()
+	if err != nil {
+		return "", err
+	}
+
+	if err := loadRuntime(libPath); err != nil {
+		return "", err
+	}
 
 	model := llama.ModelLoadFromFile(modelPath, llama.ModelDefaultParams())
+	if model == 0 {
+		return "", fmt.Errorf("%w: could not load model %s", ErrModelUnavailable, modelPath)
+	}
 	defer llama.ModelFree(model)
 
 	ctxParams := llama.ContextDefaultParams()
@@ -122,21 +196,16 @@ func Summarize(prompt string, enableColor bool) (string, error) {
 		batch = llama.BatchGetOne([]llama.Token{token})
 	}
 
-	output := strings.TrimSpace(response.String())
-	if enableColor {
-		return FormatForTerminal(output), nil
-	}
-
-	return output, nil
+	return strings.TrimSpace(response.String()), nil
 }
 
 // FormatForTerminal adds ANSI color codes based on emoji headers
 // to make the output more visually appealing in terminals.
 //
 // Color scheme:
-//   - 📂 (folder info): Cyan + Bold
-//   - 🎯 (purpose): Green + Bold
-//   - 🔍 (highlights): Yellow + Bold
+//   - 📂/📄 (folder or file info): Cyan + Bold
+//   - 🎯/🔗 (purpose, related files): Green + Bold
+//   - 🔍/🧩 (highlights, key sections): Yellow + Bold
 //   - ⚠️/👀 (warnings/suggestions): Red + Bold
 //   - **bold text**: Bold formatting
 func FormatForTerminal(text string) string {
@@ -154,11 +223,11 @@ func FormatForTerminal(text string) string {
 
 	for _, line := range lines {
 		// Colorize based on Emojis
-		if strings.Contains(line, "📁") {
+		if strings.Contains(line, "📁") || strings.Contains(line, "📄") {
 			line = Cyan + Bold + line + Reset
-		} else if strings.Contains(line, "🎯") {
+		} else if strings.Contains(line, "🎯") || strings.Contains(line, "🔗") {
 			line = Green + Bold + line + Reset
-		} else if strings.Contains(line, "🔍") {
+		} else if strings.Contains(line, "🔍") || strings.Contains(line, "🧩") {
 			line = Yellow + Bold + line + Reset
 		} else if strings.Contains(line, "⚠️") || strings.Contains(line, "👀") {
 			line = Red + Bold + line + Reset